@@ -4,19 +4,34 @@
 package APIClient
 
 import (
+	"bufio"
 	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
 	"net/http"
 	"net/url"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/publitsweden/APIUtilityGoSDK/client"
 	"github.com/publitsweden/APIUtilityGoSDK/common"
 )
 
 // General API constants
 const (
-	// Supported version of the Publit APIs
+	// Default version of the Publit APIs, used when APIClient.Version is not set
 	API_VERSION = "v2.0"
 
 	// Status check resource
@@ -24,6 +39,21 @@ const (
 
 	// Token resource
 	RESOURCE_TOKEN = "token"
+
+	// Default page size used by GetAll when APIClient.PageSize is not set
+	DEFAULT_PAGE_SIZE = 100
+
+	// Content-Type of io.Reader payloads unless set with a header
+	DEFAULT_READER_CONTENT_TYPE = "application/octet-stream"
+
+	// Header used by the Publit APIs for identifying requests
+	HEADER_REQUEST_ID = "X-Request-Id"
+
+	// Default amount of concurrent requests made by GetConcurrently
+	DEFAULT_CONCURRENCY = 4
+
+	// Default amount of response codes kept by the APIClient when APIClient.ResponseHistorySize is not set
+	DEFAULT_RESPONSE_HISTORY_SIZE = 100
 )
 
 // Endpointer interface declares how an endpoint should be defined
@@ -40,59 +70,565 @@ type APICaller interface {
 	UnsetAuthToken()
 }
 
+// CallFunc performs a request against the Publit APIs.
+type CallFunc func(r *http.Request) (*http.Response, error)
+
+// Middleware wraps a CallFunc, making it possible to act on every request and response passing through the APIClient.
+type Middleware func(next CallFunc) CallFunc
+
 // APIClient hold Client information for connecting to the Publit APIs and base URLs.
+// An APIClient is safe for concurrent use by multiple goroutines once configured, and must not be copied after first use.
 type APIClient struct {
-	Client    APICaller
-	BaseURL   string
-	API       string
-	respCodes []int
+	Client  APICaller
+	BaseURL string
+	API     string
+	// AcceptedStatuses holds the response status codes that Get, Post, Put and Delete treat as successful.
+	// Defaults to http.StatusOK only if left empty.
+	AcceptedStatuses []int
+	// Codec encodes payloads and decodes responses of Do, Get, Post, Put and Delete. Defaults to JSONCodec.
+	Codec Codec
+	// PageSize is the amount of records requested per page by GetAll. Defaults to DEFAULT_PAGE_SIZE.
+	PageSize int
+	// ValidateResponse is an optional hook run on every accepted response before it is decoded, e.g. for verifying a signature header.
+	// An error returned from the hook fails the call with that error.
+	ValidateResponse func(resp *http.Response) error
+	// Observer is notified after every call, see Observer.
+	Observer Observer
+	// ResponseHistorySize is the max amount of response codes and request ids kept by the APIClient, the oldest codes are dropped first.
+	// Defaults to DEFAULT_RESPONSE_HISTORY_SIZE.
+	ResponseHistorySize int
+	// Version is the version of the Publit APIs used in request URLs, e.g. "v3.0". Defaults to API_VERSION.
+	// Can be overridden per request with WithRequestAPIVersion.
+	Version string
+	// PayloadValidators are run on every payload before it is encoded and sent, after the Validate method of payloads
+	// implementing Validator. A failing validation fails the call with a *PayloadError without sending the request.
+	PayloadValidators []PayloadValidator
+	// URLBuilder composes the URLs of requests against endpoints. Defaults to DefaultURLBuilder.
+	// Override it for deployments with another URL layout, e.g. without the API segment.
+	URLBuilder URLBuilder
+	// RateLimitRetries is the max amount of times a request is retried after a 429 Too Many Requests response.
+	// The APIClient waits the time given by the Retry-After header (or DEFAULT_RETRY_AFTER) before retrying.
+	// Defaults to 0, in which case the 429 response is returned as a ResponseError with RetryAfter set.
+	RateLimitRetries int
+	// MaxRetryAfter is the max time waited before retrying a rate limited request. If the Retry-After header asks for a
+	// longer wait, the 429 response is returned as a ResponseError instead. Defaults to DEFAULT_MAX_RETRY_AFTER.
+	MaxRetryAfter time.Duration
+	// StatsMaxEndpoints is the max amount of endpoints with call statistics of their own, see GetCallStats.
+	// Defaults to DEFAULT_STATS_MAX_ENDPOINTS.
+	StatsMaxEndpoints int
+	// StatsSampleSize is the max amount of latencies kept per endpoint for the call statistics.
+	// Defaults to DEFAULT_STATS_SAMPLE_SIZE.
+	StatsSampleSize int
+
+	// mu guards history, lastResponse, middlewares, stats and closed.
+	mu           sync.Mutex
+	history      []ResponseRecord
+	lastResponse ResponseInfo
+	middlewares  []Middleware
+	stats        map[string]*endpointStats
+	closed       bool
+
+	// optionErr is the error of an Option given to NewAPIClient, such as WithEnvironment with an unknown environment.
+	optionErr error
+}
+
+// ResponseInfo holds metadata about a response received by the APIClient.
+type ResponseInfo struct {
+	// StatusCode is the status code of the response.
+	StatusCode int
+	// Header holds the headers of the response.
+	Header http.Header
+	// RequestURL is the URL of the request.
+	RequestURL string
+	// RequestID is the request id of the response, or of the request if the response has none.
+	RequestID string
+	// Latency is the round-trip time of the request.
+	Latency time.Duration
+}
+
+// Option configures an APIClient created with NewAPIClient.
+type Option func(c *APIClient)
+
+// NewAPIClient creates a new APIClient against the given base URL and API.
+// Options are applied in order after BaseURL and API are set.
+// Client defaults to a client.Client created with client.New (which also sets up its logger) if not set by an option.
+// An empty baseURL falls back to the BaseURL of the client.Client, eg. as set by client.NewFromEnv.
+// Returns an error if BaseURL is not an absolute URL or API is missing.
+func NewAPIClient(baseURL, api string, opts ...Option) (*APIClient, error) {
+	c := &APIClient{BaseURL: baseURL, API: api}
+
+	for _, v := range opts {
+		v(c)
+	}
+
+	if c.Client == nil {
+		c.Client = client.New()
+	}
+
+	if cl, ok := c.Client.(*client.Client); ok && c.BaseURL == "" {
+		c.BaseURL = cl.BaseURL
+	}
+
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// WithCaller sets the APICaller used by the APIClient.
+func WithCaller(caller APICaller) Option {
+	return func(c *APIClient) {
+		c.Client = caller
+	}
+}
+
+// WithEnvironment sets the BaseURL of the APIClient to the base URL of a Publit environment, such as
+// client.ENVIRONMENT_PRODUCTION or one registered with client.RegisterEnvironment. NewAPIClient fails for an unknown
+// environment.
+func WithEnvironment(name client.Environment) Option {
+	return func(c *APIClient) {
+		baseURL, err := client.LookupEnvironment(name)
+		if err != nil {
+			c.optionErr = err
+			return
+		}
+		c.BaseURL = baseURL
+	}
+}
+
+// WithAPIVersion sets the version of the Publit APIs used by the APIClient, see APIClient.Version.
+func WithAPIVersion(version string) Option {
+	return func(c *APIClient) {
+		c.Version = version
+	}
+}
+
+// WithMiddlewares adds middlewares to the APIClient, see APIClient.Use.
+func WithMiddlewares(middlewares ...Middleware) Option {
+	return func(c *APIClient) {
+		c.Use(middlewares...)
+	}
+}
+
+// validate checks that the APIClient is configured with the fields needed to call the Publit APIs.
+func (c *APIClient) validate() error {
+	if c.optionErr != nil {
+		return fmt.Errorf("Could not create APIClient. %v", c.optionErr)
+	}
+
+	if cl, ok := c.Client.(*client.Client); ok && cl.ConfigError() != nil {
+		return fmt.Errorf("Could not create APIClient. %v", cl.ConfigError())
+	}
+
+	if c.BaseURL == "" {
+		return errors.New("Could not create APIClient. Missing BaseURL")
+	}
+
+	u, err := url.Parse(c.BaseURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf(`Could not create APIClient. BaseURL "%v" is not an absolute URL`, c.BaseURL)
+	}
+
+	if c.API == "" {
+		return errors.New("Could not create APIClient. Missing API")
+	}
+
+	return nil
+}
+
+// Use adds middlewares invoked around every call made by the APIClient.
+// Middlewares are invoked in the order they are added, meaning the first added middleware is the outermost one.
+func (c *APIClient) Use(middlewares ...Middleware) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.middlewares = append(c.middlewares, middlewares...)
+}
+
+// call performs an authenticated request through the middleware chain.
+func (c *APIClient) call(r *http.Request) (*http.Response, error) {
+	return c.record(r, c.chain(c.Client.Call))
+}
+
+// callRaw performs an unauthenticated request through the middleware chain.
+func (c *APIClient) callRaw(r *http.Request) (*http.Response, error) {
+	return c.record(r, c.chain(c.Client.CallRaw))
+}
+
+// record performs the request with f, records the response code and response info and notifies the Observer.
+// A request id is generated for the request unless it already has one.
+func (c *APIClient) record(r *http.Request, f CallFunc) (*http.Response, error) {
+	if c.isClosed() {
+		return nil, ErrClientClosed
+	}
+
+	if r.Header.Get(HEADER_REQUEST_ID) == "" {
+		r.Header.Set(HEADER_REQUEST_ID, newRequestID())
+	}
+
+	start := time.Now()
+	resp, err := f(r)
+
+	retries := 0
+	for ; retries < c.RateLimitRetries && err == nil && resp != nil && resp.StatusCode == http.StatusTooManyRequests; retries++ {
+		if !c.waitForRetry(r, resp) {
+			break
+		}
+		resp, err = f(r)
+	}
+
+	failed := err != nil || resp == nil || resp.StatusCode >= http.StatusBadRequest
+	c.addCallStats(requestEndpoint(r), time.Since(start), failed)
+
+	if c.Observer != nil {
+		m := CallMetrics{
+			Method:   r.Method,
+			Endpoint: requestEndpoint(r),
+			Retries:  retries,
+			Duration: time.Since(start),
+			Err:      err,
+		}
+		if resp != nil {
+			m.StatusCode = resp.StatusCode
+		}
+		c.Observer.Observe(m)
+	}
+
+	if resp == nil {
+		if err == nil {
+			err = errors.New("No response received")
+		}
+		return nil, client.NewTransportError(r, err)
+	}
+
+	if resp.Request == nil {
+		resp.Request = r
+	}
+
+	info := ResponseInfo{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header.Clone(),
+		RequestURL: r.URL.String(),
+		RequestID:  resp.Header.Get(HEADER_REQUEST_ID),
+		Latency:    time.Since(start),
+	}
+	if info.RequestID == "" {
+		info.RequestID = r.Header.Get(HEADER_REQUEST_ID)
+	}
+
+	c.addResponse(resp.StatusCode, info.RequestID)
+
+	c.mu.Lock()
+	c.lastResponse = info
+	c.mu.Unlock()
+
+	if err != nil {
+		var decodeErr *DecodeError
+		if errors.As(err, &decodeErr) {
+			return resp, err
+		}
+		return resp, client.NewTransportError(r, err)
+	}
+
+	return resp, nil
+}
+
+// waitForRetry waits the time given by the Retry-After header of the rate limited response and rewinds the request body.
+// Reports false, leaving the response untouched, if the request can not be retried, or the wait is longer than
+// MaxRetryAfter or the deadline of the request.
+func (c *APIClient) waitForRetry(r *http.Request, resp *http.Response) bool {
+	if r.Body != nil && r.Body != http.NoBody && r.GetBody == nil {
+		return false
+	}
+
+	wait, ok := parseRetryAfter(resp.Header, time.Now())
+	if !ok {
+		wait = DEFAULT_RETRY_AFTER
+	}
+
+	maxWait := c.MaxRetryAfter
+	if maxWait <= 0 {
+		maxWait = DEFAULT_MAX_RETRY_AFTER
+	}
+	if wait > maxWait {
+		return false
+	}
+	if deadline, ok := r.Context().Deadline(); ok && time.Until(deadline) < wait {
+		return false
+	}
+
+	t := time.NewTimer(wait)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+	case <-r.Context().Done():
+		return false
+	}
+
+	if r.GetBody != nil {
+		body, err := r.GetBody()
+		if err != nil {
+			return false
+		}
+		r.Body = body
+	}
+
+	if resp.Body != nil {
+		resp.Body.Close()
+	}
+
+	return true
+}
+
+// chain wraps the final CallFunc in the registered middlewares.
+func (c *APIClient) chain(final CallFunc) CallFunc {
+	c.mu.Lock()
+	middlewares := c.middlewares
+	c.mu.Unlock()
+
+	f := final
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		f = middlewares[i](f)
+	}
+	return f
+}
+
+// isAccepted checks if the status code is one of the accepted success statuses.
+// Only http.StatusOK is accepted if no accepted statuses are given.
+func isAccepted(code int, accepted []int) bool {
+	if len(accepted) == 0 {
+		return code == http.StatusOK
+	}
+
+	for _, v := range accepted {
+		if v == code {
+			return true
+		}
+	}
+	return false
+}
+
+// checkResponse checks that the response status is accepted and runs the ValidateResponse hook.
+func (c *APIClient) checkResponse(resp *http.Response, accepted []int) error {
+	return c.checkSuccess(resp, isAccepted(resp.StatusCode, accepted))
+}
+
+// checkSuccess returns a ResponseError for unsuccessful responses and runs the ValidateResponse hook on successful ones.
+func (c *APIClient) checkSuccess(resp *http.Response, success bool) error {
+	if !success {
+		return MakeResponseError(resp)
+	}
+
+	if c.ValidateResponse != nil {
+		return c.ValidateResponse(resp)
+	}
+
+	return nil
+}
+
+// decodeResult decodes the response body into result with the codec.
+// Responses without content (204 No Content or an empty body) are not decoded.
+func decodeResult(resp *http.Response, result interface{}, codec Codec) error {
+	if resp.StatusCode == http.StatusNoContent || resp.Body == nil || result == nil {
+		return nil
+	}
+
+	body := bufio.NewReader(resp.Body)
+	if _, err := body.Peek(1); err == io.EOF {
+		return nil
+	}
+
+	if err := codec.Decode(body, result); err != nil {
+		return &DecodeError{StatusCode: resp.StatusCode, ContentType: resp.Header.Get("Content-Type"), Err: err}
+	}
+
+	return nil
+}
+
+// ResponseRecord is an entry in the response history of the APIClient.
+type ResponseRecord struct {
+	StatusCode int
+	RequestID  string
 }
 
 // Adds response codes to client
-func (c *APIClient) addResponseCode(code int) {
-	c.respCodes = append(c.respCodes, code)
+// Drops the oldest response if the history is full.
+func (c *APIClient) addResponse(code int, requestID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	size := c.ResponseHistorySize
+	if size <= 0 {
+		size = DEFAULT_RESPONSE_HISTORY_SIZE
+	}
+
+	c.history = append(c.history, ResponseRecord{StatusCode: code, RequestID: requestID})
+	if len(c.history) > size {
+		n := copy(c.history, c.history[len(c.history)-size:])
+		c.history = c.history[:n]
+	}
 }
 
 // Retrieves last inputted response code
 func (c *APIClient) GetLastResponseCode() int {
-	if len(c.respCodes) == 0 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.history) == 0 {
 		return 0
 	}
-	return c.respCodes[len(c.respCodes)-1]
+	return c.history[len(c.history)-1].StatusCode
+}
+
+// GetLastRequestID retrieves the request id of the last received response.
+func (c *APIClient) GetLastRequestID() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.history) == 0 {
+		return ""
+	}
+	return c.history[len(c.history)-1].RequestID
 }
 
-// GetResponseCodes retrieves all response codes
+// GetLastResponseInfo retrieves metadata about the last received response.
+// Returns a zero ResponseInfo if no response has been received.
+func (c *APIClient) GetLastResponseInfo() ResponseInfo {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	info := c.lastResponse
+	info.Header = info.Header.Clone()
+	return info
+}
+
+// GetResponseCodes retrieves all kept response codes, oldest first.
+// The returned slice is a copy and can be modified freely.
 func (c *APIClient) GetResponseCodes() []int {
-	return c.respCodes
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	codes := make([]int, len(c.history))
+	for i, v := range c.history {
+		codes[i] = v.StatusCode
+	}
+	return codes
+}
+
+// GetResponseHistory retrieves all kept response codes together with their request ids, oldest first.
+// The returned slice is a copy and can be modified freely.
+func (c *APIClient) GetResponseHistory() []ResponseRecord {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	history := make([]ResponseRecord, len(c.history))
+	copy(history, c.history)
+	return history
+}
+
+// newRequestID generates a random (version 4) UUID used as request id.
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
 }
 
 // StatusCheck checks if the Publit service is up.
 func (c *APIClient) StatusCheck() (bool, error) {
-	url, err := c.compileStatusCheckURL()
+	r, err := c.statusCheck()
 
 	if err != nil {
 		return false, err
 	}
 
-	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if r.Body != nil {
+		r.Body.Close()
+	}
+
+	if r.StatusCode != http.StatusOK {
+		return false, nil
+	}
 
+	return true, nil
+}
+
+// ServiceStatus holds the details of a status check response.
+type ServiceStatus struct {
+	// Up is true if the status check responded with 200 OK.
+	Up bool `json:"-"`
+	// StatusCode is the status code of the status check response.
+	StatusCode int `json:"-"`
+	// Status is the overall status reported by the service.
+	Status string `json:"status"`
+	// Version is the version of the service.
+	Version string `json:"version"`
+	// Timestamp is the time of the status check.
+	Timestamp common.PublitTime `json:"timestamp"`
+	// Components holds the statuses of the components of the service.
+	Components []ComponentStatus `json:"components"`
+}
+
+// ComponentStatus is the status of a component of the Publit service, such as a database or queue.
+type ComponentStatus struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+// Degraded returns the components with another status than "ok" or "up".
+func (s *ServiceStatus) Degraded() []ComponentStatus {
+	var degraded []ComponentStatus
+	for _, v := range s.Components {
+		if !strings.EqualFold(v.Status, "ok") && !strings.EqualFold(v.Status, "up") {
+			degraded = append(degraded, v)
+		}
+	}
+	return degraded
+}
+
+// StatusCheckDetailed checks the status of the Publit service and decodes the details given by the status check.
+// A service that is down is not an error, check ServiceStatus.Up. Details are decoded from json responses only.
+func (c *APIClient) StatusCheckDetailed() (*ServiceStatus, error) {
+	r, err := c.statusCheck()
 	if err != nil {
-		return false, err
+		return nil, err
+	}
+	if r.Body != nil {
+		defer r.Body.Close()
 	}
 
-	// Use CallRaw since no authentication is needed for status check.
-	r, err := c.Client.CallRaw(req)
-	c.addResponseCode(r.StatusCode)
+	status := &ServiceStatus{Up: r.StatusCode == http.StatusOK, StatusCode: r.StatusCode}
+	if !isJSONMediaType(r.Header.Get("Content-Type")) {
+		return status, nil
+	}
+
+	if err := decodeResult(r, status, JSONCodec); err != nil {
+		return status, err
+	}
+
+	return status, nil
+}
+
+// statusCheck performs the unauthenticated status check request.
+func (c *APIClient) statusCheck() (*http.Response, error) {
+	url, err := c.compileStatusCheckURL()
 
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
-	if r.StatusCode != http.StatusOK {
-		return false, nil
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+
+	if err != nil {
+		return nil, err
 	}
 
-	return true, nil
+	// Use CallRaw since no authentication is needed for status check.
+	return c.callRaw(req)
 }
 
 // Compiles statuscheck URL against the admin API.
@@ -101,189 +637,635 @@ func (c *APIClient) compileStatusCheckURL() (string, error) {
 		return "", errors.New("Could not compile status check URL. Missing APIClient.BaseURL")
 	}
 
-	return fmt.Sprintf("%s/%s/%s", c.BaseURL, API_VERSION, RESOURCE_STATUSCHECK), nil
+	return joinURL(c.BaseURL, c.version(), RESOURCE_STATUSCHECK)
 }
 
 // SetNewAPIToken creates and sets new token to client.
 func (c *APIClient) SetNewAPIToken() error {
+	return c.SetNewAPITokenContext(context.Background())
+}
+
+// SetNewAPITokenContext creates and sets new token to client, like SetNewAPIToken.
+// The token request is cancelled when ctx is done.
+// It fails with ErrClientClosed once the APIClient has been closed.
+func (c *APIClient) SetNewAPITokenContext(ctx context.Context) error {
+	if c.isClosed() {
+		return ErrClientClosed
+	}
+
 	url, err := c.compileTokenURL()
 	if err != nil {
 		return err
 	}
 
-	req, err := http.NewRequest(http.MethodPost, url, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
 	if err != nil {
 		return err
 	}
 	return c.Client.SetNewAPIToken(req)
 }
 
-func (c APIClient) compileTokenURL() (string, error) {
+func (c *APIClient) compileTokenURL() (string, error) {
 	if c.BaseURL == "" || c.API == "" {
 		return "", errors.New("Could not compile Token URL, missing one or both of APIClient.BaseURL or APIClient.API")
 	}
 
-	return fmt.Sprintf("%s/%s/%s/%s", c.BaseURL, c.API, API_VERSION, RESOURCE_TOKEN), nil
+	return joinURL(c.BaseURL, c.API, c.version(), RESOURCE_TOKEN)
 }
 
 // Get Performs a GET method action against the Publit admin API.
 // Also decodes response body to json
 func (c *APIClient) Get(endpoint Endpointer, model interface{}, queryParams ...func(q url.Values)) error {
+	return c.Do(http.MethodGet, endpoint, nil, model, WithQuery(queryParams...))
+}
+
+// GetWithHeaders performs a GET method action against the Publit admin API with custom headers, e.g. for overriding Accept
+// or setting a locale. Like Get it decodes the response body into model.
+func (c *APIClient) GetWithHeaders(endpoint Endpointer, model interface{}, headers []func(h *http.Header), queryParams ...func(q url.Values)) error {
+	return c.Do(http.MethodGet, endpoint, nil, model, WithHeaders(headers...), WithQuery(queryParams...))
+}
+
+// GetAll performs GET requests against an index endpoint and follows the pagination until all records are fetched.
+// The model must be a pointer to a slice, the records in the "data" attribute of each page are appended to it.
+// Paging stops when the server returns fewer records than requested.
+// GetAll sets the limit query parameter itself, any limit given in queryParams is overridden.
+func (c *APIClient) GetAll(endpoint Endpointer, model interface{}, queryParams ...func(q url.Values)) error {
+	rv := reflect.ValueOf(model)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Slice {
+		return errors.New("Could not get all records. Model must be a non nil pointer to a slice")
+	}
+	records := rv.Elem()
+
+	pageSize := c.PageSize
+	if pageSize <= 0 {
+		pageSize = DEFAULT_PAGE_SIZE
+	}
+
+	for offset := 0; ; offset += pageSize {
+		page := reflect.New(records.Type())
+		envelope := &struct {
+			Data interface{} `json:"data"`
+		}{Data: page.Interface()}
+
+		params := append(queryParams[:len(queryParams):len(queryParams)], pageLimit(pageSize, offset))
+		if err := c.Get(endpoint, envelope, params...); err != nil {
+			return err
+		}
+
+		records.Set(reflect.AppendSlice(records, page.Elem()))
+
+		if page.Elem().Len() < pageSize {
+			return nil
+		}
+	}
+}
+
+// pageLimit replaces any previously set limit query parameter.
+func pageLimit(limit, offset int) func(q url.Values) {
+	setLimit := common.QueryLimit(limit, offset)
+	return func(q url.Values) {
+		q.Del(common.QUERY_KEY_LIMIT)
+		setLimit(q)
+	}
+}
+
+// GetRequest describes a single GET request made by GetConcurrently.
+type GetRequest struct {
+	Endpoint    Endpointer
+	Model       interface{}
+	QueryParams []func(q url.Values)
+}
+
+// GetConcurrently performs the GET requests concurrently, with at most workers requests in flight at the same time.
+// Each response is decoded into the model of its request.
+// The returned errors are in the same order as the requests, with nil for successful requests.
+// Requests not yet sent when ctx is done are not performed and get the context error.
+func (c *APIClient) GetConcurrently(ctx context.Context, workers int, requests []GetRequest) []error {
+	if workers <= 0 {
+		workers = DEFAULT_CONCURRENCY
+	}
+
+	errs := make([]error, len(requests))
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if err := ctx.Err(); err != nil {
+					errs[i] = err
+					continue
+				}
+
+				r := requests[i]
+				errs[i] = c.Do(http.MethodGet, r.Endpoint, nil, r.Model, WithContext(ctx), WithQuery(r.QueryParams...))
+			}
+		}()
+	}
+
+	for i := range requests {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return errs
+}
+
+// GetStream performs a GET request and passes the records of the response to callback one at a time.
+// The response is decoded token by token, so the whole body never has to be held in memory.
+// The response can either be a json array or an object with the records in its "data" attribute.
+// Streaming stops at the first error returned from callback, and that error is returned.
+func (c *APIClient) GetStream(endpoint Endpointer, callback func(record json.RawMessage) error, queryParams ...func(q url.Values)) error {
 	resp, err := c.GetWithRawResponse(endpoint, queryParams...)
 	if err != nil {
+		closeBody(resp)
 		return err
 	}
-	defer resp.Body.Close()
-	c.addResponseCode(resp.StatusCode)
+	if resp.Body != nil {
+		defer resp.Body.Close()
+	}
 
-	if err != nil {
+	if err := c.checkResponse(resp, c.AcceptedStatuses); err != nil {
 		return err
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return MakeResponseError(resp)
+	if resp.Body == nil {
+		return nil
 	}
 
-	err = json.NewDecoder(resp.Body).Decode(model)
+	return streamRecords(json.NewDecoder(resp.Body), callback)
+}
 
+// streamRecords finds the record array in the decoder stream and streams it to callback.
+func streamRecords(dec *json.Decoder, callback func(record json.RawMessage) error) error {
+	t, err := dec.Token()
 	if err != nil {
 		return err
 	}
 
-	return nil
+	switch t {
+	case json.Delim('['):
+		return streamArray(dec, callback)
+	case json.Delim('{'):
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return err
+			}
+
+			if key != "data" {
+				// Skip values of other attributes.
+				var skip json.RawMessage
+				if err := dec.Decode(&skip); err != nil {
+					return err
+				}
+				continue
+			}
+
+			t, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			if t == nil {
+				return nil
+			}
+			if t != json.Delim('[') {
+				return errors.New("Could not stream records. Response data attribute is not an array")
+			}
+			return streamArray(dec, callback)
+		}
+		return errors.New("Could not stream records. Response has no data attribute")
+	}
+
+	return errors.New("Could not stream records. Response is neither a json array nor an object")
+}
+
+// streamArray decodes the elements of an already opened json array one at a time.
+func streamArray(dec *json.Decoder, callback func(record json.RawMessage) error) error {
+	for dec.More() {
+		var record json.RawMessage
+		if err := dec.Decode(&record); err != nil {
+			return err
+		}
+
+		if err := callback(record); err != nil {
+			return err
+		}
+	}
+
+	// Consume closing bracket.
+	_, err := dec.Token()
+	return err
+}
+
+// DownloadInfo holds metadata about a downloaded asset.
+type DownloadInfo struct {
+	// ContentType is the Content-Type header of the response.
+	ContentType string
+	// ContentLength is the amount of bytes written to the writer.
+	ContentLength int64
+	// Checksum is the hex encoded SHA-256 checksum of the written bytes.
+	Checksum string
+}
+
+// Download performs a GET request and streams the response body (PDFs, EPUBs, images etc.) to w without decoding it.
+// If copying fails midway the returned DownloadInfo describes the bytes written so far together with the error.
+func (c *APIClient) Download(endpoint Endpointer, w io.Writer, queryParams ...func(q url.Values)) (*DownloadInfo, error) {
+	return c.download(endpoint, w, WithQuery(queryParams...))
+}
+
+// GetIntoWriter performs a GET request and copies the raw response body into w, so large exports never have to fit in memory.
+// The response status is checked like in Do, and a status that is not accepted is returned as a *ResponseError without
+// anything being written to w. Returns the amount of bytes written. See Download for the metadata of the written body.
+func (c *APIClient) GetIntoWriter(endpoint Endpointer, w io.Writer, opts ...RequestOption) (int64, error) {
+	info, err := c.download(endpoint, w, opts...)
+	if info == nil {
+		return 0, err
+	}
+	return info.ContentLength, err
+}
+
+// download performs a GET request with the options and copies the response body to w, see Download.
+func (c *APIClient) download(endpoint Endpointer, w io.Writer, opts ...RequestOption) (*DownloadInfo, error) {
+	o := c.newRequestOptions(opts...)
+	ctx, cancel := o.context()
+	defer cancel()
+
+	req, err := c.newRequest(ctx, http.MethodGet, endpoint, nil, o)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.call(req)
+	if err != nil {
+		closeBody(resp)
+		return nil, err
+	}
+	if resp.Body != nil {
+		defer resp.Body.Close()
+	}
+
+	if err := c.checkSuccess(resp, o.succeeded(resp)); err != nil {
+		return nil, err
+	}
+
+	info := &DownloadInfo{ContentType: resp.Header.Get("Content-Type")}
+	hash := sha256.New()
+
+	if resp.Body != nil {
+		info.ContentLength, err = io.Copy(io.MultiWriter(w, hash), resp.Body)
+	}
+	info.Checksum = hex.EncodeToString(hash.Sum(nil))
+
+	return info, err
 }
 
 // GetWithRawResponse perform get call and returns raw response body
 func (c *APIClient) GetWithRawResponse(endpoint Endpointer, queryParams ...func(q url.Values)) (resp *http.Response, err error) {
-	epoint, err := endpoint.GetEndpoint()
+	o := c.newRequestOptions(WithQuery(queryParams...))
+	req, err := c.newRequest(context.Background(), http.MethodGet, endpoint, nil, o)
 	if err != nil {
 		return
 	}
 
-	endUrl := c.CompileEndpointURL(epoint)
-	req, _ := http.NewRequest(http.MethodGet, endUrl, nil)
+	return c.call(req)
+}
 
-	q := req.URL.Query()
-	for _, v := range queryParams {
-		v(q)
+// GetRaw performs a GET request and returns the read response body together with the response status code.
+// Unlike Get the status is not checked, so the body is returned as is also for error responses.
+func (c *APIClient) GetRaw(endpoint Endpointer, queryParams ...func(q url.Values)) ([]byte, int, error) {
+	resp, err := c.GetWithRawResponse(endpoint, queryParams...)
+	if err != nil {
+		closeBody(resp)
+		return nil, 0, err
 	}
-	req.URL.RawQuery = q.Encode()
 
-	return c.Client.Call(req)
+	if resp.Body == nil {
+		return nil, resp.StatusCode, nil
+	}
+	defer resp.Body.Close()
+
+	b, err := ioutil.ReadAll(resp.Body)
+	return b, resp.StatusCode, err
 }
 
 // Post performs a POST method action against the Publit API.
+// An io.Reader payload is streamed as the request body without encoding, set its Content-Type with a header func.
 func (c *APIClient) Post(endpoint Endpointer, payload interface{}, result interface{}, headers ...func(h *http.Header)) error {
-	return c.postPut(http.MethodPost, endpoint, payload, result, headers...)
+	return c.Do(http.MethodPost, endpoint, payload, result, WithHeaders(headers...))
 }
 
 // Put performs a PUT method action against the Publit API.
+// An io.Reader payload is streamed as the request body without encoding, set its Content-Type with a header func.
 func (c *APIClient) Put(endpoint Endpointer, payload interface{}, result interface{}, headers ...func(h *http.Header)) error {
-	return c.postPut(http.MethodPut, endpoint, payload, result, headers...)
+	return c.Do(http.MethodPut, endpoint, payload, result, WithHeaders(headers...))
 }
 
-// postPut performs a post or put method action against the Publit admin API.
-func (c *APIClient) postPut(method string, endpoint Endpointer, payload interface{}, result interface{}, headers ...func(h *http.Header)) error {
-	epoint, err := endpoint.GetEndpoint()
+// Do performs a request with the given http method against the Publit API and decodes the response body into result.
+// The payload is encoded with the codec (json by default) and sent as request body unless it is nil.
+// Query params, headers, context and timeout are set per request through RequestOptions.
+func (c *APIClient) Do(method string, endpoint Endpointer, payload interface{}, result interface{}, opts ...RequestOption) error {
+	o := c.newRequestOptions(opts...)
+	ctx, cancel := o.context()
+	defer cancel()
+
+	req, err := c.buildRequest(ctx, method, endpoint, payload, o)
 	if err != nil {
 		return err
 	}
-	endUrl := c.CompileEndpointURL(epoint)
 
-	body, err := json.Marshal(payload)
+	return c.doRequest(req, result, o)
+}
+
+// BuildRequest composes the request Do would send, without sending it.
+// The request has its URL, encoded body and headers set, but no authentication since that is added by the APICaller.
+// Useful for tooling and tests that need to inspect exactly what would be sent.
+func (c *APIClient) BuildRequest(method string, endpoint Endpointer, payload interface{}, opts ...RequestOption) (*http.Request, error) {
+	o := c.newRequestOptions(opts...)
+
+	ctx := o.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
 
+	return c.buildRequest(ctx, method, endpoint, payload, o)
+}
+
+// buildRequest creates the request with the Accept header set for the codec.
+func (c *APIClient) buildRequest(ctx context.Context, method string, endpoint Endpointer, payload interface{}, o *requestOptions) (*http.Request, error) {
+	req, err := c.newRequest(ctx, method, endpoint, payload, o)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	if req.Header.Get("Accept") == "" {
+		req.Header.Set("Accept", o.codec.ContentType())
+	}
+
+	return req, nil
+}
+
+// newRequest compiles the endpoint URL and creates a request with encoded payload, query params and headers.
+func (c *APIClient) newRequest(ctx context.Context, method string, endpoint Endpointer, payload interface{}, o *requestOptions) (*http.Request, error) {
+	epoint, err := endpoint.GetEndpoint()
+	if err != nil {
+		return nil, err
+	}
+	endUrl, err := c.compileEndpointURL(epoint, o.version)
+	if err != nil {
+		return nil, err
+	}
+
+	var body io.Reader
+	contentType := o.codec.ContentType()
+	if r, ok := payload.(io.Reader); ok {
+		// Readers are pre-encoded content streamed as is.
+		body = r
+		contentType = DEFAULT_READER_CONTENT_TYPE
+	} else if payload != nil {
+		if err := c.validatePayload(payload); err != nil {
+			return nil, err
+		}
+
+		b := &bytes.Buffer{}
+		if err := o.codec.Encode(b, payload); err != nil {
+			return nil, err
+		}
+		body = b
+	}
+
+	if o.accountID != 0 {
+		ctx = client.ContextWithAccountID(ctx, o.accountID)
+	}
+
+	req, err := http.NewRequestWithContext(withEndpoint(ctx, epoint), method, endUrl, body)
+	if err != nil {
+		return nil, err
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
 	}
 
-	req, _ := http.NewRequest(method, endUrl, bytes.NewBuffer(body))
-	req.Header.Set("Content-Type", "application/json")
+	q := req.URL.Query()
+	for _, v := range o.query {
+		v(q)
+	}
+	req.URL.RawQuery = q.Encode()
 
 	h := &req.Header
-	for _, v := range headers {
+	for _, v := range o.headers {
 		v(h)
 	}
 
-	resp, err := c.Client.Call(req)
-	c.addResponseCode(resp.StatusCode)
-	if err != nil {
+	return req, nil
+}
+
+// PostMultipart performs a POST method action against the Publit API with a multipart/form-data body.
+// fields are written as regular form fields and files as file parts, both indexed by form field name.
+// If a file reader has a Name method (like *os.File) its base name is used as file name, otherwise the field name is used.
+// The fields are validated by the PayloadValidators before the request is sent, and the RequestOptions are applied
+// like for Do.
+func (c *APIClient) PostMultipart(endpoint Endpointer, fields map[string]string, files map[string]io.Reader, result interface{}, opts ...RequestOption) error {
+	if err := c.validatePayload(fields); err != nil {
 		return err
 	}
 
-	if resp.Body != nil {
-		defer resp.Body.Close()
+	body := &bytes.Buffer{}
+	w := multipart.NewWriter(body)
+
+	for _, k := range sortedKeys(fields) {
+		if err := w.WriteField(k, fields[k]); err != nil {
+			return err
+		}
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return MakeResponseError(resp)
+	fileFields := make([]string, 0, len(files))
+	for k := range files {
+		fileFields = append(fileFields, k)
+	}
+	sort.Strings(fileFields)
+
+	for _, k := range fileFields {
+		filename := k
+		if n, ok := files[k].(interface{ Name() string }); ok {
+			filename = filepath.Base(n.Name())
+		}
+
+		part, err := w.CreateFormFile(k, filename)
+		if err != nil {
+			return err
+		}
+
+		if _, err := io.Copy(part, files[k]); err != nil {
+			return err
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		return err
 	}
 
-	err = json.NewDecoder(resp.Body).Decode(result)
+	o := c.newRequestOptions(opts...)
+	ctx, cancel := o.context()
+	defer cancel()
 
+	req, err := c.buildRequest(ctx, http.MethodPost, endpoint, body, o)
 	if err != nil {
 		return err
 	}
+	req.Header.Set("Content-Type", w.FormDataContentType())
 
-	return nil
+	return c.doRequest(req, result, o)
+}
+
+// sortedKeys returns the keys of the map in sorted order.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
 }
 
 // Delete performs a DELETE http call against the Publit API.
 func (c *APIClient) Delete(endpoint Endpointer, result interface{}, headers ...func(h *http.Header)) error {
-	epoint, err := endpoint.GetEndpoint()
+	return c.Do(http.MethodDelete, endpoint, nil, result, WithHeaders(headers...))
+}
+
+// doRequest performs the authenticated request, checks the response status and decodes the response body into result.
+func (c *APIClient) doRequest(req *http.Request, result interface{}, o *requestOptions) error {
+	resp, err := c.call(req)
 	if err != nil {
+		closeBody(resp)
 		return err
 	}
-	endUrl := c.CompileEndpointURL(epoint)
-	req, _ := http.NewRequest(http.MethodDelete, endUrl, nil)
 
-	h := &req.Header
-	for _, v := range headers {
-		v(h)
+	if resp.Body != nil {
+		defer resp.Body.Close()
 	}
 
-	resp, err := c.Client.Call(req)
-	c.addResponseCode(resp.StatusCode)
-	if err != nil {
+	if err := c.checkSuccess(resp, o.succeeded(resp)); err != nil {
 		return err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return MakeResponseError(resp)
+	if err := decodeResult(resp, result, o.codec); err != nil {
+		return err
 	}
 
-	err = json.NewDecoder(resp.Body).Decode(result)
-
-	if err != nil {
-		return err
+	if hs, ok := result.(HeaderSetter); ok {
+		hs.SetResponseHeaders(resp.Header.Clone())
 	}
 
 	return nil
 }
 
+// closeBody closes the body of the response, if any. Used for responses returned together with an error.
+func closeBody(resp *http.Response) {
+	if resp != nil && resp.Body != nil {
+		resp.Body.Close()
+	}
+}
+
+// HeaderSetter is implemented by results that need the response headers alongside the body,
+// e.g. for reading Location, pagination or rate limit headers.
+// SetResponseHeaders is called with a copy of the response headers after the body has been decoded.
+type HeaderSetter interface {
+	SetResponseHeaders(h http.Header)
+}
+
 // CompileEndpointURL compiles regular endpoints URL.
 // Endpoints are defined in format baseurl / api / version / endpoint
-func (c APIClient) CompileEndpointURL(endpoint string) string {
-	return fmt.Sprintf("%v/%v/%v/%v", c.BaseURL, c.API, API_VERSION, endpoint)
+// If the URL can not be composed, e.g. due to an invalid BaseURL, the segments are joined with slashes as is.
+//
+// Deprecated: Use EndpointURL, which returns the error instead.
+func (c *APIClient) CompileEndpointURL(endpoint string) string {
+	u, err := c.EndpointURL(endpoint)
+	if err != nil {
+		return fmt.Sprintf("%v/%v/%v/%v", c.BaseURL, c.API, c.version(), endpoint)
+	}
+	return u
 }
 
-// MakeResponseError attempts to make a better response error from response.
-func MakeResponseError(resp *http.Response) error {
-	if resp.Header.Get("Content-Type") == "application/json" {
-		APIErr := &common.APIErrorResponse{}
-		err := json.NewDecoder(resp.Body).Decode(APIErr)
-		if err == nil && APIErr.HasInformation() { // Only return this error message if APIErr has information.
-			return APIErr.GetAsError()
-		}
+// EndpointURL compiles the URL of the endpoint with the URLBuilder, in format baseurl / api / version / endpoint by
+// default. Returns an error if the URL can not be composed, e.g. due to an invalid BaseURL.
+func (c *APIClient) EndpointURL(endpoint string) (string, error) {
+	return c.compileEndpointURL(endpoint, c.version())
+}
+
+// compileEndpointURL compiles the endpoint URL against the given API version with the URLBuilder.
+func (c *APIClient) compileEndpointURL(endpoint, version string) (string, error) {
+	build := c.URLBuilder
+	if build == nil {
+		build = DefaultURLBuilder
+	}
+	return build(c.BaseURL, c.API, version, endpoint)
+}
+
+// URLBuilder composes the URL of a request from the base URL, API, API version and endpoint.
+type URLBuilder func(baseURL, api, version, endpoint string) (string, error)
+
+// DefaultURLBuilder joins the base URL, API, version and endpoint as path segments with url.JoinPath.
+// Redundant slashes are removed, and characters of the endpoint reserved in URLs (such as ? and #) are escaped.
+// Any query of the base URL is kept.
+func DefaultURLBuilder(baseURL, api, version, endpoint string) (string, error) {
+	return joinURL(baseURL, api, version, endpoint)
+}
+
+// joinURL joins the path elements to the base URL.
+func joinURL(baseURL string, elem ...string) (string, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return "", err
 	}
 
-	// Special check for unauthorized reponse.
-	if resp.StatusCode == http.StatusUnauthorized {
-		return fmt.Errorf(`Unauthorized. Code: "%v"`, resp.StatusCode)
+	return u.JoinPath(elem...).String(), nil
+}
+
+// version returns the API version of the APIClient.
+func (c *APIClient) version() string {
+	if c.Version == "" {
+		return API_VERSION
 	}
+	return c.Version
+}
+
+// ErrClientClosed is returned by calls made after the APIClient has been closed.
+var ErrClientClosed = errors.New("APIClient is closed")
+
+// Close releases the resources of the APIClient so a service can shut down cleanly.
+// Further calls fail fast with ErrClientClosed. The APICaller is closed if it implements io.Closer, and idle connections
+// of its transport are closed if it has a CloseIdleConnections method (like client.Client).
+// Closing an already closed APIClient does nothing.
+func (c *APIClient) Close() error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil
+	}
+	c.closed = true
+	c.mu.Unlock()
+
+	if ic, ok := c.Client.(interface{ CloseIdleConnections() }); ok {
+		ic.CloseIdleConnections()
+	}
+
+	if closer, ok := c.Client.(io.Closer); ok {
+		return closer.Close()
+	}
+
+	return nil
+}
 
-	// Default
-	return fmt.Errorf(`Response not ok. No information given. Code: "%v"`, resp.StatusCode)
+// isClosed reports if the APIClient has been closed.
+func (c *APIClient) isClosed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.closed
 }
 
 // UnsetAuthToken wraps undest autho token from the APICaller to the APIClient
@@ -2,22 +2,100 @@ package APIClient_test
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
 	"testing"
 
 	. "github.com/publitsweden/APIUtilityGoSDK/APIClient"
 	"github.com/publitsweden/APIUtilityGoSDK/client"
+	"github.com/publitsweden/APIUtilityGoSDK/common"
 	"github.com/publitsweden/APIUtilityGoSDK/endpoint"
 )
 
 var TestAPI string = "someapi"
 
+func TestNewAPIClient(t *testing.T) {
+	t.Parallel()
+
+	t.Run(
+		"With defaults",
+		func(t *testing.T) {
+			c, err := NewAPIClient("https://test.publit.com", TestAPI)
+
+			if err != nil {
+				t.Fatal("Received an error but was not expecting to.", err)
+			}
+
+			if _, ok := c.Client.(*client.Client); !ok {
+				t.Error("Expected Client to default to a client.Client but it did not.")
+			}
+
+			if c.BaseURL != "https://test.publit.com" || c.API != TestAPI {
+				t.Error("BaseURL or API did not match expected.")
+			}
+		},
+	)
+
+	t.Run(
+		"With options",
+		func(t *testing.T) {
+			caller := &MockAPICaller{}
+			c, err := NewAPIClient(
+				"https://test.publit.com",
+				TestAPI,
+				WithCaller(caller),
+				func(c *APIClient) {
+					c.PageSize = 10
+				},
+			)
+
+			if err != nil {
+				t.Fatal("Received an error but was not expecting to.", err)
+			}
+
+			if c.Client != caller {
+				t.Error("Expected Client to be set by option but was not.")
+			}
+
+			if c.PageSize != 10 {
+				t.Error("Expected PageSize to be set by option but was not.")
+			}
+		},
+	)
+
+	t.Run(
+		"Returns error if misconfigured",
+		func(t *testing.T) {
+			table := map[string][]string{
+				"Missing base URL":     {"", TestAPI},
+				"Relative base URL":    {"test.publit.com", TestAPI},
+				"Missing API":          {"https://test.publit.com", ""},
+				"Unparseable base URL": {"https://test publit.com:port", TestAPI},
+			}
+
+			for name, v := range table {
+				if _, err := NewAPIClient(v[0], v[1]); err == nil {
+					t.Errorf("%s: Expected an error but did not receive one.", name)
+				}
+			}
+		},
+	)
+}
+
 func TestCanCheckStatus(t *testing.T) {
 	t.Parallel()
 
@@ -121,6 +199,70 @@ func TestCanCheckStatus(t *testing.T) {
 	)
 }
 
+func TestCanCheckStatusDetails(t *testing.T) {
+	t.Parallel()
+
+	caller := &MockAPICaller{}
+	caller.Response = createCallerResponse(
+		http.StatusOK,
+		`{"status":"degraded","version":"2.4.1","timestamp":"2018-05-01 12:00:00","components":[{"name":"database","status":"ok"},{"name":"queue","status":"down","message":"timeout"}]}`,
+	)
+	caller.Response.Header = http.Header{"Content-Type": {"application/json"}}
+
+	c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI}
+
+	status, err := c.StatusCheckDetailed()
+	if err != nil {
+		t.Fatal("Received an error but was not expecting to.", err)
+	}
+
+	if !status.Up || status.Status != "degraded" || status.Version != "2.4.1" || status.Timestamp != "2018-05-01 12:00:00" {
+		t.Errorf("Unexpected status. Got %+v", status)
+	}
+
+	degraded := status.Degraded()
+	if len(degraded) != 1 || degraded[0].Name != "queue" || degraded[0].Message != "timeout" {
+		t.Errorf("Unexpected degraded components. Got %+v", degraded)
+	}
+}
+
+func TestClosedClientFailsFast(t *testing.T) {
+	t.Parallel()
+
+	caller := &ClosableMockAPICaller{}
+	caller.T = t
+	caller.Response = createCallerResponse(http.StatusOK, `{}`)
+	caller.CallTestCallback = func(t *testing.T, r *http.Request) {
+		t.Error("Did not expect any request to be sent.")
+	}
+
+	c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI}
+
+	if err := c.Close(); err != nil {
+		t.Error("Received an error but was not expecting to.", err)
+	}
+
+	if !caller.IdleClosed || !caller.Closed {
+		t.Error("Expected caller to be closed.")
+	}
+
+	if err := c.Get(NewEndpoint(), &struct{}{}); !errors.Is(err, ErrClientClosed) {
+		t.Errorf("Expected closed error, got %v", err)
+	}
+
+	if _, err := c.StatusCheck(); !errors.Is(err, ErrClientClosed) {
+		t.Errorf("Expected closed error, got %v", err)
+	}
+
+	if err := c.SetNewAPIToken(); !errors.Is(err, ErrClientClosed) {
+		t.Errorf("Expected closed error, got %v", err)
+	}
+
+	if err := c.Close(); err != nil {
+		t.Error("Expected closing twice to do nothing.", err)
+	}
+}
+
 func TestCanSetNewAPIToken(t *testing.T) {
 	t.Parallel()
 
@@ -180,6 +322,74 @@ func TestCanSetNewAPIToken(t *testing.T) {
 	)
 }
 
+func TestSetNewAPITokenRespectsContext(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("token", "sometoken")
+	}))
+	defer ts.Close()
+
+	c, _ := NewAPIClient(ts.URL, TestAPI, WithCaller(client.New(func(c *client.Client) {
+		c.User = "someuser"
+		c.Password = "somepassword"
+	})))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := c.SetNewAPITokenContext(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context error, got %v", err)
+	}
+
+	if err := c.SetNewAPITokenContext(context.Background()); err != nil {
+		t.Errorf("Received an error but did not expect one: %v", err)
+	}
+}
+
+func TestCompileEndpointURL(t *testing.T) {
+	t.Parallel()
+
+	table := []struct {
+		BaseURL  string
+		Endpoint string
+		Expected string
+	}{
+		{"https://test.publit.com", "books/1", "https://test.publit.com/someapi/v2.0/books/1"},
+		{"https://test.publit.com/", "/books/1", "https://test.publit.com/someapi/v2.0/books/1"},
+		{"https://test.publit.com/proxy", "books/a b?c#d", "https://test.publit.com/proxy/someapi/v2.0/books/a%20b%3Fc%23d"},
+	}
+
+	for _, v := range table {
+		c := &APIClient{BaseURL: v.BaseURL, API: TestAPI}
+		if u, err := c.EndpointURL(v.Endpoint); u != v.Expected || err != nil {
+			t.Errorf("Unexpected URL. Expected %s, got %s, %v", v.Expected, u, err)
+		}
+		if u := c.CompileEndpointURL(v.Endpoint); u != v.Expected {
+			t.Errorf("Unexpected URL. Expected %s, got %s", v.Expected, u)
+		}
+	}
+
+	invalid := &APIClient{BaseURL: ":invalid", API: TestAPI}
+	if _, err := invalid.EndpointURL("books"); err == nil {
+		t.Error("Expected an error for an invalid BaseURL but did not receive one.")
+	}
+	if u := invalid.CompileEndpointURL("books"); u != ":invalid/someapi/v2.0/books" {
+		t.Errorf("Expected segments to be joined as is for an invalid BaseURL. Got %s", u)
+	}
+
+	c := &APIClient{
+		BaseURL: "https://test.publit.com",
+		API:     TestAPI,
+		URLBuilder: func(baseURL, api, version, endpoint string) (string, error) {
+			return baseURL + "/" + endpoint, nil
+		},
+	}
+	if u := c.CompileEndpointURL("books"); u != "https://test.publit.com/books" {
+		t.Errorf("Expected URL builder to be used. Got %s", u)
+	}
+}
+
 func TestCanPerformGetRequestWithRawResponse(t *testing.T) {
 	caller := &MockAPICaller{}
 	expectedBody := `{"some":"body"}`
@@ -200,13 +410,36 @@ func TestCanPerformGetRequestWithRawResponse(t *testing.T) {
 
 	defer resp.Body.Close()
 
-	body,_ := ioutil.ReadAll(resp.Body)
+	body, _ := ioutil.ReadAll(resp.Body)
 
 	if string(body) != string(expectedBody) {
 		t.Errorf("Unexpected body. Expected %s, got %s", expectedBody, body)
 	}
 }
 
+func TestCanPerformGetRequestWithRawBody(t *testing.T) {
+	t.Parallel()
+
+	caller := &MockAPICaller{}
+	expectedBody := `{"some":"error"}`
+	caller.Response = createCallerResponse(http.StatusBadRequest, expectedBody)
+
+	c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI}
+
+	body, status, err := c.GetRaw(NewEndpoint())
+	if err != nil {
+		t.Error("Unexpected error.", err)
+	}
+
+	if status != http.StatusBadRequest {
+		t.Errorf("Unexpected status code. Got %d, expected %d", status, http.StatusBadRequest)
+	}
+
+	if string(body) != expectedBody {
+		t.Errorf("Unexpected body. Expected %s, got %s", expectedBody, body)
+	}
+}
+
 func TestCanPerformGetRequest(t *testing.T) {
 	t.Parallel()
 
@@ -236,6 +469,43 @@ func TestCanPerformGetRequest(t *testing.T) {
 	}
 }
 
+func TestCanPerformGetRequestWithHeaders(t *testing.T) {
+	t.Parallel()
+
+	caller := &MockAPICaller{}
+	caller.T = t
+	caller.Response = createCallerResponse(http.StatusOK, `{"some":"body"}`)
+	caller.CallTestCallback = func(t *testing.T, r *http.Request) {
+		if r.Header.Get("Accept-Language") != "sv" {
+			t.Error("Expected header to be set but was not.")
+		}
+
+		if r.URL.Query().Get(common.QUERY_KEY_LIMIT) != "0,1" {
+			t.Error("Expected query param to be set but was not.")
+		}
+	}
+
+	c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI}
+
+	model := &struct {
+		Some string `json:"some"`
+	}{}
+
+	headers := []func(h *http.Header){
+		func(h *http.Header) {
+			h.Set("Accept-Language", "sv")
+		},
+	}
+
+	if err := c.GetWithHeaders(NewEndpoint(), model, headers, common.QueryLimit(1, 0)); err != nil {
+		t.Error("Expected GetWithHeaders to pass but received error.", err)
+	}
+
+	if model.Some != "body" {
+		t.Error("Unmarshalled struct did not match expected.")
+	}
+}
+
 func TestGetReturnsErrorIfCallFails(t *testing.T) {
 	t.Parallel()
 
@@ -299,6 +569,174 @@ func TestGetReturnsErrorIfBodyCanNotBeUnmarshalled(t *testing.T) {
 	}
 }
 
+func TestCanGetAllPages(t *testing.T) {
+	t.Parallel()
+
+	pages := []string{
+		`{"count":5,"data":[{"id":1},{"id":2}]}`,
+		`{"count":5,"data":[{"id":3},{"id":4}]}`,
+		`{"count":5,"data":[{"id":5}]}`,
+	}
+
+	caller := &MockAPICaller{}
+	caller.T = t
+	limits := []string{}
+	caller.CallTestCallback = func(t *testing.T, r *http.Request) {
+		limits = append(limits, r.URL.Query()[common.QUERY_KEY_LIMIT]...)
+		caller.Response = createCallerResponse(http.StatusOK, pages[len(limits)-1])
+	}
+
+	c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI, PageSize: 2}
+
+	model := []struct {
+		ID int `json:"id"`
+	}{}
+
+	err := c.GetAll(NewEndpoint(), &model, common.QueryLimit(50, 0))
+
+	if err != nil {
+		t.Error("Expected GetAll to pass but received error.", err)
+	}
+
+	if len(model) != 5 || model[4].ID != 5 {
+		t.Errorf("Aggregated records did not match expected. Got %+v", model)
+	}
+
+	expectedLimits := []string{"0,2", "2,2", "4,2"}
+	if !reflect.DeepEqual(limits, expectedLimits) {
+		t.Errorf("Unexpected limits. Expected %v, got %v", expectedLimits, limits)
+	}
+}
+
+func TestGetAllReturnsErrorIfModelIsNotASlicePointer(t *testing.T) {
+	t.Parallel()
+
+	c := &APIClient{Client: &MockAPICaller{}, BaseURL: "somebaseurl", API: TestAPI}
+
+	model := struct{}{}
+	err := c.GetAll(NewEndpoint(), &model)
+
+	if err == nil {
+		t.Error("Expected an error due to faulty model but did not receive one.")
+	}
+}
+
+func TestCanGetConcurrently(t *testing.T) {
+	t.Parallel()
+
+	c := &APIClient{Client: &ConcurrentMockAPICaller{}, BaseURL: "somebaseurl", API: TestAPI}
+
+	failing := NewEndpoint()
+	failing.ShouldFail = true
+
+	requests := []GetRequest{}
+	for i := 0; i < 10; i++ {
+		requests = append(requests, GetRequest{Endpoint: NewEndpoint(), Model: &struct{}{}})
+	}
+	requests = append(requests, GetRequest{Endpoint: failing, Model: &struct{}{}})
+
+	errs := c.GetConcurrently(context.Background(), 3, requests)
+
+	if len(errs) != len(requests) {
+		t.Fatalf("Expected %d errors, got %d", len(requests), len(errs))
+	}
+
+	for i, err := range errs[:10] {
+		if err != nil {
+			t.Errorf("Expected request %d to pass but received error: %v", i, err)
+		}
+	}
+
+	if errs[10] == nil {
+		t.Error("Expected an error due to endpointer errors but did not receive one.")
+	}
+
+	if len(c.GetResponseCodes()) != 10 {
+		t.Errorf("Expected 10 response codes, got %d", len(c.GetResponseCodes()))
+	}
+}
+
+func TestGetConcurrentlyRespectsContext(t *testing.T) {
+	t.Parallel()
+
+	c := &APIClient{Client: &ConcurrentMockAPICaller{}, BaseURL: "somebaseurl", API: TestAPI}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	errs := c.GetConcurrently(ctx, 2, []GetRequest{{Endpoint: NewEndpoint(), Model: &struct{}{}}})
+
+	if errs[0] != context.Canceled {
+		t.Errorf("Expected context error, got %v", errs[0])
+	}
+}
+
+func TestCanStreamGetResponse(t *testing.T) {
+	t.Parallel()
+
+	table := map[string]string{
+		"From data envelope": `{"count":3,"data":[{"id":1},{"id":2},{"id":3}],"other":{}}`,
+		"From plain array":   `[{"id":1},{"id":2},{"id":3}]`,
+	}
+
+	for name, body := range table {
+		body := body
+		t.Run(
+			name,
+			func(t *testing.T) {
+				caller := &MockAPICaller{}
+				caller.Response = createCallerResponse(http.StatusOK, body)
+
+				c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI}
+
+				ids := []int{}
+				err := c.GetStream(NewEndpoint(), func(record json.RawMessage) error {
+					r := struct {
+						ID int `json:"id"`
+					}{}
+					if err := json.Unmarshal(record, &r); err != nil {
+						return err
+					}
+					ids = append(ids, r.ID)
+					return nil
+				})
+
+				if err != nil {
+					t.Error("Expected GetStream to pass but received error.", err)
+				}
+
+				if !reflect.DeepEqual(ids, []int{1, 2, 3}) {
+					t.Errorf("Streamed records did not match expected. Got %v", ids)
+				}
+			},
+		)
+	}
+}
+
+func TestGetStreamStopsOnCallbackError(t *testing.T) {
+	t.Parallel()
+
+	caller := &MockAPICaller{}
+	caller.Response = createCallerResponse(http.StatusOK, `{"data":[{"id":1},{"id":2}]}`)
+
+	c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI}
+
+	calls := 0
+	callbackErr := errors.New("stop")
+	err := c.GetStream(NewEndpoint(), func(record json.RawMessage) error {
+		calls++
+		return callbackErr
+	})
+
+	if err != callbackErr {
+		t.Errorf("Expected the callback error to be returned, got %v", err)
+	}
+
+	if calls != 1 {
+		t.Errorf("Expected callback to run exactly 1 times, but ran %d times.", calls)
+	}
+}
+
 func TestGetReturnsErrorIfEndpointerReturnsAnError(t *testing.T) {
 	t.Parallel()
 
@@ -318,6 +756,184 @@ func TestGetReturnsErrorIfEndpointerReturnsAnError(t *testing.T) {
 	}
 }
 
+func TestCanDownload(t *testing.T) {
+	t.Parallel()
+
+	t.Run(
+		"If status is ok",
+		func(t *testing.T) {
+			caller := &MockAPICaller{}
+			content := "%PDF-1.4 some binary content"
+			caller.Response = createCallerResponse(http.StatusOK, content)
+			caller.Response.Header = http.Header{"Content-Type": []string{"application/pdf"}}
+
+			c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI}
+
+			b := &bytes.Buffer{}
+			info, err := c.Download(NewEndpoint(), b)
+
+			if err != nil {
+				t.Error("Expected Download to pass but received error.", err)
+			}
+
+			if b.String() != content {
+				t.Errorf("Unexpected content. Expected %s, got %s", content, b.String())
+			}
+
+			if info.ContentType != "application/pdf" {
+				t.Errorf("Unexpected content type. Got %s", info.ContentType)
+			}
+
+			if info.ContentLength != int64(len(content)) {
+				t.Errorf("Unexpected content length. Expected %d, got %d", len(content), info.ContentLength)
+			}
+
+			sum := sha256.Sum256([]byte(content))
+			if info.Checksum != hex.EncodeToString(sum[:]) {
+				t.Errorf("Unexpected checksum. Got %s", info.Checksum)
+			}
+		},
+	)
+
+	t.Run(
+		"If status is not ok",
+		func(t *testing.T) {
+			caller := &MockAPICaller{}
+			caller.Response = createCallerResponse(http.StatusNotFound, "not found")
+
+			c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI}
+
+			b := &bytes.Buffer{}
+			_, err := c.Download(NewEndpoint(), b)
+
+			if err == nil {
+				t.Error("Expected an error due to status not ok but did not receive one.")
+			}
+
+			if b.Len() != 0 {
+				t.Error("Expected nothing to be written to the writer.")
+			}
+		},
+	)
+}
+
+func TestCanGetIntoWriter(t *testing.T) {
+	t.Parallel()
+
+	t.Run(
+		"If status is ok",
+		func(t *testing.T) {
+			caller := &MockAPICaller{}
+			caller.T = t
+			content := "id,name\n1,export"
+			caller.Response = createCallerResponse(http.StatusOK, content)
+			caller.CallTestCallback = func(t *testing.T, r *http.Request) {
+				if r.Header.Get("Accept") != "text/csv" {
+					t.Errorf("Expected Accept header to be set. Got %q", r.Header.Get("Accept"))
+				}
+			}
+
+			c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI}
+
+			b := &bytes.Buffer{}
+			n, err := c.GetIntoWriter(NewEndpoint(), b, WithHeader("Accept", "text/csv"))
+
+			if err != nil {
+				t.Error("Expected GetIntoWriter to pass but received error.", err)
+			}
+
+			if b.String() != content || n != int64(len(content)) {
+				t.Errorf("Unexpected content. Expected %s, got %s (%d bytes)", content, b.String(), n)
+			}
+		},
+	)
+
+	t.Run(
+		"If status is not ok",
+		func(t *testing.T) {
+			caller := &MockAPICaller{}
+			caller.Response = createCallerResponse(http.StatusNotFound, "not found")
+
+			c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI}
+
+			b := &bytes.Buffer{}
+			_, err := c.GetIntoWriter(NewEndpoint(), b)
+
+			if !errors.Is(err, ErrNotFound) {
+				t.Errorf("Expected a not found error, got %v", err)
+			}
+
+			if b.Len() != 0 {
+				t.Error("Expected nothing to be written to the writer.")
+			}
+		},
+	)
+}
+
+type HeaderModel struct {
+	Name     string `json:"name"`
+	Location string `json:"-"`
+}
+
+func (m *HeaderModel) SetResponseHeaders(h http.Header) {
+	m.Location = h.Get("Location")
+}
+
+func TestResultsReceiveResponseHeaders(t *testing.T) {
+	t.Parallel()
+
+	caller := &MockAPICaller{}
+	caller.Response = createCallerResponse(http.StatusOK, `{"name":"created"}`)
+	caller.Response.Header = http.Header{"Location": {"/books/1"}}
+
+	c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI}
+
+	result := &HeaderModel{}
+	if err := c.Post(NewEndpoint(), &HeaderModel{Name: "new"}, result); err != nil {
+		t.Error("Received an error but was not expecting to.", err)
+	}
+
+	if result.Name != "created" || result.Location != "/books/1" {
+		t.Errorf("Expected body and headers to be set. Got %+v", result)
+	}
+}
+
+func TestCanPerformPOSTRequestWithReaderPayload(t *testing.T) {
+	t.Parallel()
+
+	caller := &MockAPICaller{}
+	caller.T = t
+	caller.Response = createCallerResponse(http.StatusOK, `{"name":"newTestName"}`)
+	caller.CallTestCallback = func(t *testing.T, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/xml" {
+			t.Errorf("Unexpected Content-Type. Got %s", r.Header.Get("Content-Type"))
+		}
+
+		b, _ := ioutil.ReadAll(r.Body)
+		if string(b) != "<name>test</name>" {
+			t.Errorf("Expected body to be sent as is. Got %s", b)
+		}
+	}
+
+	c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI}
+
+	result := &struct {
+		Name string `json:"name"`
+	}{}
+
+	err := c.Post(NewEndpoint(), strings.NewReader("<name>test</name>"), result, func(h *http.Header) {
+		h.Set("Content-Type", "application/xml")
+	})
+
+	if err != nil {
+		t.Error("Received an error but was not expecting to.", err)
+	}
+
+	if result.Name != "newTestName" {
+		t.Error("Struct did not have expected value.")
+	}
+}
+
 func TestCanPerformPOSTRequest(t *testing.T) {
 	t.Parallel()
 	caller := &MockAPICaller{}
@@ -337,7 +953,7 @@ func TestCanPerformPOSTRequest(t *testing.T) {
 
 		ic := i
 
-		json.Unmarshal(b, ic)
+		json.Unmarshal(b, &ic)
 
 		if ic.Name != i.Name {
 			t.Error("Request body did not match expected.")
@@ -380,54 +996,153 @@ func TestPostReturnsErrorIfEndpointerReturnsAnError(t *testing.T) {
 	ep.ShouldFail = true
 	err := c.Post(ep, &i, j)
 
-	if err == nil {
-		t.Error("Expected an error due to endpointer errors but did not receive one.")
+	if err == nil {
+		t.Error("Expected an error due to endpointer errors but did not receive one.")
+	}
+}
+
+func TestCanPerformPUTRequest(t *testing.T) {
+	t.Parallel()
+	caller := &MockAPICaller{}
+	caller.T = t
+
+	i := struct {
+		Name string `json:"name"`
+	}{Name: "test"}
+	j := &i
+
+	caller.CallTestCallback = func(t *testing.T, r *http.Request) {
+		defer r.Body.Close()
+		b, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Error("Got error was not expecting one.")
+		}
+
+		ic := i
+
+		json.Unmarshal(b, &ic)
+
+		if ic.Name != i.Name {
+			t.Error("Request body did not match expected.")
+		}
+	}
+
+	baseurl := "somebaseurl"
+	caller.Response = createCallerResponse(http.StatusOK, `{"name":"newTestName"}`)
+
+	c := &APIClient{Client: caller, BaseURL: baseurl, API: TestAPI}
+
+	err := c.Put(NewEndpoint(), &i, j)
+
+	if err != nil {
+		t.Error("Received an error but was not expecting to.")
+	}
+
+	if i.Name != "newTestName" {
+		t.Error("Struct did not have expected value.")
+	}
+
+	if c.GetLastResponseCode() != http.StatusOK {
+		t.Errorf("Unexpected response code. Expected %d, got %d", http.StatusOK, c.GetLastResponseCode())
+	}
+}
+
+func TestCanPerformMultipartPOSTRequest(t *testing.T) {
+	t.Parallel()
+	caller := &MockAPICaller{}
+	caller.T = t
+
+	caller.CallTestCallback = func(t *testing.T, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("Unexpected method. Expected %s, got %s", http.MethodPost, r.Method)
+		}
+
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatal("Could not parse multipart request.", err)
+		}
+
+		if r.FormValue("title") != "Some title" {
+			t.Errorf("Unexpected form field. Got %s", r.FormValue("title"))
+		}
+
+		f, fh, err := r.FormFile("cover")
+		if err != nil {
+			t.Fatal("Could not get file from request.", err)
+		}
+		defer f.Close()
+
+		if fh.Filename != "cover" {
+			t.Errorf("Unexpected file name. Got %s", fh.Filename)
+		}
+
+		b, _ := ioutil.ReadAll(f)
+		if string(b) != "imagedata" {
+			t.Errorf("Unexpected file contents. Got %s", b)
+		}
+	}
+	caller.Response = createCallerResponse(http.StatusOK, `{"name":"newTestName"}`)
+
+	c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI}
+
+	result := struct {
+		Name string `json:"name"`
+	}{}
+	err := c.PostMultipart(
+		NewEndpoint(),
+		map[string]string{"title": "Some title"},
+		map[string]io.Reader{"cover": bytes.NewBufferString("imagedata")},
+		&result,
+	)
+
+	if err != nil {
+		t.Error("Received an error but was not expecting to.", err)
+	}
+
+	if result.Name != "newTestName" {
+		t.Error("Struct did not have expected value.")
 	}
 }
 
-func TestCanPerformPUTRequest(t *testing.T) {
+func TestMultipartPOSTRequestAppliesOptionsAndValidation(t *testing.T) {
 	t.Parallel()
 	caller := &MockAPICaller{}
 	caller.T = t
+	caller.Response = createCallerResponse(http.StatusOK, `{}`)
 
-	i := struct {
-		Name string `json:"name"`
-	}{Name: "test"}
-	j := &i
-
+	requests := 0
 	caller.CallTestCallback = func(t *testing.T, r *http.Request) {
-		defer r.Body.Close()
-		b, err := ioutil.ReadAll(r.Body)
-		if err != nil {
-			t.Error("Got error was not expecting one.")
-		}
-
-		ic := i
-
-		json.Unmarshal(b, ic)
-
-		if ic.Name != i.Name {
-			t.Error("Request body did not match expected.")
+		requests++
+		if r.Header.Get("X-Some") != "value" || r.URL.Query().Get("some") != "param" {
+			t.Errorf("Expected request options to be applied. Got %v, %v", r.Header, r.URL.Query())
 		}
 	}
 
-	baseurl := "somebaseurl"
-	caller.Response = createCallerResponse(http.StatusOK, `{"name":"newTestName"}`)
-
-	c := &APIClient{Client: caller, BaseURL: baseurl, API: TestAPI}
-
-	err := c.Put(NewEndpoint(), &i, j)
+	c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI}
+	files := map[string]io.Reader{"cover": bytes.NewBufferString("imagedata")}
 
+	err := c.PostMultipart(NewEndpoint(), nil, files, &struct{}{},
+		WithHeader("X-Some", "value"),
+		WithQuery(func(q url.Values) { q.Set("some", "param") }),
+	)
 	if err != nil {
-		t.Error("Received an error but was not expecting to.")
+		t.Error("Received an error but was not expecting to.", err)
 	}
 
-	if i.Name != "newTestName" {
-		t.Error("Struct did not have expected value.")
+	c.PayloadValidators = []PayloadValidator{func(payload interface{}) error {
+		return errors.New("invalid fields")
+	}}
+	var payloadErr *PayloadError
+	if err := c.PostMultipart(NewEndpoint(), map[string]string{"title": ""}, files, &struct{}{}); !errors.As(err, &payloadErr) {
+		t.Errorf("Expected a payload error, got %v", err)
 	}
 
-	if c.GetLastResponseCode() != http.StatusOK {
-		t.Errorf("Unexpected response code. Expected %d, got %d", http.StatusOK, c.GetLastResponseCode())
+	c = &APIClient{Client: caller, BaseURL: ":invalid", API: TestAPI}
+	if err := c.PostMultipart(NewEndpoint(), nil, files, &struct{}{}); err == nil {
+		t.Error("Expected an error for an invalid BaseURL but did not receive one.")
+	}
+
+	if requests != 1 {
+		t.Errorf("Expected exactly 1 request to be sent, got %d", requests)
 	}
 }
 
@@ -571,6 +1286,125 @@ func TestPostPutErrors(t *testing.T) {
 	}
 }
 
+func TestAcceptedStatuses(t *testing.T) {
+	t.Parallel()
+
+	t.Run(
+		"Only status ok is accepted by default",
+		func(t *testing.T) {
+			caller := &MockAPICaller{}
+			caller.Response = createCallerResponse(http.StatusCreated, `{"name":"newTestName"}`)
+
+			c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI}
+
+			i := struct {
+				Name string `json:"name"`
+			}{}
+			err := c.Post(NewEndpoint(), &i, &i)
+
+			if err == nil {
+				t.Error("Expected an error due to status not ok but did not receive one.")
+			}
+		},
+	)
+
+	t.Run(
+		"Accepted statuses are not converted into errors",
+		func(t *testing.T) {
+			caller := &MockAPICaller{}
+			caller.Response = createCallerResponse(http.StatusCreated, `{"name":"newTestName"}`)
+
+			c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI}
+			c.AcceptedStatuses = []int{http.StatusOK, http.StatusCreated, http.StatusNoContent}
+
+			i := struct {
+				Name string `json:"name"`
+			}{}
+			err := c.Post(NewEndpoint(), &i, &i)
+
+			if err != nil {
+				t.Error("Received an error but was not expecting to.", err)
+			}
+
+			if i.Name != "newTestName" {
+				t.Error("Struct did not have expected value.")
+			}
+		},
+	)
+
+	t.Run(
+		"No content responses are not decoded",
+		func(t *testing.T) {
+			caller := &MockAPICaller{}
+			caller.Response = createCallerResponse(http.StatusNoContent, "")
+
+			c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI}
+			c.AcceptedStatuses = []int{http.StatusOK, http.StatusNoContent}
+
+			i := struct{}{}
+			err := c.Delete(NewEndpoint(), &i)
+
+			if err != nil {
+				t.Error("Received an error but was not expecting to.", err)
+			}
+
+			if c.GetLastResponseCode() != http.StatusNoContent {
+				t.Errorf("Unexpected response code. Expected %d, got %d", http.StatusNoContent, c.GetLastResponseCode())
+			}
+		},
+	)
+}
+
+func TestResponseValidationHook(t *testing.T) {
+	t.Parallel()
+
+	validationErr := errors.New("Missing signature")
+	validate := func(resp *http.Response) error {
+		if resp.Header.Get("X-Signature") == "" {
+			return validationErr
+		}
+		return nil
+	}
+
+	t.Run(
+		"Fails call if validation fails",
+		func(t *testing.T) {
+			caller := &MockAPICaller{}
+			caller.Response = createCallerResponse(http.StatusOK, `{"some":"body"}`)
+
+			c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI, ValidateResponse: validate}
+
+			model := &struct {
+				Some string `json:"some"`
+			}{}
+			err := c.Get(NewEndpoint(), model)
+
+			if err != validationErr {
+				t.Errorf("Expected validation error, got %v", err)
+			}
+
+			if model.Some != "" {
+				t.Error("Did not expect response to be decoded.")
+			}
+		},
+	)
+
+	t.Run(
+		"Passes call if validation passes",
+		func(t *testing.T) {
+			caller := &MockAPICaller{}
+			caller.Response = createCallerResponse(http.StatusOK, `{"some":"body"}`)
+			caller.Response.Header = http.Header{"X-Signature": []string{"somesignature"}}
+
+			c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI, ValidateResponse: validate}
+
+			if err := c.Get(NewEndpoint(), &struct{}{}); err != nil {
+				t.Error("Expected Get to pass but received error.", err)
+			}
+		},
+	)
+}
+
 func TestCanMakeResponseError(t *testing.T) {
 	t.Parallel()
 
@@ -648,6 +1482,50 @@ func TestCanMakeResponseError(t *testing.T) {
 	)
 }
 
+func TestMiddlewaresWrapCalls(t *testing.T) {
+	t.Parallel()
+
+	caller := &MockAPICaller{}
+	caller.T = t
+	caller.Response = createCallerResponse(http.StatusOK, `{"some":"body"}`)
+	caller.CallTestCallback = func(t *testing.T, r *http.Request) {
+		if r.Header.Get("X-Injected") != "value" {
+			t.Error("Expected header to be injected by middleware.")
+		}
+	}
+
+	c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI}
+
+	order := []string{}
+	c.Use(
+		func(next CallFunc) CallFunc {
+			return func(r *http.Request) (*http.Response, error) {
+				order = append(order, "first")
+				r.Header.Set("X-Injected", "value")
+				return next(r)
+			}
+		},
+		func(next CallFunc) CallFunc {
+			return func(r *http.Request) (*http.Response, error) {
+				order = append(order, "second")
+				resp, err := next(r)
+				order = append(order, "second done")
+				return resp, err
+			}
+		},
+	)
+
+	model := &struct{}{}
+	if err := c.Get(NewEndpoint(), model); err != nil {
+		t.Error("Expected Get to pass but received error.", err)
+	}
+
+	expected := []string{"first", "second", "second done"}
+	if !reflect.DeepEqual(order, expected) {
+		t.Errorf("Middlewares were not invoked in expected order. Expected %v, got %v", expected, order)
+	}
+}
+
 func TestCanUnsetAuthToken(t *testing.T) {
 	unsetAuthTokenCount := 0
 	caller := &MockAPICaller{
@@ -694,6 +1572,136 @@ func TestResponseErrors(t *testing.T) {
 
 }
 
+func TestCanGetLastResponseInfo(t *testing.T) {
+	t.Parallel()
+
+	caller := &MockAPICaller{}
+	c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI}
+
+	if c.GetLastResponseInfo().StatusCode != 0 {
+		t.Error("Expected empty response info before any call.")
+	}
+
+	caller.Response = createCallerResponse(http.StatusOK, `{}`)
+	caller.Response.Header = http.Header{HEADER_REQUEST_ID: []string{"somerequestid"}}
+
+	if err := c.Get(NewEndpoint(), &struct{}{}); err != nil {
+		t.Error("Expected Get to pass but received error.", err)
+	}
+
+	info := c.GetLastResponseInfo()
+
+	if info.StatusCode != http.StatusOK {
+		t.Errorf("Unexpected response code. Expected %d, got %d", http.StatusOK, info.StatusCode)
+	}
+
+	if info.RequestID != "somerequestid" {
+		t.Errorf("Unexpected request id. Got %s", info.RequestID)
+	}
+
+	if u, _ := c.EndpointURL("someendpoint"); info.RequestURL != u {
+		t.Errorf("Unexpected request URL. Got %s", info.RequestURL)
+	}
+
+	if info.Header.Get(HEADER_REQUEST_ID) != "somerequestid" {
+		t.Error("Expected response headers to be kept but were not.")
+	}
+
+	if info.Latency < 0 {
+		t.Errorf("Unexpected latency %v", info.Latency)
+	}
+}
+
+func TestRequestIDsAreGeneratedAndRecorded(t *testing.T) {
+	t.Parallel()
+
+	sent := []string{}
+	caller := &MockAPICaller{}
+	caller.T = t
+	caller.CallTestCallback = func(t *testing.T, r *http.Request) {
+		sent = append(sent, r.Header.Get(HEADER_REQUEST_ID))
+	}
+
+	c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI}
+
+	caller.Response = createCallerResponse(http.StatusOK, `{}`)
+	c.Get(NewEndpoint(), &struct{}{})
+
+	caller.Response = createCallerResponse(http.StatusBadRequest, "")
+	err := c.Get(NewEndpoint(), &struct{}{})
+
+	if len(sent) != 2 || sent[0] == "" || sent[0] == sent[1] {
+		t.Fatalf("Expected unique request ids to be sent, got %q", sent)
+	}
+
+	uuid := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	if !uuid.MatchString(sent[0]) {
+		t.Errorf("Expected request id to be a UUID, got %s", sent[0])
+	}
+
+	history := c.GetResponseHistory()
+	expected := []ResponseRecord{
+		{StatusCode: http.StatusOK, RequestID: sent[0]},
+		{StatusCode: http.StatusBadRequest, RequestID: sent[1]},
+	}
+	if !reflect.DeepEqual(history, expected) {
+		t.Errorf("Unexpected response history. Expected %v, got %v", expected, history)
+	}
+
+	if c.GetLastRequestID() != sent[1] {
+		t.Errorf("Unexpected last request id. Got %s", c.GetLastRequestID())
+	}
+
+	expectedErr := fmt.Sprintf(`Response not ok. No information given. Code: "400", Request ID: "%s"`, sent[1])
+	if err == nil || err.Error() != expectedErr {
+		t.Errorf(`Error message did not match expected. Got: "%v", Expected "%v"`, err, expectedErr)
+	}
+}
+
+func TestResponseCodeHistoryIsBounded(t *testing.T) {
+	t.Parallel()
+
+	caller := &MockAPICaller{}
+	c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI, ResponseHistorySize: 2}
+
+	codes := []int{
+		http.StatusOK,
+		http.StatusBadRequest,
+		http.StatusUnauthorized,
+	}
+
+	for _, code := range codes {
+		caller.Response = createCallerResponse(code, "")
+		c.StatusCheck()
+	}
+
+	expected := []int{http.StatusBadRequest, http.StatusUnauthorized}
+	if !reflect.DeepEqual(c.GetResponseCodes(), expected) {
+		t.Errorf("Unexpected response codes. Expected %v, got %v", expected, c.GetResponseCodes())
+	}
+}
+
+func TestResponseCodesCanBeTrackedConcurrently(t *testing.T) {
+	t.Parallel()
+
+	c := &APIClient{Client: &ConcurrentMockAPICaller{}, BaseURL: "somebaseurl", API: TestAPI}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.Get(NewEndpoint(), &struct{}{})
+			c.GetLastResponseCode()
+		}()
+	}
+	wg.Wait()
+
+	if len(c.GetResponseCodes()) != 50 {
+		t.Errorf("Expected 50 response codes to be tracked, got %d", len(c.GetResponseCodes()))
+	}
+}
+
 func createCallerResponse(status int, body string) *http.Response {
 	resp := &http.Response{}
 	resp.StatusCode = status
@@ -743,6 +1751,35 @@ func (c *MockAPICaller) UnsetAuthToken() {
 	}
 }
 
+// ConcurrentMockAPICaller returns a new ok response for every call and is safe for concurrent use.
+type ConcurrentMockAPICaller struct {
+	MockAPICaller
+}
+
+func (c *ConcurrentMockAPICaller) Call(r *http.Request) (*http.Response, error) {
+	return createCallerResponse(http.StatusOK, `{}`), nil
+}
+
+func (c *ConcurrentMockAPICaller) CallRaw(r *http.Request) (*http.Response, error) {
+	return c.Call(r)
+}
+
+// ClosableMockAPICaller records if it has been closed.
+type ClosableMockAPICaller struct {
+	MockAPICaller
+	IdleClosed bool
+	Closed     bool
+}
+
+func (c *ClosableMockAPICaller) CloseIdleConnections() {
+	c.IdleClosed = true
+}
+
+func (c *ClosableMockAPICaller) Close() error {
+	c.Closed = true
+	return nil
+}
+
 // Creates new endpoint.
 func NewEndpoint() Endpoint { return Endpoint{1, false} }
 
@@ -843,3 +1880,88 @@ func ExampleAPIClient_SetNewAPIToken() {
 		log.Fatal(err)
 	}
 }
+
+func TestNewAPIClientUsesBaseURLOfClient(t *testing.T) {
+	t.Parallel()
+	cl := client.New(func(c *client.Client) { c.BaseURL = "https://api.publit.test" })
+
+	c, err := NewAPIClient("", "someapi", WithCaller(cl))
+	if err != nil {
+		t.Fatalf("Received an error but did not expect one: %v", err)
+	}
+
+	if c.BaseURL != cl.BaseURL {
+		t.Errorf("Expected base URL %q, got %q", cl.BaseURL, c.BaseURL)
+	}
+}
+
+func TestNewAPIClientWithEnvironment(t *testing.T) {
+	t.Parallel()
+
+	client.RegisterEnvironment("apiclient-test", "https://api.publit.test")
+
+	c, err := NewAPIClient("", "someapi", WithEnvironment("apiclient-test"))
+	if err != nil {
+		t.Fatalf("Received an error but did not expect one: %v", err)
+	}
+
+	if c.BaseURL != "https://api.publit.test" {
+		t.Errorf("Expected base URL of registered environment, got %q", c.BaseURL)
+	}
+
+	c, err = NewAPIClient("", "someapi", WithEnvironment(client.ENVIRONMENT_PRODUCTION))
+	if err != nil || c.BaseURL != client.BASE_URL_PRODUCTION {
+		t.Errorf("Expected base URL of production preset, got %q, %v", c.BaseURL, err)
+	}
+
+	if _, err := NewAPIClient("https://api.publit.test", "someapi", WithEnvironment("moon")); err == nil {
+		t.Error("Expected an error for unknown environment but did not receive one.")
+	}
+
+	cl := client.New(client.WithEnvironment("moon"))
+	if _, err := NewAPIClient("https://api.publit.test", "someapi", WithCaller(cl)); err == nil {
+		t.Error("Expected an error for client with unknown environment but did not receive one.")
+	}
+}
+
+// closeTrackingBody records if it was closed.
+type closeTrackingBody struct {
+	io.Reader
+	closed bool
+}
+
+func (b *closeTrackingBody) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestRawGettersCloseBodyOnCallError(t *testing.T) {
+	t.Parallel()
+
+	calls := map[string]func(c *APIClient) error{
+		"GetStream": func(c *APIClient) error {
+			return c.GetStream(NewEndpoint(), func(record json.RawMessage) error { return nil })
+		},
+		"Download": func(c *APIClient) error {
+			_, err := c.Download(NewEndpoint(), ioutil.Discard)
+			return err
+		},
+		"GetRaw": func(c *APIClient) error {
+			_, _, err := c.GetRaw(NewEndpoint())
+			return err
+		},
+	}
+
+	for name, call := range calls {
+		body := &closeTrackingBody{Reader: strings.NewReader(`{}`)}
+		caller := &MockAPICaller{ReturnErrors: true, Response: &http.Response{StatusCode: http.StatusOK, Body: body}}
+		c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI}
+
+		if err := call(c); err == nil {
+			t.Errorf("%s: Expected an error but did not receive one.", name)
+		}
+		if !body.closed {
+			t.Errorf("%s: Expected the response body to be closed.", name)
+		}
+	}
+}
@@ -0,0 +1,246 @@
+// Copyright 2018 Publit Sweden AB. All rights reserved.
+
+// Package apiclienttest provides test doubles for code built on top of the APIClient, such as the resource SDKs.
+// A MockCaller is set as the APICaller of an APIClient, and answers requests matching its expectations with canned responses.
+// A Server is a fake Publit API on a local http server, for end-to-end tests through the real client.
+package apiclienttest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/publitsweden/APIUtilityGoSDK/APIClient"
+)
+
+// Base URL and API of clients created with NewClient.
+const (
+	TEST_BASE_URL = "https://api.publit.test"
+	TEST_API      = "test"
+)
+
+// TestingT is the subset of testing.TB used by MockCaller.
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+}
+
+// MockCaller is an APICaller answering requests with the canned responses of matching expectations.
+// Expectations are matched in the order they were added, and each expectation answers one request unless Times is used.
+// Requests not matching any expectation fail with an error. MockCaller is safe for concurrent use.
+type MockCaller struct {
+	mu           sync.Mutex
+	expectations []*Expectation
+	requests     []*http.Request
+	unexpected   []string
+	tokenErr     error
+}
+
+// Expectation is an expected request and the response given to it.
+type Expectation struct {
+	method   string
+	endpoint string
+	query    map[string]string
+	times    int
+	calls    int
+	status   int
+	header   http.Header
+	body     []byte
+	err      error
+}
+
+// NewMockCaller creates a new MockCaller without expectations.
+func NewMockCaller() *MockCaller {
+	return &MockCaller{}
+}
+
+// NewClient creates an APIClient calling a new MockCaller, using TEST_BASE_URL and TEST_API.
+func NewClient() (*APIClient.APIClient, *MockCaller) {
+	m := NewMockCaller()
+	return &APIClient.APIClient{Client: m, BaseURL: TEST_BASE_URL, API: TEST_API}, m
+}
+
+// Expect adds an expectation of a request with the http method against the endpoint, as returned by an Endpointer.
+// The expectation responds with 200 OK and an empty body unless configured otherwise.
+func (m *MockCaller) Expect(method, endpoint string) *Expectation {
+	e := &Expectation{
+		method:   method,
+		endpoint: strings.Trim(endpoint, "/"),
+		query:    map[string]string{},
+		times:    1,
+		status:   http.StatusOK,
+		header:   http.Header{},
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.expectations = append(m.expectations, e)
+
+	return e
+}
+
+// WithQuery expects the request to have the query param with the value.
+func (e *Expectation) WithQuery(key, value string) *Expectation {
+	e.query[key] = value
+	return e
+}
+
+// Times sets the amount of requests the expectation answers.
+func (e *Expectation) Times(n int) *Expectation {
+	e.times = n
+	return e
+}
+
+// Respond sets the status and body of the response.
+func (e *Expectation) Respond(status int, body string) *Expectation {
+	e.status = status
+	e.body = []byte(body)
+	return e
+}
+
+// RespondJSON sets the status of the response and the json encoded v as its body.
+// Panics if v can not be encoded.
+func (e *Expectation) RespondJSON(status int, v interface{}) *Expectation {
+	b, err := json.Marshal(v)
+	if err != nil {
+		panic(fmt.Sprintf("apiclienttest: could not encode response: %v", err))
+	}
+
+	e.status = status
+	e.body = b
+	e.header.Set("Content-Type", "application/json")
+	return e
+}
+
+// RespondHeader sets a header of the response.
+func (e *Expectation) RespondHeader(key, value string) *Expectation {
+	e.header.Set(key, value)
+	return e
+}
+
+// ReturnError makes the call fail with err instead of responding, like a transport error.
+func (e *Expectation) ReturnError(err error) *Expectation {
+	e.err = err
+	return e
+}
+
+// matches reports if the request matches the expectation.
+func (e *Expectation) matches(r *http.Request) bool {
+	if e.method != r.Method || !strings.HasSuffix(strings.TrimRight(r.URL.Path, "/"), "/"+e.endpoint) {
+		return false
+	}
+
+	q := r.URL.Query()
+	for k, v := range e.query {
+		if q.Get(k) != v {
+			return false
+		}
+	}
+
+	return true
+}
+
+// response creates the canned response for the request.
+func (e *Expectation) response(r *http.Request) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.status, http.StatusText(e.status)),
+		StatusCode:    e.status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.header.Clone(),
+		Body:          ioutil.NopCloser(bytes.NewReader(e.body)),
+		ContentLength: int64(len(e.body)),
+		Request:       r,
+	}
+}
+
+// Call answers the request with the first matching expectation.
+func (m *MockCaller) Call(r *http.Request) (*http.Response, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.requests = append(m.requests, r)
+
+	for _, e := range m.expectations {
+		if e.calls >= e.times || !e.matches(r) {
+			continue
+		}
+
+		e.calls++
+		if e.err != nil {
+			return nil, e.err
+		}
+		return e.response(r), nil
+	}
+
+	msg := fmt.Sprintf("%s %s", r.Method, r.URL.String())
+	m.unexpected = append(m.unexpected, msg)
+	return nil, fmt.Errorf("apiclienttest: unexpected request %s", msg)
+}
+
+// CallRaw answers the request like Call.
+func (m *MockCaller) CallRaw(r *http.Request) (*http.Response, error) {
+	return m.Call(r)
+}
+
+// SetNewAPIToken succeeds unless an error is set with SetTokenError.
+func (m *MockCaller) SetNewAPIToken(r *http.Request) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.tokenErr
+}
+
+// SetTokenError sets the error returned from SetNewAPIToken.
+func (m *MockCaller) SetTokenError(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tokenErr = err
+}
+
+// UnsetAuthToken does nothing.
+func (m *MockCaller) UnsetAuthToken() {}
+
+// Requests returns the requests received so far, in order.
+func (m *MockCaller) Requests() []*http.Request {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	requests := make([]*http.Request, len(m.requests))
+	copy(requests, m.requests)
+	return requests
+}
+
+// Verify returns an error describing unmet expectations and unexpected requests, or nil if all expectations were met.
+func (m *MockCaller) Verify() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var problems []string
+	for _, e := range m.expectations {
+		if e.calls < e.times {
+			problems = append(problems, fmt.Sprintf("expected %s %s %d time(s), got %d", e.method, e.endpoint, e.times, e.calls))
+		}
+	}
+	for _, v := range m.unexpected {
+		problems = append(problems, "unexpected request "+v)
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	return errors.New("apiclienttest: " + strings.Join(problems, "; "))
+}
+
+// AssertExpectations fails the test if Verify returns an error.
+func (m *MockCaller) AssertExpectations(t TestingT) {
+	t.Helper()
+	if err := m.Verify(); err != nil {
+		t.Errorf("%v", err)
+	}
+}
@@ -0,0 +1,80 @@
+package apiclienttest_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/publitsweden/APIUtilityGoSDK/APIClient"
+	"github.com/publitsweden/APIUtilityGoSDK/APIClient/apiclienttest"
+	"github.com/publitsweden/APIUtilityGoSDK/common"
+	"github.com/publitsweden/APIUtilityGoSDK/endpoint"
+)
+
+var countries = &endpoint.Resource{
+	Endpoint:  1,
+	Endpoints: map[endpoint.Endpoint]string{1: "countries"},
+}
+
+type Country struct {
+	ISO string `json:"iso3"`
+}
+
+func TestMockCallerAnswersExpectedRequests(t *testing.T) {
+	t.Parallel()
+
+	c, m := apiclienttest.NewClient()
+	m.Expect(http.MethodGet, "countries").
+		WithQuery(common.QUERY_KEY_LIMIT, "0,1").
+		RespondJSON(http.StatusOK, []Country{{ISO: "SWE"}})
+
+	var result []Country
+	if err := c.Get(countries, &result, common.QueryLimit(1, 0)); err != nil {
+		t.Fatal("Received an error but was not expecting to.", err)
+	}
+
+	if len(result) != 1 || result[0].ISO != "SWE" {
+		t.Errorf("Unexpected result. Got %+v", result)
+	}
+
+	m.AssertExpectations(t)
+
+	if len(m.Requests()) != 1 {
+		t.Errorf("Expected 1 recorded request, got %d", len(m.Requests()))
+	}
+}
+
+func TestMockCallerReportsUnmetAndUnexpectedRequests(t *testing.T) {
+	t.Parallel()
+
+	c, m := apiclienttest.NewClient()
+	m.Expect(http.MethodPost, "countries").Respond(http.StatusCreated, `{}`)
+
+	if err := c.Delete(countries, nil); err == nil {
+		t.Error("Expected an error for an unexpected request but did not receive one.")
+	}
+
+	if err := m.Verify(); err == nil {
+		t.Error("Expected verification to fail but it did not.")
+	}
+}
+
+func TestMockCallerCanFailCalls(t *testing.T) {
+	t.Parallel()
+
+	someErr := errors.New("connection reset")
+
+	c, m := apiclienttest.NewClient()
+	m.Expect(http.MethodGet, "countries").ReturnError(someErr)
+	m.Expect(http.MethodGet, "countries").Respond(http.StatusNotFound, "")
+
+	if err := c.Get(countries, nil); !errors.Is(err, someErr) {
+		t.Errorf("Expected the given error, got %v", err)
+	}
+
+	if err := c.Get(countries, nil); !errors.Is(err, APIClient.ErrNotFound) {
+		t.Errorf("Expected a not found error, got %v", err)
+	}
+
+	m.AssertExpectations(t)
+}
@@ -0,0 +1,355 @@
+// Copyright 2018 Publit Sweden AB. All rights reserved.
+
+package apiclienttest
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/publitsweden/APIUtilityGoSDK/client"
+)
+
+// RecorderMode decides if a Recorder records or replays interactions.
+type RecorderMode int
+
+// RecorderMode enum constants.
+const (
+	// MODE_RECORD performs requests and records them.
+	MODE_RECORD RecorderMode = 1 + iota
+	// MODE_REPLAY answers requests from the fixture file without performing them.
+	MODE_REPLAY
+	// MODE_AUTO replays if the fixture file exists and records otherwise.
+	MODE_AUTO
+)
+
+// Value replacing scrubbed header values and body fields in fixture files.
+const SCRUBBED = "[SCRUBBED]"
+
+// BODY_ENCODING_BASE64 is the body encoding of recorded bodies that are not valid UTF-8, e.g. compressed or binary bodies.
+const BODY_ENCODING_BASE64 = "base64"
+
+// ErrNoInteraction is returned by a replaying Recorder when no recorded interaction matches the request.
+var ErrNoInteraction = errors.New("apiclienttest: no recorded interaction matches request")
+
+// Interaction is a recorded request and its response.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is a recorded request.
+// BodyEncoding is empty, or BODY_ENCODING_BASE64 if Body is base64 encoded.
+type RecordedRequest struct {
+	Method       string      `json:"method"`
+	URL          string      `json:"url"`
+	Header       http.Header `json:"header"`
+	Body         string      `json:"body"`
+	BodyEncoding string      `json:"body_encoding,omitempty"`
+}
+
+// RecordedResponse is a recorded response.
+// BodyEncoding is empty, or BODY_ENCODING_BASE64 if Body is base64 encoded.
+type RecordedResponse struct {
+	StatusCode   int         `json:"status_code"`
+	Header       http.Header `json:"header"`
+	Body         string      `json:"body"`
+	BodyEncoding string      `json:"body_encoding,omitempty"`
+}
+
+// Recorder records interactions with the real API to a fixture file and replays them deterministically in tests.
+// Use it as client.Client.HTTPClient, or as Transport of a http.Client.
+// Recorded requests and responses are scrubbed of credentials before saved, see ScrubHeaders, ScrubFields and Scrub.
+// Bodies that are not valid UTF-8 are saved base64 encoded, see BODY_ENCODING_BASE64.
+// Replayed interactions are matched on method and URL, in recorded order.
+type Recorder struct {
+	// ScrubHeaders are the request and response headers whose values are replaced with SCRUBBED.
+	// Defaults to Authorization, token, Cookie and Set-Cookie.
+	ScrubHeaders []string
+	// ScrubFields are the form fields and JSON object keys of bodies whose values are replaced with SCRUBBED.
+	// Names are case insensitive. Defaults to password, token, access_token, refresh_token and client_secret.
+	ScrubFields []string
+	// Scrub is an optional hook modifying interactions before they are saved, e.g. for removing personal data from bodies.
+	Scrub func(i *Interaction)
+
+	path string
+	mode RecorderMode
+	next client.Doer
+
+	mu           sync.Mutex
+	interactions []*Interaction
+	replayed     map[int]bool
+}
+
+// NewRecorder creates a Recorder using the fixture file at path.
+// next performs the real requests when recording and defaults to http.DefaultClient.
+// In replay mode the fixture file is loaded and must exist.
+func NewRecorder(path string, mode RecorderMode, next client.Doer) (*Recorder, error) {
+	if next == nil {
+		next = http.DefaultClient
+	}
+
+	if mode == MODE_AUTO {
+		mode = MODE_RECORD
+		if _, err := os.Stat(path); err == nil {
+			mode = MODE_REPLAY
+		}
+	}
+
+	r := &Recorder{
+		ScrubHeaders: []string{"Authorization", "token", "Cookie", "Set-Cookie"},
+		ScrubFields:  []string{"password", "token", "access_token", "refresh_token", "client_secret"},
+		path:         path,
+		mode:         mode,
+		next:         next,
+		replayed:     map[int]bool{},
+	}
+
+	if mode == MODE_REPLAY {
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(b, &r.interactions); err != nil {
+			return nil, fmt.Errorf("apiclienttest: could not parse fixture file %s: %v", path, err)
+		}
+	}
+
+	return r, nil
+}
+
+// Mode returns the mode of the recorder, with MODE_AUTO resolved.
+func (r *Recorder) Mode() RecorderMode {
+	return r.mode
+}
+
+// Do records or replays the request.
+func (r *Recorder) Do(req *http.Request) (*http.Response, error) {
+	if r.mode == MODE_REPLAY {
+		return r.replay(req)
+	}
+	return r.record(req)
+}
+
+// RoundTrip records or replays the request, for using the Recorder as Transport of a http.Client.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	return r.Do(req)
+}
+
+// Save writes the recorded interactions to the fixture file. Does nothing when replaying.
+func (r *Recorder) Save() error {
+	if r.mode == MODE_REPLAY {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	b, err := json.MarshalIndent(r.interactions, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(r.path, b, 0644)
+}
+
+func (r *Recorder) record(req *http.Request) (*http.Response, error) {
+	i := &Interaction{
+		Request: RecordedRequest{
+			Method: req.Method,
+			URL:    req.URL.String(),
+			Header: req.Header.Clone(),
+		},
+	}
+
+	if req.Body != nil {
+		b, err := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(b))
+		i.Request.Body, i.Request.BodyEncoding = encodeBody(b)
+	}
+
+	resp, err := r.next.Do(req)
+	if err != nil {
+		return resp, err
+	}
+
+	b, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = ioutil.NopCloser(bytes.NewReader(b))
+	if err != nil {
+		return resp, err
+	}
+
+	i.Response = RecordedResponse{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header.Clone(),
+	}
+	i.Response.Body, i.Response.BodyEncoding = encodeBody(b)
+
+	r.scrub(i)
+
+	r.mu.Lock()
+	r.interactions = append(r.interactions, i)
+	r.mu.Unlock()
+
+	return resp, nil
+}
+
+// scrub removes credentials from the interaction.
+func (r *Recorder) scrub(i *Interaction) {
+	for _, h := range r.ScrubHeaders {
+		for _, header := range []http.Header{i.Request.Header, i.Response.Header} {
+			if header.Get(h) != "" {
+				header.Set(h, SCRUBBED)
+			}
+		}
+	}
+
+	if i.Request.BodyEncoding == "" {
+		i.Request.Body = r.scrubBody(i.Request.Header.Get("Content-Type"), i.Request.Body)
+	}
+	if i.Response.BodyEncoding == "" {
+		i.Response.Body = r.scrubBody(i.Response.Header.Get("Content-Type"), i.Response.Body)
+	}
+
+	if r.Scrub != nil {
+		r.Scrub(i)
+	}
+}
+
+// scrubBody replaces the values of ScrubFields in form and JSON bodies. Other bodies are returned as is.
+func (r *Recorder) scrubBody(contentType, body string) string {
+	if body == "" || len(r.ScrubFields) == 0 {
+		return body
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mediaType == "application/x-www-form-urlencoded":
+		form, err := url.ParseQuery(body)
+		if err != nil {
+			return body
+		}
+		scrubbed := false
+		for k := range form {
+			if r.isScrubField(k) {
+				form[k] = []string{SCRUBBED}
+				scrubbed = true
+			}
+		}
+		if !scrubbed {
+			return body
+		}
+		return form.Encode()
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		var v interface{}
+		if err := json.Unmarshal([]byte(body), &v); err != nil {
+			return body
+		}
+		if !r.scrubJSON(v) {
+			return body
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			return body
+		}
+		return string(b)
+	}
+
+	return body
+}
+
+// scrubJSON replaces the values of ScrubFields in the decoded JSON value, recursively. Reports if any were replaced.
+func (r *Recorder) scrubJSON(v interface{}) bool {
+	scrubbed := false
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, field := range val {
+			if r.isScrubField(k) {
+				val[k] = SCRUBBED
+				scrubbed = true
+				continue
+			}
+			scrubbed = r.scrubJSON(field) || scrubbed
+		}
+	case []interface{}:
+		for _, item := range val {
+			scrubbed = r.scrubJSON(item) || scrubbed
+		}
+	}
+	return scrubbed
+}
+
+func (r *Recorder) isScrubField(name string) bool {
+	for _, f := range r.ScrubFields {
+		if strings.EqualFold(f, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// encodeBody returns the body as a string, base64 encoded with BODY_ENCODING_BASE64 if it is not valid UTF-8.
+func encodeBody(b []byte) (string, string) {
+	if utf8.Valid(b) {
+		return string(b), ""
+	}
+	return base64.StdEncoding.EncodeToString(b), BODY_ENCODING_BASE64
+}
+
+// decodeBody reverses encodeBody.
+func decodeBody(body, encoding string) ([]byte, error) {
+	switch encoding {
+	case "":
+		return []byte(body), nil
+	case BODY_ENCODING_BASE64:
+		return base64.StdEncoding.DecodeString(body)
+	}
+	return nil, fmt.Errorf("apiclienttest: unknown body encoding %q", encoding)
+}
+
+func (r *Recorder) replay(req *http.Request) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	url := req.URL.String()
+	for n, i := range r.interactions {
+		if r.replayed[n] || i.Request.Method != req.Method || i.Request.URL != url {
+			continue
+		}
+
+		body, err := decodeBody(i.Response.Body, i.Response.BodyEncoding)
+		if err != nil {
+			return nil, err
+		}
+
+		r.replayed[n] = true
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", i.Response.StatusCode, http.StatusText(i.Response.StatusCode)),
+			StatusCode:    i.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        i.Response.Header.Clone(),
+			Body:          ioutil.NopCloser(bytes.NewReader(body)),
+			ContentLength: int64(len(body)),
+			Request:       req,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s %s", ErrNoInteraction, req.Method, url)
+}
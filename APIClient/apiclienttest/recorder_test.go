@@ -0,0 +1,146 @@
+package apiclienttest_test
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/publitsweden/APIUtilityGoSDK/APIClient"
+	"github.com/publitsweden/APIUtilityGoSDK/APIClient/apiclienttest"
+	"github.com/publitsweden/APIUtilityGoSDK/client"
+)
+
+func TestRecorderRecordsAndReplays(t *testing.T) {
+	t.Parallel()
+
+	fixture := filepath.Join(t.TempDir(), "books.json")
+
+	s := apiclienttest.NewServer()
+	s.Seed("books", Book{Title: "Recorded"})
+
+	rec, err := apiclienttest.NewRecorder(fixture, apiclienttest.MODE_AUTO, s.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.Mode() != apiclienttest.MODE_RECORD {
+		t.Fatal("Expected recorder to record when no fixture exists.")
+	}
+
+	c := &APIClient.APIClient{
+		Client: client.New(func(c *client.Client) {
+			c.User = s.User
+			c.Password = s.Password
+			c.HTTPClient = rec
+		}),
+		BaseURL: s.URL,
+		API:     "publishing",
+	}
+
+	book := &Book{}
+	if err := c.Get(bookEndpoint("books/%v", 1), book); err != nil {
+		t.Fatal("Received an error but was not expecting to.", err)
+	}
+	if err := rec.Save(); err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+
+	b, _ := ioutil.ReadFile(fixture)
+	if strings.Contains(string(b), "Basic ") || !strings.Contains(string(b), apiclienttest.SCRUBBED) {
+		t.Errorf("Expected credentials to be scrubbed from fixture. Got %s", b)
+	}
+
+	rec, err = apiclienttest.NewRecorder(fixture, apiclienttest.MODE_AUTO, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.Mode() != apiclienttest.MODE_REPLAY {
+		t.Fatal("Expected recorder to replay when fixture exists.")
+	}
+
+	c.Client = client.New(func(c *client.Client) {
+		c.HTTPClient = rec
+	})
+
+	replayed := &Book{}
+	if err := c.Get(bookEndpoint("books/%v", 1), replayed); err != nil {
+		t.Fatal("Received an error but was not expecting to.", err)
+	}
+	if replayed.Title != "Recorded" {
+		t.Errorf("Unexpected replayed book. Got %+v", replayed)
+	}
+
+	if err := c.Get(bookEndpoint("books/%v", 1), replayed); !errors.Is(err, apiclienttest.ErrNoInteraction) {
+		t.Errorf("Expected no interaction error once replayed, got %v", err)
+	}
+}
+
+type doerFunc func(r *http.Request) (*http.Response, error)
+
+func (f doerFunc) Do(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestRecorderEncodesBinaryBodiesAndScrubsCredentialFields(t *testing.T) {
+	t.Parallel()
+
+	fixture := filepath.Join(t.TempDir(), "token.json")
+	binary := []byte{0x1f, 0x8b, 0x08, 0x00, 0xff, 0xfe}
+
+	next := doerFunc(func(r *http.Request) (*http.Response, error) {
+		h := http.Header{"Content-Type": {"application/json"}}
+		body := `{"data":{"Token":"secret-token","name":"someuser"}}`
+		if r.URL.Path == "/cover" {
+			h = http.Header{"Content-Type": {"image/png"}}
+			body = string(binary)
+		}
+		return &http.Response{StatusCode: http.StatusOK, Header: h, Body: ioutil.NopCloser(strings.NewReader(body))}, nil
+	})
+
+	rec, err := apiclienttest.NewRecorder(fixture, apiclienttest.MODE_RECORD, next)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	form := url.Values{"user": {"someuser"}, "password": {"secret-password"}}
+	req, _ := http.NewRequest(http.MethodPost, "https://api.publit.test/token", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if _, err := rec.Do(req); err != nil {
+		t.Fatal(err)
+	}
+
+	req, _ = http.NewRequest(http.MethodGet, "https://api.publit.test/cover", nil)
+	if _, err := rec.Do(req); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := rec.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	b, _ := ioutil.ReadFile(fixture)
+	if strings.Contains(string(b), "secret-") || !strings.Contains(string(b), "someuser") {
+		t.Errorf("Expected credential fields to be scrubbed from fixture. Got %s", b)
+	}
+	if !strings.Contains(string(b), `"body_encoding": "base64"`) {
+		t.Errorf("Expected binary body to be base64 encoded. Got %s", b)
+	}
+
+	rec, err = apiclienttest.NewRecorder(fixture, apiclienttest.MODE_REPLAY, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, _ = http.NewRequest(http.MethodGet, "https://api.publit.test/cover", nil)
+	resp, err := rec.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayed, _ := ioutil.ReadAll(resp.Body)
+	if !bytes.Equal(replayed, binary) {
+		t.Errorf("Expected binary body to be replayed as recorded. Got %v", replayed)
+	}
+}
@@ -0,0 +1,402 @@
+// Copyright 2018 Publit Sweden AB. All rights reserved.
+
+package apiclienttest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/publitsweden/APIUtilityGoSDK/APIClient"
+	"github.com/publitsweden/APIUtilityGoSDK/client"
+	"github.com/publitsweden/APIUtilityGoSDK/common"
+)
+
+// Credentials accepted by a Server created with NewServer.
+const (
+	TEST_USER     = "testuser"
+	TEST_PASSWORD = "testpassword"
+	TEST_TOKEN    = "testtoken"
+)
+
+// Server is a fake Publit API for end-to-end tests of resource SDKs, running on a local httptest.Server.
+// It serves the status check and token endpoints and generic CRUD for any resource, storing records in memory:
+//
+//	GET    /{api}/{version}/{resource}       index, with limit, order_by/order_dir and attribute filters
+//	GET    /{api}/{version}/{resource}/{id}  show
+//	POST   /{api}/{version}/{resource}       create, assigning an incremental id
+//	PUT    /{api}/{version}/{resource}/{id}  update, merging the given attributes
+//	DELETE /{api}/{version}/{resource}/{id}  delete
+//
+// Requests must be authenticated with the User and Password of the server, or with the token given by the token endpoint.
+// Attribute filters support the operators of common.Operator, combined with AND. Other reserved query params are ignored.
+type Server struct {
+	*httptest.Server
+
+	// User and Password are the credentials accepted by the server. Default to TEST_USER and TEST_PASSWORD.
+	User     string
+	Password string
+	// Token is the token given by the token endpoint. Defaults to TEST_TOKEN.
+	Token string
+
+	mu        sync.Mutex
+	resources map[string]*fakeResource
+}
+
+// fakeResource holds the records of a resource.
+type fakeResource struct {
+	nextID  int
+	records map[int]map[string]interface{}
+}
+
+// NewServer starts a new Server. The server must be closed with Close when done.
+func NewServer() *Server {
+	s := &Server{
+		User:      TEST_USER,
+		Password:  TEST_PASSWORD,
+		Token:     TEST_TOKEN,
+		resources: map[string]*fakeResource{},
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// NewAPIClient creates an APIClient for the api authenticated against the server.
+func (s *Server) NewAPIClient(api string) *APIClient.APIClient {
+	c := client.New(func(c *client.Client) {
+		c.User = s.User
+		c.Password = s.Password
+		c.HTTPClient = s.Client()
+	})
+
+	return &APIClient.APIClient{Client: c, BaseURL: s.URL, API: api}
+}
+
+// Seed adds records to the resource, e.g. "books". Records are stored as their json representation.
+// A record without an id gets the next id of the resource. Returns the ids of the records.
+func (s *Server) Seed(resource string, records ...interface{}) ([]int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids := make([]int, 0, len(records))
+	for _, v := range records {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return ids, err
+		}
+
+		record := map[string]interface{}{}
+		if err := json.Unmarshal(b, &record); err != nil {
+			return ids, err
+		}
+
+		ids = append(ids, s.resource(resource).add(record))
+	}
+
+	return ids, nil
+}
+
+// Records returns the records of the resource ordered by id.
+func (s *Server) Records(resource string) []map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.resource(resource).list()
+}
+
+// resource returns the resource, creating it if it does not exist. The mutex must be held.
+func (s *Server) resource(name string) *fakeResource {
+	r, ok := s.resources[name]
+	if !ok {
+		r = &fakeResource{nextID: 1, records: map[int]map[string]interface{}{}}
+		s.resources[name] = r
+	}
+	return r
+}
+
+func (r *fakeResource) add(record map[string]interface{}) int {
+	id, ok := recordID(record)
+	if !ok {
+		id = r.nextID
+		record["id"] = id
+	}
+	if id >= r.nextID {
+		r.nextID = id + 1
+	}
+
+	r.records[id] = record
+	return id
+}
+
+func (r *fakeResource) list() []map[string]interface{} {
+	ids := make([]int, 0, len(r.records))
+	for id := range r.records {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	records := make([]map[string]interface{}, len(ids))
+	for i, id := range ids {
+		records[i] = r.records[id]
+	}
+	return records
+}
+
+// recordID returns the id attribute of the record, if it has a numeric one.
+func recordID(record map[string]interface{}) (int, bool) {
+	switch v := record["id"].(type) {
+	case float64:
+		return int(v), true
+	case int:
+		return v, true
+	case string:
+		id, err := strconv.Atoi(v)
+		return id, err == nil
+	}
+	return 0, false
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+
+	if len(parts) == 2 && parts[1] == APIClient.RESOURCE_STATUSCHECK {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+		return
+	}
+
+	if len(parts) < 3 {
+		writeError(w, http.StatusNotFound, "NotFound", "Unknown endpoint")
+		return
+	}
+
+	if !s.authenticated(r) {
+		writeError(w, http.StatusUnauthorized, "Unauthorized", "Invalid credentials")
+		return
+	}
+
+	path := parts[2:]
+	if len(path) == 1 && path[0] == APIClient.RESOURCE_TOKEN {
+		if r.Method != http.MethodPost {
+			writeError(w, http.StatusMethodNotAllowed, "MethodNotAllowed", "Method not allowed")
+			return
+		}
+		w.Header().Set("token", s.Token)
+		writeJSON(w, http.StatusOK, map[string]string{})
+		return
+	}
+
+	id, err := strconv.Atoi(path[len(path)-1])
+	hasID := err == nil && len(path) > 1
+	if hasID {
+		path = path[:len(path)-1]
+	}
+	resource := strings.Join(path, "/")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	res := s.resource(resource)
+
+	switch {
+	case r.Method == http.MethodGet && !hasID:
+		s.index(w, r, res)
+	case r.Method == http.MethodPost && !hasID:
+		record := map[string]interface{}{}
+		if err := json.NewDecoder(r.Body).Decode(&record); err != nil {
+			writeError(w, http.StatusBadRequest, "BadRequest", "Invalid json body")
+			return
+		}
+		delete(record, "id")
+		res.add(record)
+		writeJSON(w, http.StatusOK, record)
+	case hasID:
+		record, ok := res.records[id]
+		if !ok {
+			writeError(w, http.StatusNotFound, "NotFound", fmt.Sprintf("No %s with id %d", resource, id))
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			writeJSON(w, http.StatusOK, record)
+		case http.MethodPut:
+			changes := map[string]interface{}{}
+			if err := json.NewDecoder(r.Body).Decode(&changes); err != nil {
+				writeError(w, http.StatusBadRequest, "BadRequest", "Invalid json body")
+				return
+			}
+			for k, v := range changes {
+				if k != "id" {
+					record[k] = v
+				}
+			}
+			writeJSON(w, http.StatusOK, record)
+		case http.MethodDelete:
+			delete(res.records, id)
+			writeJSON(w, http.StatusOK, map[string]string{})
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "MethodNotAllowed", "Method not allowed")
+		}
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "MethodNotAllowed", "Method not allowed")
+	}
+}
+
+// authenticated checks the token header, or the basic auth credentials in "user;account" format.
+func (s *Server) authenticated(r *http.Request) bool {
+	if t := r.Header.Get("token"); t != "" {
+		return t == s.Token
+	}
+
+	user, password, ok := r.BasicAuth()
+	if !ok {
+		return false
+	}
+
+	return strings.SplitN(user, ";", 2)[0] == s.User && password == s.Password
+}
+
+// index writes the records matching the query in a data and count envelope.
+func (s *Server) index(w http.ResponseWriter, r *http.Request, res *fakeResource) {
+	q := r.URL.Query()
+
+	records := []map[string]interface{}{}
+	for _, v := range res.list() {
+		if matchesFilters(v, q) {
+			records = append(records, v)
+		}
+	}
+
+	if orderBy := q.Get(common.QUERY_KEY_ORDER); orderBy != "" {
+		desc := strings.EqualFold(q.Get(common.QUERY_KEY_ORDER_DIR), "DESC")
+		attrs := strings.Split(orderBy, ",")
+		sort.SliceStable(records, func(i, j int) bool {
+			for _, a := range attrs {
+				c := compareValues(records[i][a], records[j][a])
+				if c != 0 {
+					return (c < 0) != desc
+				}
+			}
+			return false
+		})
+	}
+
+	count := len(records)
+
+	if limit := q.Get(common.QUERY_KEY_LIMIT); limit != "" {
+		parts := strings.SplitN(limit, ",", 2)
+		offset, err := strconv.Atoi(parts[0])
+		if err != nil || offset < 0 {
+			writeError(w, http.StatusBadRequest, "BadRequest", "Invalid limit offset")
+			return
+		}
+		n := len(records)
+		if len(parts) == 2 {
+			n, _ = strconv.Atoi(parts[1])
+		}
+
+		if offset > len(records) {
+			offset = len(records)
+		}
+		if offset+n > len(records) || n < 0 {
+			n = len(records) - offset
+		}
+		records = records[offset : offset+n]
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"data": records, "count": count})
+}
+
+// reservedQueryKeys are query keys that are not attribute filters.
+var reservedQueryKeys = map[string]bool{
+	common.QUERY_KEY_LIMIT:     true,
+	common.QUERY_KEY_WITH:      true,
+	common.QUERY_KEY_SCOPE:     true,
+	common.QUERY_KEY_AUX:       true,
+	common.QUERY_KEY_ORDER:     true,
+	common.QUERY_KEY_ORDER_DIR: true,
+	common.QUERY_KEY_GROUP_BY:  true,
+}
+
+// matchesFilters reports if the record matches all attribute filters of the query.
+func matchesFilters(record map[string]interface{}, q map[string][]string) bool {
+	for k, values := range q {
+		if reservedQueryKeys[k] || strings.HasSuffix(k, common.QUERY_ARGS_SUFFIX) {
+			continue
+		}
+
+		var operators []string
+		if args, ok := q[k+common.QUERY_ARGS_SUFFIX]; ok && len(args) > 0 {
+			for _, v := range strings.Split(args[0], ",") {
+				operators = append(operators, strings.SplitN(v, ";", 2)[0])
+			}
+		}
+
+		for i, v := range values {
+			op := "EQUAL"
+			if i < len(operators) {
+				op = operators[i]
+			}
+
+			if !compareOperator(compareValues(record[k], v), op) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func compareOperator(c int, op string) bool {
+	switch op {
+	case "NOT_EQUAL":
+		return c != 0
+	case "GREATER_EQUAL":
+		return c >= 0
+	case "GREATER":
+		return c > 0
+	case "LESS_EQUAL":
+		return c <= 0
+	case "LESS":
+		return c < 0
+	}
+	return c == 0
+}
+
+// compareValues compares the values numerically if both are numbers, and as strings otherwise.
+func compareValues(a, b interface{}) int {
+	as, bs := fmt.Sprint(a), fmt.Sprint(b)
+
+	af, aErr := strconv.ParseFloat(as, 64)
+	bf, bErr := strconv.ParseFloat(bs, 64)
+	if aErr == nil && bErr == nil {
+		switch {
+		case af < bf:
+			return -1
+		case af > bf:
+			return 1
+		}
+		return 0
+	}
+
+	return strings.Compare(as, bs)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes an error response in the format of common.APIErrorResponse.
+func writeError(w http.ResponseWriter, status int, errType, info string) {
+	writeJSON(w, status, common.APIErrorResponse{
+		Code:         status,
+		Type:         errType,
+		Errors:       []*common.APIError{{Info: info, Type: errType}},
+		CombinedInfo: info,
+	})
+}
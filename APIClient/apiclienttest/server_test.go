@@ -0,0 +1,128 @@
+package apiclienttest_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/publitsweden/APIUtilityGoSDK/APIClient"
+	"github.com/publitsweden/APIUtilityGoSDK/APIClient/apiclienttest"
+	"github.com/publitsweden/APIUtilityGoSDK/common"
+	"github.com/publitsweden/APIUtilityGoSDK/endpoint"
+)
+
+type Book struct {
+	ID    int    `json:"id,omitempty"`
+	Title string `json:"title"`
+	Pages int    `json:"pages"`
+}
+
+func bookEndpoint(format string, args ...interface{}) *endpoint.Resource {
+	return &endpoint.Resource{
+		Endpoint:   1,
+		Endpoints:  map[endpoint.Endpoint]string{1: format},
+		Qualifiers: args,
+	}
+}
+
+func TestServerServesStatusCheckAndToken(t *testing.T) {
+	t.Parallel()
+
+	s := apiclienttest.NewServer()
+	defer s.Close()
+
+	c := s.NewAPIClient("publishing")
+
+	if ok, err := c.StatusCheck(); !ok || err != nil {
+		t.Errorf("Expected status check to pass. Got %v, %v", ok, err)
+	}
+
+	if err := c.SetNewAPIToken(); err != nil {
+		t.Error("Expected to be able to set new API token but got an error.", err)
+	}
+}
+
+func TestServerServesCRUD(t *testing.T) {
+	t.Parallel()
+
+	s := apiclienttest.NewServer()
+	defer s.Close()
+
+	s.Seed("books", Book{Title: "First", Pages: 100}, Book{Title: "Second", Pages: 300})
+
+	c := s.NewAPIClient("publishing")
+
+	created := &Book{}
+	if err := c.Post(bookEndpoint("books"), &Book{Title: "Third", Pages: 200}, created); err != nil {
+		t.Fatal("Received an error but was not expecting to.", err)
+	}
+	if created.ID != 3 {
+		t.Errorf("Expected created book to get id 3, got %d", created.ID)
+	}
+
+	if err := c.Put(bookEndpoint("books/%v", 1), &Book{Title: "Updated", Pages: 100}, &Book{}); err != nil {
+		t.Fatal("Received an error but was not expecting to.", err)
+	}
+
+	list, err := APIClient.List[Book](
+		c,
+		bookEndpoint("books"),
+		APIClient.WithQuery(
+			common.QueryAttr(common.AttrQuery{Name: "pages", Value: "150", Args: common.AttrArgs{Operator: []common.Operator{common.OPERATOR_GREATER}}}),
+			common.QueryOrderBy([]string{"pages"}, common.ORDER_DIR_DESC),
+			common.QueryLimit(1, 0),
+		),
+	)
+	if err != nil {
+		t.Fatal("Received an error but was not expecting to.", err)
+	}
+	if list.Count != 2 || len(list.Data) != 1 || list.Data[0].Title != "Second" {
+		t.Errorf("Unexpected index result. Got %+v", list)
+	}
+
+	if err := c.Delete(bookEndpoint("books/%v", 2), nil); err != nil {
+		t.Fatal("Received an error but was not expecting to.", err)
+	}
+
+	if err := c.Get(bookEndpoint("books/%v", 2), &Book{}); !errors.Is(err, APIClient.ErrNotFound) {
+		t.Errorf("Expected a not found error, got %v", err)
+	}
+
+	book := &Book{}
+	if err := c.Get(bookEndpoint("books/%v", 1), book); err != nil || book.Title != "Updated" {
+		t.Errorf("Unexpected show result. Got %+v, %v", book, err)
+	}
+}
+
+func TestServerRejectsNegativeLimitOffset(t *testing.T) {
+	t.Parallel()
+
+	s := apiclienttest.NewServer()
+	defer s.Close()
+
+	s.Seed("books", Book{Title: "First", Pages: 100})
+
+	c := s.NewAPIClient("publishing")
+
+	if err := c.Get(bookEndpoint("books"), &struct{}{}, common.QueryLimit(1, -1)); err == nil {
+		t.Error("Expected an error but did not receive one.")
+	}
+	if c.GetLastResponseCode() != http.StatusBadRequest {
+		t.Errorf("Unexpected response code. Expected %d, got %d", http.StatusBadRequest, c.GetLastResponseCode())
+	}
+}
+
+func TestServerRequiresAuthentication(t *testing.T) {
+	t.Parallel()
+
+	s := apiclienttest.NewServer()
+	defer s.Close()
+
+	s.Password = "otherpassword"
+	c := s.NewAPIClient("publishing")
+	s.Password = apiclienttest.TEST_PASSWORD
+
+	if err := c.Get(bookEndpoint("books"), &struct{}{}); !errors.Is(err, APIClient.ErrUnauthorized) {
+		t.Errorf("Expected an unauthorized error, got %v", err)
+	}
+}
@@ -0,0 +1,196 @@
+// Copyright 2018 Publit Sweden AB. All rights reserved.
+
+package APIClient
+
+import (
+	"bytes"
+	"container/list"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/publitsweden/APIUtilityGoSDK/client"
+)
+
+// Default max amount of entries in a ResponseCache.
+const DEFAULT_CACHE_SIZE = 1000
+
+// ResponseCache is an in-memory read-through cache of successful GET responses, indexed by cacheKey.
+// Add it to an APIClient with APIClient.Use(cache.Middleware()). Entries are not separated by credentials, so don't
+// share a ResponseCache between APIClients.
+// Entries expire after the TTL, and the least recently used entry is evicted when the cache is full.
+type ResponseCache struct {
+	// TTL is the time an entry is served from the cache.
+	TTL time.Duration
+	// MaxEntries is the max amount of cached responses. Defaults to DEFAULT_CACHE_SIZE.
+	MaxEntries int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+	now     func() time.Time
+}
+
+// cacheEntry is a cached response.
+type cacheEntry struct {
+	key     string
+	header  http.Header
+	body    []byte
+	expires time.Time
+}
+
+// NewResponseCache creates a new ResponseCache with the given TTL and max amount of entries.
+func NewResponseCache(ttl time.Duration, maxEntries int) *ResponseCache {
+	return &ResponseCache{
+		TTL:        ttl,
+		MaxEntries: maxEntries,
+		entries:    map[string]*list.Element{},
+		lru:        list.New(),
+		now:        time.Now,
+	}
+}
+
+// Middleware returns the middleware serving GET requests from the cache.
+func (c *ResponseCache) Middleware() Middleware {
+	return func(next CallFunc) CallFunc {
+		return func(r *http.Request) (*http.Response, error) {
+			if r.Method != http.MethodGet {
+				return next(r)
+			}
+
+			key := cacheKey(r)
+			if entry, ok := c.get(key); ok {
+				return cachedResponse(r, entry.header, entry.body), nil
+			}
+
+			resp, err := next(r)
+			if err != nil || resp == nil || resp.StatusCode != http.StatusOK || resp.Body == nil {
+				return resp, err
+			}
+
+			body, err := ioutil.ReadAll(resp.Body)
+			resp.Body.Close()
+			resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+			if err != nil {
+				return resp, err
+			}
+
+			c.set(&cacheEntry{key: key, header: resp.Header.Clone(), body: body})
+
+			return resp, nil
+		}
+	}
+}
+
+// Len returns the amount of entries in the cache, including expired entries not yet evicted.
+func (c *ResponseCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+// Clear removes all entries from the cache.
+func (c *ResponseCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = map[string]*list.Element{}
+	c.lru = list.New()
+}
+
+func (c *ResponseCache) get(key string) (*cacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := el.Value.(*cacheEntry)
+	if !c.now().Before(entry.expires) {
+		c.lru.Remove(el)
+		delete(c.entries, key)
+		return nil, false
+	}
+
+	c.lru.MoveToFront(el)
+	return entry, true
+}
+
+func (c *ResponseCache) set(entry *cacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.entries == nil {
+		c.entries = map[string]*list.Element{}
+		c.lru = list.New()
+	}
+	if c.now == nil {
+		c.now = time.Now
+	}
+
+	entry.expires = c.now().Add(c.TTL)
+
+	if el, ok := c.entries[entry.key]; ok {
+		el.Value = entry
+		c.lru.MoveToFront(el)
+		return
+	}
+
+	c.entries[entry.key] = c.lru.PushFront(entry)
+
+	max := c.MaxEntries
+	if max <= 0 {
+		max = DEFAULT_CACHE_SIZE
+	}
+
+	for c.lru.Len() > max {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// Request headers that select the representation of a response. Responses are only served from the caches to requests
+// with the same values.
+var cacheVaryHeaders = []string{"Accept", "Accept-Language"}
+
+// cacheKey returns the key of the request in the caches: the URL including query, the account id of the request
+// context and the cacheVaryHeaders. Requests acting for different accounts, or asking for a different codec or
+// locale, never share cached responses.
+// The credentials are not part of the key, as the middlewares run before the client.Client authenticates the request,
+// so a cache must only be used by a single APIClient.
+func cacheKey(r *http.Request) string {
+	b := strings.Builder{}
+	b.WriteString(r.URL.String())
+
+	if id, ok := client.AccountIDFromContext(r.Context()); ok {
+		fmt.Fprintf(&b, "\naccount:%d", id)
+	}
+
+	for _, h := range cacheVaryHeaders {
+		if v := r.Header.Values(h); len(v) > 0 {
+			fmt.Fprintf(&b, "\n%s:%s", h, strings.Join(v, ","))
+		}
+	}
+
+	return b.String()
+}
+
+// cachedResponse creates a new ok response with the cached header and body.
+func cachedResponse(r *http.Request, header http.Header, body []byte) *http.Response {
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header.Clone(),
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       r,
+	}
+}
@@ -0,0 +1,157 @@
+package APIClient_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	. "github.com/publitsweden/APIUtilityGoSDK/APIClient"
+	"github.com/publitsweden/APIUtilityGoSDK/common"
+)
+
+func TestResponseCacheServesCachedResponses(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	caller := &MockAPICaller{}
+	caller.T = t
+	caller.CallTestCallback = func(t *testing.T, r *http.Request) {
+		calls++
+		caller.Response = createCallerResponse(http.StatusOK, `{"some":"body"}`)
+	}
+
+	c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI}
+	c.Use(NewResponseCache(time.Minute, 10).Middleware())
+
+	for i := 0; i < 3; i++ {
+		model := &struct {
+			Some string `json:"some"`
+		}{}
+		if err := c.Get(NewEndpoint(), model); err != nil {
+			t.Error("Expected Get to pass but received error.", err)
+		}
+
+		if model.Some != "body" {
+			t.Error("Unmarshalled struct did not match expected.")
+		}
+	}
+
+	if calls != 1 {
+		t.Errorf("Expected exactly 1 call to be made, but %d were made.", calls)
+	}
+
+	// Different query means different cache key.
+	if err := c.Get(NewEndpoint(), &struct{}{}, common.QueryLimit(1, 0)); err != nil {
+		t.Error("Expected Get to pass but received error.", err)
+	}
+
+	if calls != 2 {
+		t.Errorf("Expected exactly 2 calls to be made, but %d were made.", calls)
+	}
+}
+
+func TestResponseCacheEntriesExpire(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	caller := &MockAPICaller{}
+	caller.T = t
+	caller.CallTestCallback = func(t *testing.T, r *http.Request) {
+		calls++
+		caller.Response = createCallerResponse(http.StatusOK, `{}`)
+	}
+
+	c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI}
+	c.Use(NewResponseCache(10*time.Millisecond, 10).Middleware())
+
+	c.Get(NewEndpoint(), &struct{}{})
+	time.Sleep(20 * time.Millisecond)
+	c.Get(NewEndpoint(), &struct{}{})
+
+	if calls != 2 {
+		t.Errorf("Expected exactly 2 calls to be made, but %d were made.", calls)
+	}
+}
+
+func TestResponseCacheIsBounded(t *testing.T) {
+	t.Parallel()
+
+	caller := &MockAPICaller{}
+	caller.T = t
+	caller.CallTestCallback = func(t *testing.T, r *http.Request) {
+		caller.Response = createCallerResponse(http.StatusOK, `{}`)
+	}
+
+	cache := NewResponseCache(time.Minute, 2)
+	c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI}
+	c.Use(cache.Middleware())
+
+	for i := 0; i < 5; i++ {
+		c.Get(NewEndpoint(), &struct{}{}, common.QueryLimit(1, i))
+	}
+
+	if cache.Len() != 2 {
+		t.Errorf("Expected cache to hold 2 entries, but held %d.", cache.Len())
+	}
+}
+
+func TestResponseCacheSeparatesAccountsAndLocales(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	caller := &MockAPICaller{}
+	caller.T = t
+	caller.CallTestCallback = func(t *testing.T, r *http.Request) {
+		calls++
+		caller.Response = createCallerResponse(http.StatusOK, `{"some":"body"}`)
+	}
+
+	c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI}
+	c.Use(NewResponseCache(time.Minute, 10).Middleware())
+
+	opts := [][]RequestOption{
+		nil,
+		{WithAccountID(1)},
+		{WithAccountID(2)},
+		{WithAccountID(2), WithLocale("sv-SE")},
+		{WithAccountID(2), WithLocale("sv-SE")},
+		{WithAccountID(1)},
+	}
+
+	for _, o := range opts {
+		if err := c.Do(http.MethodGet, NewEndpoint(), nil, &struct{}{}, o...); err != nil {
+			t.Error("Expected Get to pass but received error.", err)
+		}
+	}
+
+	if calls != 4 {
+		t.Errorf("Expected exactly 4 calls to be made, but %d were made.", calls)
+	}
+}
+
+func TestResponseCacheIsPerAPIClient(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	caller := &MockAPICaller{}
+	caller.T = t
+	caller.CallTestCallback = func(t *testing.T, r *http.Request) {
+		calls++
+		caller.Response = createCallerResponse(http.StatusOK, `{"some":"body"}`)
+	}
+
+	for i := 0; i < 2; i++ {
+		c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI}
+		c.Use(NewResponseCache(time.Minute, 10).Middleware())
+
+		for j := 0; j < 2; j++ {
+			if err := c.Get(NewEndpoint(), &struct{}{}); err != nil {
+				t.Error("Expected Get to pass but received error.", err)
+			}
+		}
+	}
+
+	if calls != 2 {
+		t.Errorf("Expected exactly 2 calls to be made, but %d were made.", calls)
+	}
+}
@@ -0,0 +1,160 @@
+// Copyright 2018 Publit Sweden AB. All rights reserved.
+
+package APIClient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned for requests rejected by an open CircuitBreaker.
+var ErrCircuitOpen = errors.New("Circuit breaker is open. Request not sent")
+
+// CircuitState is the state of a CircuitBreaker.
+type CircuitState int
+
+// CircuitState enum constants.
+const (
+	// Requests are sent as usual.
+	CIRCUIT_CLOSED CircuitState = 1 + iota
+	// Requests fail fast with ErrCircuitOpen.
+	CIRCUIT_OPEN
+	// A single probe request is let through to check if the backend has recovered.
+	CIRCUIT_HALF_OPEN
+)
+
+// CircuitBreaker stops sending requests after a number of consecutive failures, so a struggling backend does not get hammered.
+// Failures are transport errors and responses with 5xx status codes.
+// When open, requests fail fast with ErrCircuitOpen until the cooldown has passed. After that a single probe request is sent:
+// if it succeeds the circuit closes, otherwise it opens again.
+// Add it to an APIClient with APIClient.Use(breaker.Middleware()) or with the WithCircuitBreaker option.
+type CircuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu         sync.Mutex
+	state      CircuitState
+	generation uint64
+	failures   int
+	openedAt   time.Time
+	probing    bool
+}
+
+// NewCircuitBreaker creates a CircuitBreaker opening after threshold consecutive failures and staying open for cooldown.
+// A threshold lower than 1 is set to 1.
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	if threshold < 1 {
+		threshold = 1
+	}
+
+	return &CircuitBreaker{threshold: threshold, cooldown: cooldown, state: CIRCUIT_CLOSED}
+}
+
+// WithCircuitBreaker adds a CircuitBreaker to the APIClient.
+func WithCircuitBreaker(threshold int, cooldown time.Duration) Option {
+	return func(c *APIClient) {
+		c.Use(NewCircuitBreaker(threshold, cooldown).Middleware())
+	}
+}
+
+// State returns the current state of the circuit.
+func (b *CircuitBreaker) State() CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == CIRCUIT_OPEN && time.Since(b.openedAt) >= b.cooldown {
+		return CIRCUIT_HALF_OPEN
+	}
+	return b.state
+}
+
+// Middleware returns the middleware guarding requests with the circuit breaker.
+// Requests canceled by their context are not counted as failures or successes.
+func (b *CircuitBreaker) Middleware() Middleware {
+	return func(next CallFunc) CallFunc {
+		return func(r *http.Request) (*http.Response, error) {
+			generation, err := b.allow()
+			if err != nil {
+				return nil, err
+			}
+
+			resp, err := next(r)
+			if errors.Is(err, context.Canceled) {
+				b.release(generation)
+			} else {
+				b.report(generation, err == nil && resp != nil && resp.StatusCode < http.StatusInternalServerError)
+			}
+
+			return resp, err
+		}
+	}
+}
+
+// allow checks if a request may be sent, and returns the generation of the state the request is admitted in.
+func (b *CircuitBreaker) allow() (uint64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case CIRCUIT_OPEN:
+		if time.Since(b.openedAt) < b.cooldown {
+			return 0, ErrCircuitOpen
+		}
+		b.setState(CIRCUIT_HALF_OPEN)
+		b.probing = true
+	case CIRCUIT_HALF_OPEN:
+		if b.probing {
+			return 0, ErrCircuitOpen
+		}
+		b.probing = true
+	}
+
+	return b.generation, nil
+}
+
+// setState changes the state of the circuit and starts a new generation, so outcomes of requests admitted in the
+// previous state are ignored. Must be called with mu held.
+func (b *CircuitBreaker) setState(state CircuitState) {
+	b.state = state
+	b.generation++
+}
+
+// release ends a request admitted in the generation without recording an outcome, letting another probe through.
+func (b *CircuitBreaker) release(generation uint64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if generation == b.generation && b.state == CIRCUIT_HALF_OPEN {
+		b.probing = false
+	}
+}
+
+// report records the outcome of a request admitted in the generation. Outcomes of earlier generations are stale and
+// ignored, eg. a slow request admitted before the circuit opened does not close it.
+func (b *CircuitBreaker) report(generation uint64, success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if generation != b.generation {
+		return
+	}
+
+	if success {
+		b.failures = 0
+		b.probing = false
+		if b.state != CIRCUIT_CLOSED {
+			b.setState(CIRCUIT_CLOSED)
+		}
+		return
+	}
+
+	b.failures++
+	if b.state == CIRCUIT_HALF_OPEN || b.failures >= b.threshold {
+		b.probing = false
+		b.openedAt = time.Now()
+		b.setState(CIRCUIT_OPEN)
+	}
+}
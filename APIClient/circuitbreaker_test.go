@@ -0,0 +1,124 @@
+package APIClient_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	. "github.com/publitsweden/APIUtilityGoSDK/APIClient"
+)
+
+func TestCircuitBreakerOpensAfterConsecutiveFailures(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	caller := &MockAPICaller{}
+	caller.T = t
+	caller.CallTestCallback = func(t *testing.T, r *http.Request) {
+		calls++
+		caller.Response = createCallerResponse(http.StatusServiceUnavailable, "")
+	}
+
+	breaker := NewCircuitBreaker(2, time.Minute)
+	c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI}
+	c.Use(breaker.Middleware())
+
+	for i := 0; i < 2; i++ {
+		if err := c.Get(NewEndpoint(), &struct{}{}); err == nil || errors.Is(err, ErrCircuitOpen) {
+			t.Errorf("Expected response error, got %v", err)
+		}
+	}
+
+	if breaker.State() != CIRCUIT_OPEN {
+		t.Error("Expected circuit to be open but was not.")
+	}
+
+	if err := c.Get(NewEndpoint(), &struct{}{}); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Expected circuit open error, got %v", err)
+	}
+
+	if calls != 2 {
+		t.Errorf("Expected exactly 2 calls to be made, but %d were made.", calls)
+	}
+}
+
+func TestCircuitBreakerClosesAfterSuccessfulProbe(t *testing.T) {
+	t.Parallel()
+
+	status := http.StatusInternalServerError
+	caller := &MockAPICaller{}
+	caller.T = t
+	caller.CallTestCallback = func(t *testing.T, r *http.Request) {
+		caller.Response = createCallerResponse(status, `{}`)
+	}
+
+	breaker := NewCircuitBreaker(1, 10*time.Millisecond)
+	c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI}
+	c.Use(breaker.Middleware())
+
+	c.Get(NewEndpoint(), &struct{}{})
+
+	if breaker.State() != CIRCUIT_OPEN {
+		t.Fatal("Expected circuit to be open but was not.")
+	}
+
+	time.Sleep(20 * time.Millisecond)
+
+	if breaker.State() != CIRCUIT_HALF_OPEN {
+		t.Fatal("Expected circuit to be half open but was not.")
+	}
+
+	status = http.StatusOK
+	if err := c.Get(NewEndpoint(), &struct{}{}); err != nil {
+		t.Error("Expected probe to pass but received error.", err)
+	}
+
+	if breaker.State() != CIRCUIT_CLOSED {
+		t.Error("Expected circuit to be closed but was not.")
+	}
+}
+
+func TestCircuitBreakerIgnoresStaleAndCanceledRequests(t *testing.T) {
+	t.Parallel()
+
+	breaker := NewCircuitBreaker(1, time.Minute)
+	r, _ := http.NewRequest(http.MethodGet, "somebaseurl", nil)
+
+	canceled := breaker.Middleware()(func(r *http.Request) (*http.Response, error) {
+		return nil, context.Canceled
+	})
+	if _, err := canceled(r); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected canceled error, got %v", err)
+	}
+	if breaker.State() != CIRCUIT_CLOSED {
+		t.Fatal("Expected canceled request not to open the circuit.")
+	}
+
+	admitted := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan struct{})
+	slow := breaker.Middleware()(func(r *http.Request) (*http.Response, error) {
+		close(admitted)
+		<-release
+		return createCallerResponse(http.StatusOK, `{}`), nil
+	})
+	go func() {
+		slow(r)
+		close(done)
+	}()
+	<-admitted
+
+	failing := breaker.Middleware()(func(r *http.Request) (*http.Response, error) {
+		return createCallerResponse(http.StatusServiceUnavailable, ""), nil
+	})
+	failing(r)
+
+	close(release)
+	<-done
+
+	if breaker.State() != CIRCUIT_OPEN {
+		t.Error("Expected success of request admitted before the circuit opened to be ignored.")
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright 2018 Publit Sweden AB. All rights reserved.
+
+package APIClient
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+)
+
+// Codec encodes request payloads and decodes response bodies.
+// Set APIClient.Codec to change the codec of all requests, or use WithCodec for a single request.
+// Other formats, like msgpack, can be supported by implementing this interface.
+type Codec interface {
+	// ContentType is the media type of the encoded data, used for the Content-Type and Accept headers.
+	ContentType() string
+	Encode(w io.Writer, v interface{}) error
+	Decode(r io.Reader, v interface{}) error
+}
+
+// Codecs supported out of the box.
+var (
+	JSONCodec Codec = jsonCodec{}
+	XMLCodec  Codec = xmlCodec{}
+	CSVCodec  Codec = csvCodec{}
+)
+
+type jsonCodec struct{}
+
+func (jsonCodec) ContentType() string { return "application/json" }
+
+func (jsonCodec) Encode(w io.Writer, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(b)
+	return err
+}
+
+func (jsonCodec) Decode(r io.Reader, v interface{}) error { return json.NewDecoder(r).Decode(v) }
+
+type xmlCodec struct{}
+
+func (xmlCodec) ContentType() string { return "application/xml" }
+
+func (xmlCodec) Encode(w io.Writer, v interface{}) error { return xml.NewEncoder(w).Encode(v) }
+
+func (xmlCodec) Decode(r io.Reader, v interface{}) error { return xml.NewDecoder(r).Decode(v) }
+
+// csvCodec encodes [][]string payloads and decodes into *[][]string results.
+type csvCodec struct{}
+
+func (csvCodec) ContentType() string { return "text/csv" }
+
+func (csvCodec) Encode(w io.Writer, v interface{}) error {
+	records, ok := v.([][]string)
+	if !ok {
+		if p, isPointer := v.(*[][]string); isPointer && p != nil {
+			records, ok = *p, true
+		}
+	}
+	if !ok {
+		return errors.New("Could not encode csv. Payload must be [][]string")
+	}
+
+	return csv.NewWriter(w).WriteAll(records)
+}
+
+func (csvCodec) Decode(r io.Reader, v interface{}) error {
+	records, ok := v.(*[][]string)
+	if !ok || records == nil {
+		return errors.New("Could not decode csv. Result must be *[][]string")
+	}
+
+	var err error
+	*records, err = csv.NewReader(r).ReadAll()
+	return err
+}
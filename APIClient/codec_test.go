@@ -0,0 +1,68 @@
+package APIClient_test
+
+import (
+	"encoding/xml"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"testing"
+
+	. "github.com/publitsweden/APIUtilityGoSDK/APIClient"
+)
+
+func TestCanUseXMLCodecForClient(t *testing.T) {
+	t.Parallel()
+
+	type Product struct {
+		XMLName xml.Name `xml:"Product"`
+		Title   string   `xml:"Title"`
+	}
+
+	caller := &MockAPICaller{}
+	caller.T = t
+	caller.Response = createCallerResponse(http.StatusOK, `<Product><Title>New title</Title></Product>`)
+	caller.CallTestCallback = func(t *testing.T, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/xml" {
+			t.Errorf("Unexpected content type. Got %s", r.Header.Get("Content-Type"))
+		}
+
+		if r.Header.Get("Accept") != "application/xml" {
+			t.Errorf("Unexpected accept header. Got %s", r.Header.Get("Accept"))
+		}
+
+		b, _ := ioutil.ReadAll(r.Body)
+		if string(b) != `<Product><Title>Some title</Title></Product>` {
+			t.Errorf("Unexpected request body. Got %s", b)
+		}
+	}
+
+	c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI, Codec: XMLCodec}
+
+	p := &Product{Title: "Some title"}
+	if err := c.Put(NewEndpoint(), p, p); err != nil {
+		t.Error("Received an error but was not expecting to.", err)
+	}
+
+	if p.Title != "New title" {
+		t.Error("Struct did not have expected value.")
+	}
+}
+
+func TestCanUseCSVCodecPerCall(t *testing.T) {
+	t.Parallel()
+
+	caller := &MockAPICaller{}
+	caller.Response = createCallerResponse(http.StatusOK, "isbn,title\n9789100000000,\"Crime, Punishment\"\n")
+
+	c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI}
+
+	records := [][]string{}
+	if err := c.Do(http.MethodGet, NewEndpoint(), nil, &records, WithCodec(CSVCodec)); err != nil {
+		t.Error("Received an error but was not expecting to.", err)
+	}
+
+	expected := [][]string{{"isbn", "title"}, {"9789100000000", "Crime, Punishment"}}
+	if !reflect.DeepEqual(records, expected) {
+		t.Errorf("Unexpected records. Expected %v, got %v", expected, records)
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright 2018 Publit Sweden AB. All rights reserved.
+
+package APIClient
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// Default min size in bytes of request bodies compressed by GzipCompression.
+const DEFAULT_GZIP_MIN_SIZE = 1024
+
+// GzipCompression returns a middleware compressing json request bodies of at least minSize bytes with gzip.
+// Other bodies, such as PostMultipart bodies and io.Reader payloads, are sent as is.
+// It also asks for gzip compressed responses and decompresses them before they are decoded, except for HEAD requests
+// and requests with a Range header, such as DownloadResumable.
+// Setting the Accept-Encoding header makes the middleware own decoding of the response, and the client.Client then
+// leaves it undecoded. Without the middleware the client.Client requests and decodes compressed responses itself, see
+// client.Client.DisableCompression.
+// If minSize is 0 DEFAULT_GZIP_MIN_SIZE is used.
+func GzipCompression(minSize int) Middleware {
+	if minSize <= 0 {
+		minSize = DEFAULT_GZIP_MIN_SIZE
+	}
+
+	return func(next CallFunc) CallFunc {
+		return func(r *http.Request) (*http.Response, error) {
+			if err := compressRequestBody(r, minSize); err != nil {
+				return nil, err
+			}
+
+			if r.Method == http.MethodHead || r.Header.Get("Range") != "" {
+				return next(r)
+			}
+			if r.Header.Get("Accept-Encoding") == "" {
+				r.Header.Set("Accept-Encoding", "gzip")
+			}
+
+			resp, err := next(r)
+			if err != nil || resp == nil || resp.Body == nil {
+				return resp, err
+			}
+
+			if !strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+				return resp, nil
+			}
+
+			gz, err := gzip.NewReader(resp.Body)
+			if err != nil {
+				return resp, &DecodeError{StatusCode: resp.StatusCode, ContentType: resp.Header.Get("Content-Type"), Err: err}
+			}
+
+			resp.Body = &gzipReadCloser{Reader: gz, body: resp.Body}
+			resp.Header.Del("Content-Encoding")
+			resp.Header.Del("Content-Length")
+			resp.ContentLength = -1
+			resp.Uncompressed = true
+
+			return resp, nil
+		}
+	}
+}
+
+// compressRequestBody replaces the request body with its gzip compressed equivalent if it is json of at least minSize
+// bytes. Only replayable bodies, such as encoded payloads, are read.
+func compressRequestBody(r *http.Request, minSize int) error {
+	if r.Body == nil || r.Body == http.NoBody || r.GetBody == nil || r.Header.Get("Content-Encoding") != "" {
+		return nil
+	}
+	if !isJSONMediaType(r.Header.Get("Content-Type")) {
+		return nil
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	r.Body.Close()
+	if err != nil {
+		return err
+	}
+
+	if len(body) < minSize {
+		setRequestBody(r, body)
+		return nil
+	}
+
+	b := &bytes.Buffer{}
+	gz := gzip.NewWriter(b)
+	if _, err := gz.Write(body); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+
+	setRequestBody(r, b.Bytes())
+	r.Header.Set("Content-Encoding", "gzip")
+
+	return nil
+}
+
+// setRequestBody sets a replayable body on the request.
+func setRequestBody(r *http.Request, body []byte) {
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	r.ContentLength = int64(len(body))
+	r.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(body)), nil
+	}
+}
+
+// gzipReadCloser reads from a gzip reader and closes both it and the underlying body.
+type gzipReadCloser struct {
+	*gzip.Reader
+	body io.ReadCloser
+}
+
+// Close closes the gzip reader and the underlying body.
+func (g *gzipReadCloser) Close() error {
+	g.Reader.Close()
+	return g.body.Close()
+}
@@ -0,0 +1,183 @@
+package APIClient_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	. "github.com/publitsweden/APIUtilityGoSDK/APIClient"
+)
+
+func TestGzipCompressionCompressesLargeRequestBodies(t *testing.T) {
+	t.Parallel()
+
+	payload := struct {
+		Description string `json:"description"`
+	}{Description: strings.Repeat("a", 2048)}
+
+	caller := &MockAPICaller{}
+	caller.T = t
+	caller.Response = createCallerResponse(http.StatusOK, `{}`)
+	caller.CallTestCallback = func(t *testing.T, r *http.Request) {
+		if r.Header.Get("Content-Encoding") != "gzip" {
+			t.Fatal("Expected request body to be gzip encoded but was not.")
+		}
+
+		if r.Header.Get("Accept-Encoding") != "gzip" {
+			t.Error("Expected Accept-Encoding header to be set but was not.")
+		}
+
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			t.Fatal("Could not read gzip body.", err)
+		}
+
+		b, _ := ioutil.ReadAll(gz)
+		if !strings.Contains(string(b), payload.Description) {
+			t.Error("Decompressed body did not match expected.")
+		}
+	}
+
+	c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI}
+	c.Use(GzipCompression(1024))
+
+	if err := c.Post(NewEndpoint(), &payload, &struct{}{}); err != nil {
+		t.Error("Received an error but was not expecting to.", err)
+	}
+}
+
+func TestGzipCompressionLeavesSmallRequestBodies(t *testing.T) {
+	t.Parallel()
+
+	caller := &MockAPICaller{}
+	caller.T = t
+	caller.Response = createCallerResponse(http.StatusOK, `{}`)
+	caller.CallTestCallback = func(t *testing.T, r *http.Request) {
+		if r.Header.Get("Content-Encoding") != "" {
+			t.Error("Did not expect small request body to be compressed.")
+		}
+
+		b, _ := ioutil.ReadAll(r.Body)
+		if string(b) != `{"name":"test"}` {
+			t.Errorf("Unexpected request body. Got %s", b)
+		}
+	}
+
+	c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI}
+	c.Use(GzipCompression(1024))
+
+	payload := struct {
+		Name string `json:"name"`
+	}{Name: "test"}
+	if err := c.Post(NewEndpoint(), &payload, &struct{}{}); err != nil {
+		t.Error("Received an error but was not expecting to.", err)
+	}
+}
+
+func TestGzipCompressionDecompressesResponses(t *testing.T) {
+	t.Parallel()
+
+	b := &bytes.Buffer{}
+	gz := gzip.NewWriter(b)
+	gz.Write([]byte(`{"some":"body"}`))
+	gz.Close()
+
+	caller := &MockAPICaller{}
+	caller.Response = createCallerResponse(http.StatusOK, b.String())
+	caller.Response.Header = http.Header{"Content-Encoding": []string{"gzip"}}
+
+	c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI}
+	c.Use(GzipCompression(0))
+
+	model := &struct {
+		Some string `json:"some"`
+	}{}
+	if err := c.Get(NewEndpoint(), model); err != nil {
+		t.Error("Expected Get to pass but received error.", err)
+	}
+
+	if model.Some != "body" {
+		t.Error("Unmarshalled struct did not match expected.")
+	}
+}
+
+func TestGzipCompressionLeavesRangeRequestsUncompressed(t *testing.T) {
+	t.Parallel()
+
+	caller := &MockAPICaller{T: t}
+	caller.Response = createCallerResponse(http.StatusOK, `{"some":"body"}`)
+	caller.CallTestCallback = func(t *testing.T, r *http.Request) {
+		if ae := r.Header.Get("Accept-Encoding"); ae != "" {
+			t.Errorf("Expected no Accept-Encoding header for range request, got %q.", ae)
+		}
+	}
+
+	c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI}
+	c.Use(GzipCompression(0))
+
+	model := &struct {
+		Some string `json:"some"`
+	}{}
+	if err := c.Do(http.MethodGet, NewEndpoint(), nil, model, WithHeader("Range", "bytes=10-")); err != nil {
+		t.Error("Expected Do to pass but received error.", err)
+	}
+}
+
+func TestGzipCompressionLeavesNonJSONRequestBodies(t *testing.T) {
+	t.Parallel()
+
+	caller := &MockAPICaller{}
+	caller.T = t
+	caller.Response = createCallerResponse(http.StatusOK, `{}`)
+	caller.CallTestCallback = func(t *testing.T, r *http.Request) {
+		if r.Header.Get("Content-Encoding") != "" {
+			t.Errorf("Did not expect %s request body to be compressed.", r.Header.Get("Content-Type"))
+		}
+	}
+
+	c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI}
+	c.Use(GzipCompression(1024))
+
+	content := strings.Repeat("a", 2048)
+
+	if err := c.Post(NewEndpoint(), strings.NewReader(content), &struct{}{}); err != nil {
+		t.Error("Received an error but was not expecting to.", err)
+	}
+
+	files := map[string]io.Reader{"file": strings.NewReader(content)}
+	if err := c.PostMultipart(NewEndpoint(), nil, files, &struct{}{}); err != nil {
+		t.Error("Received an error but was not expecting to.", err)
+	}
+}
+
+func TestGzipCompressionReturnsDecodeErrorForInvalidResponses(t *testing.T) {
+	t.Parallel()
+
+	caller := &MockAPICaller{}
+	caller.Response = createCallerResponse(http.StatusOK, "not gzip")
+	caller.Response.Header = http.Header{"Content-Encoding": []string{"gzip"}}
+
+	c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI}
+	c.Use(GzipCompression(0))
+
+	err := c.Get(NewEndpoint(), &struct{}{})
+
+	var decodeErr *DecodeError
+	if !errors.As(err, &decodeErr) {
+		t.Errorf("Expected a decode error, got %v", err)
+	}
+
+	var transportErr *TransportError
+	if errors.As(err, &transportErr) {
+		t.Errorf("Did not expect a transport error, got %v", err)
+	}
+
+	if c.GetLastResponseCode() != http.StatusOK {
+		t.Errorf("Expected the response to be recorded. Got %d", c.GetLastResponseCode())
+	}
+}
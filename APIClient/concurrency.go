@@ -0,0 +1,86 @@
+// Copyright 2018 Publit Sweden AB. All rights reserved.
+
+package APIClient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// ConcurrencyLimiter limits the amount of simultaneous outstanding requests.
+// A request is outstanding until its response body is closed, or until the call fails.
+// Add it to an APIClient with APIClient.Use(limiter.Middleware()) or the WithMaxConcurrentRequests option.
+type ConcurrencyLimiter struct {
+	slots chan struct{}
+}
+
+// NewConcurrencyLimiter creates a limiter allowing at most max outstanding requests. A max below 1 allows one request.
+func NewConcurrencyLimiter(max int) *ConcurrencyLimiter {
+	if max < 1 {
+		max = 1
+	}
+	return &ConcurrencyLimiter{slots: make(chan struct{}, max)}
+}
+
+// WithMaxConcurrentRequests adds a ConcurrencyLimiter to the APIClient.
+func WithMaxConcurrentRequests(max int) Option {
+	return func(c *APIClient) {
+		c.Use(NewConcurrencyLimiter(max).Middleware())
+	}
+}
+
+// Acquire blocks until a request slot is free or ctx is done, in which case the context error is returned.
+// Every successful Acquire must be followed by a Release.
+func (l *ConcurrencyLimiter) Acquire(ctx context.Context) error {
+	select {
+	case l.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Release frees a request slot.
+func (l *ConcurrencyLimiter) Release() {
+	<-l.slots
+}
+
+// InFlight returns the amount of outstanding requests.
+func (l *ConcurrencyLimiter) InFlight() int {
+	return len(l.slots)
+}
+
+// Middleware returns the middleware limiting the amount of outstanding requests.
+func (l *ConcurrencyLimiter) Middleware() Middleware {
+	return func(next CallFunc) CallFunc {
+		return func(r *http.Request) (*http.Response, error) {
+			if err := l.Acquire(r.Context()); err != nil {
+				return nil, err
+			}
+
+			resp, err := next(r)
+			if err != nil || resp == nil || resp.Body == nil {
+				l.Release()
+				return resp, err
+			}
+
+			resp.Body = &releasingBody{ReadCloser: resp.Body, release: l.Release}
+			return resp, nil
+		}
+	}
+}
+
+// releasingBody releases a request slot when the response body is closed.
+type releasingBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
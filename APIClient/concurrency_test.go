@@ -0,0 +1,78 @@
+package APIClient_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/publitsweden/APIUtilityGoSDK/APIClient"
+)
+
+func TestConcurrencyLimiterLimitsOutstandingRequests(t *testing.T) {
+	t.Parallel()
+
+	limiter := NewConcurrencyLimiter(2)
+
+	var mu sync.Mutex
+	inFlight, maxInFlight := 0, 0
+
+	caller := &ConcurrentMockAPICaller{}
+	c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI}
+	c.Use(limiter.Middleware(), func(next CallFunc) CallFunc {
+		return func(r *http.Request) (*http.Response, error) {
+			mu.Lock()
+			inFlight++
+			if inFlight > maxInFlight {
+				maxInFlight = inFlight
+			}
+			mu.Unlock()
+
+			time.Sleep(5 * time.Millisecond)
+
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+			return next(r)
+		}
+	})
+
+	requests := make([]GetRequest, 8)
+	for i := range requests {
+		requests[i] = GetRequest{Endpoint: NewEndpoint(), Model: &struct{}{}}
+	}
+
+	for _, err := range c.GetConcurrently(context.Background(), 8, requests) {
+		if err != nil {
+			t.Error("Received an error but was not expecting to.", err)
+		}
+	}
+
+	if maxInFlight > 2 {
+		t.Errorf("Expected at most 2 outstanding requests, got %d", maxInFlight)
+	}
+
+	if limiter.InFlight() != 0 {
+		t.Errorf("Expected all slots to be released, got %d in flight", limiter.InFlight())
+	}
+}
+
+func TestConcurrencyLimiterRespectsRequestContext(t *testing.T) {
+	t.Parallel()
+
+	limiter := NewConcurrencyLimiter(1)
+	limiter.Acquire(context.Background())
+
+	caller := &MockAPICaller{}
+	caller.Response = createCallerResponse(http.StatusOK, `{}`)
+
+	c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI}
+	c.Use(limiter.Middleware())
+
+	err := c.Do(http.MethodGet, NewEndpoint(), nil, &struct{}{}, WithTimeout(10*time.Millisecond))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded error, got %v", err)
+	}
+}
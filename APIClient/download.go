@@ -0,0 +1,193 @@
+// Copyright 2018 Publit Sweden AB. All rights reserved.
+
+package APIClient
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// DownloadState is the progress of a resumable download.
+// Persist it between attempts to resume an interrupted download where it stopped.
+type DownloadState struct {
+	// Offset is the amount of bytes downloaded so far.
+	Offset int64 `json:"offset"`
+	// Size is the total size of the asset in bytes. 0 if not known.
+	Size int64 `json:"size"`
+	// Validator is the ETag (or Last-Modified date if the server gives no ETag) of the asset being downloaded.
+	// A download is only resumed if the asset still matches the validator, otherwise it is restarted.
+	Validator string `json:"validator"`
+}
+
+// Done reports if the whole asset has been downloaded.
+func (s *DownloadState) Done() bool {
+	return s.Size > 0 && s.Offset >= s.Size
+}
+
+// DownloadResumable performs a GET request for the part of the asset not yet downloaded according to state,
+// and writes it to w at the offset where it belongs. The state is updated as bytes are written.
+// The remaining part is requested with a Range header, and an If-Range header with the validator of the state, so that the
+// whole asset is sent if it has changed since the download started. In that case the download restarts from offset 0 and
+// w is truncated first if it has a Truncate method (like *os.File). The download also restarts if the server sends
+// another range than the one requested.
+// The RequestOptions are applied like for Do, use eg. WithContext to cancel a long download.
+// The returned DownloadInfo describes the bytes written by this call only.
+func (c *APIClient) DownloadResumable(endpoint Endpointer, w io.WriterAt, state *DownloadState, opts ...RequestOption) (*DownloadInfo, error) {
+	if state.Done() {
+		return &DownloadInfo{Checksum: hex.EncodeToString(sha256.New().Sum(nil))}, nil
+	}
+
+	o := c.newRequestOptions(opts...)
+	ctx, cancel := o.context()
+	defer cancel()
+
+	resp, err := c.requestRemaining(ctx, endpoint, state, o)
+	if err != nil {
+		return nil, err
+	}
+
+	if !continuesDownload(resp, state.Offset) {
+		// The range does not continue the download, restart it.
+		closeBody(resp)
+		state.Offset = 0
+		state.Validator = ""
+		if err := truncate(w); err != nil {
+			return nil, err
+		}
+
+		if resp, err = c.requestRemaining(ctx, endpoint, state, o); err != nil {
+			return nil, err
+		}
+		if !continuesDownload(resp, 0) {
+			closeBody(resp)
+			return nil, fmt.Errorf("Unexpected Content-Range %q of download", resp.Header.Get("Content-Range"))
+		}
+	}
+	if resp.Body != nil {
+		defer resp.Body.Close()
+	}
+
+	if resp.StatusCode == http.StatusOK {
+		// The range was ignored, the whole asset is sent.
+		state.Offset = 0
+		state.Size = resp.ContentLength
+		if err := truncate(w); err != nil {
+			return nil, err
+		}
+	} else if size, ok := contentRangeSize(resp.Header.Get("Content-Range")); ok {
+		state.Size = size
+	}
+
+	if state.Size < 0 {
+		state.Size = 0
+	}
+
+	state.Validator = resp.Header.Get("ETag")
+	if state.Validator == "" {
+		state.Validator = resp.Header.Get("Last-Modified")
+	}
+
+	info := &DownloadInfo{ContentType: resp.Header.Get("Content-Type")}
+	hash := sha256.New()
+
+	if resp.Body != nil {
+		info.ContentLength, err = io.Copy(io.MultiWriter(&stateWriter{w: w, state: state}, hash), resp.Body)
+	}
+	info.Checksum = hex.EncodeToString(hash.Sum(nil))
+
+	return info, err
+}
+
+// requestRemaining performs the GET request for the part of the asset not yet downloaded according to state.
+// The returned response is ok or partial content.
+func (c *APIClient) requestRemaining(ctx context.Context, endpoint Endpointer, state *DownloadState, o *requestOptions) (*http.Response, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, endpoint, nil, o)
+	if err != nil {
+		return nil, err
+	}
+
+	if state.Offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", state.Offset))
+		if state.Validator != "" {
+			req.Header.Set("If-Range", state.Validator)
+		}
+	}
+
+	resp, err := c.call(req)
+	if err != nil {
+		closeBody(resp)
+		return nil, err
+	}
+
+	if err := c.checkResponse(resp, []int{http.StatusOK, http.StatusPartialContent}); err != nil {
+		closeBody(resp)
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// truncate empties w if it has a Truncate method.
+func truncate(w io.WriterAt) error {
+	if t, ok := w.(interface{ Truncate(size int64) error }); ok {
+		return t.Truncate(0)
+	}
+	return nil
+}
+
+// stateWriter writes to w at the offset of the download state and advances it.
+type stateWriter struct {
+	w     io.WriterAt
+	state *DownloadState
+}
+
+func (s *stateWriter) Write(p []byte) (int, error) {
+	n, err := s.w.WriteAt(p, s.state.Offset)
+	s.state.Offset += int64(n)
+	return n, err
+}
+
+// continuesDownload reports if the response is the whole asset, or the range of it starting at offset.
+func continuesDownload(resp *http.Response, offset int64) bool {
+	if resp.StatusCode != http.StatusPartialContent {
+		return true
+	}
+
+	start, ok := contentRangeStart(resp.Header.Get("Content-Range"))
+	return ok && start == offset
+}
+
+// contentRangeStart parses the first byte position from a Content-Range header, e.g. "bytes 100-199/1000".
+func contentRangeStart(v string) (int64, bool) {
+	v = strings.TrimPrefix(v, "bytes ")
+	i := strings.Index(v, "-")
+	if i < 0 {
+		return 0, false
+	}
+
+	start, err := strconv.ParseInt(v[:i], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return start, true
+}
+
+// contentRangeSize parses the complete length from a Content-Range header, e.g. "bytes 100-199/1000".
+func contentRangeSize(v string) (int64, bool) {
+	i := strings.LastIndex(v, "/")
+	if i < 0 {
+		return 0, false
+	}
+
+	size, err := strconv.ParseInt(v[i+1:], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return size, true
+}
@@ -0,0 +1,181 @@
+package APIClient_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/publitsweden/APIUtilityGoSDK/APIClient"
+)
+
+const testAsset = "0123456789"
+
+func TestDownloadResumableResumesFromOffset(t *testing.T) {
+	t.Parallel()
+
+	caller := &MockAPICaller{}
+	caller.T = t
+	caller.CallTestCallback = func(t *testing.T, r *http.Request) {
+		if r.Header.Get("Range") != "bytes=4-" {
+			t.Errorf("Unexpected Range header. Got %q", r.Header.Get("Range"))
+		}
+		if r.Header.Get("If-Range") != `"v1"` {
+			t.Errorf("Unexpected If-Range header. Got %q", r.Header.Get("If-Range"))
+		}
+
+		caller.Response = createCallerResponse(http.StatusPartialContent, testAsset[4:])
+		caller.Response.Header = http.Header{
+			"Content-Range": {"bytes 4-9/10"},
+			"Etag":          {`"v1"`},
+		}
+	}
+
+	c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI}
+
+	f, err := os.Create(filepath.Join(t.TempDir(), "asset"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	f.WriteString(testAsset[:4])
+
+	state := &DownloadState{Offset: 4, Validator: `"v1"`}
+	info, err := c.DownloadResumable(NewEndpoint(), f, state)
+	if err != nil {
+		t.Fatal("Expected DownloadResumable to pass but received error.", err)
+	}
+
+	if info.ContentLength != 6 || !state.Done() {
+		t.Errorf("Unexpected download result. Got %+v and state %+v", info, state)
+	}
+
+	b, _ := ioutil.ReadFile(f.Name())
+	if string(b) != testAsset {
+		t.Errorf("Unexpected file content. Got %s", b)
+	}
+}
+
+func TestDownloadResumableRestartsChangedAsset(t *testing.T) {
+	t.Parallel()
+
+	caller := &MockAPICaller{}
+	caller.Response = createCallerResponse(http.StatusOK, testAsset)
+	caller.Response.ContentLength = int64(len(testAsset))
+	caller.Response.Header = http.Header{"Etag": {`"v2"`}}
+
+	c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI}
+
+	f, err := os.Create(filepath.Join(t.TempDir(), "asset"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	f.WriteString("stale data from an old version")
+
+	state := &DownloadState{Offset: 30, Validator: `"v1"`}
+	if _, err := c.DownloadResumable(NewEndpoint(), f, state); err != nil {
+		t.Fatal("Expected DownloadResumable to pass but received error.", err)
+	}
+
+	if state.Offset != 10 || state.Size != 10 || state.Validator != `"v2"` {
+		t.Errorf("Unexpected state. Got %+v", state)
+	}
+
+	b, _ := ioutil.ReadFile(f.Name())
+	if string(b) != testAsset {
+		t.Errorf("Unexpected file content. Got %s", b)
+	}
+}
+
+func TestDownloadResumableRestartsOnUnexpectedRange(t *testing.T) {
+	t.Parallel()
+
+	caller := &MockAPICaller{}
+	caller.T = t
+	caller.CallTestCallback = func(t *testing.T, r *http.Request) {
+		if r.Header.Get("Range") == "" {
+			caller.Response = createCallerResponse(http.StatusOK, testAsset)
+			caller.Response.ContentLength = int64(len(testAsset))
+			caller.Response.Header = http.Header{"Etag": {`"v1"`}}
+			return
+		}
+
+		// The server sends another range than the requested one.
+		caller.Response = createCallerResponse(http.StatusPartialContent, testAsset[2:])
+		caller.Response.Header = http.Header{"Content-Range": {"bytes 2-9/10"}, "Etag": {`"v1"`}}
+	}
+
+	c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI}
+
+	f, err := os.Create(filepath.Join(t.TempDir(), "asset"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	f.WriteString(testAsset[:4])
+
+	state := &DownloadState{Offset: 4, Validator: `"v1"`}
+	if _, err := c.DownloadResumable(NewEndpoint(), f, state); err != nil {
+		t.Fatal("Expected DownloadResumable to pass but received error.", err)
+	}
+
+	if state.Offset != 10 || !state.Done() {
+		t.Errorf("Unexpected state. Got %+v", state)
+	}
+
+	b, _ := ioutil.ReadFile(f.Name())
+	if string(b) != testAsset {
+		t.Errorf("Unexpected file content. Got %s", b)
+	}
+}
+
+func TestDownloadResumableCanBeCancelled(t *testing.T) {
+	t.Parallel()
+
+	caller := &MockAPICaller{}
+	caller.T = t
+	caller.Response = createCallerResponse(http.StatusOK, testAsset)
+	caller.CallTestCallback = func(t *testing.T, r *http.Request) {
+		if r.Context().Err() == nil {
+			t.Error("Expected the request context to be cancelled.")
+		}
+	}
+
+	c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	c.DownloadResumable(NewEndpoint(), &bytesWriterAt{}, &DownloadState{}, WithContext(ctx))
+}
+
+func TestDownloadResumableSkipsFinishedDownload(t *testing.T) {
+	t.Parallel()
+
+	caller := &MockAPICaller{}
+	caller.T = t
+	caller.CallTestCallback = func(t *testing.T, r *http.Request) {
+		t.Error("Did not expect any request to be sent.")
+	}
+
+	c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI}
+
+	state := &DownloadState{Offset: 10, Size: 10}
+	info, err := c.DownloadResumable(NewEndpoint(), &bytesWriterAt{}, state)
+	if err != nil || info.ContentLength != 0 {
+		t.Errorf("Unexpected result. Got %+v and %v", info, err)
+	}
+}
+
+// bytesWriterAt is an in-memory io.WriterAt appending all writes regardless of offset.
+type bytesWriterAt struct {
+	bytes.Buffer
+}
+
+func (b *bytesWriterAt) WriteAt(p []byte, off int64) (int, error) {
+	return b.Write(p)
+}
@@ -0,0 +1,210 @@
+// Copyright 2018 Publit Sweden AB. All rights reserved.
+
+package APIClient
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/publitsweden/APIUtilityGoSDK/client"
+	"github.com/publitsweden/APIUtilityGoSDK/common"
+)
+
+// Error categories of responses from the Publit APIs.
+// Errors returned by the APIClient can be checked against these with errors.Is.
+// They are the categories of the common package, see common.NewCategoryError.
+var (
+	ErrUnauthorized = common.ErrUnauthorized
+	ErrNotFound     = common.ErrNotFound
+	ErrValidation   = common.ErrValidation
+	ErrRateLimited  = common.ErrRateLimited
+	ErrConflict     = common.ErrConflict
+)
+
+// Category errors of responses from the Publit APIs, retrieved from a ResponseError with errors.As.
+type (
+	NotFoundError   = common.NotFoundError
+	ValidationError = common.ValidationError
+	ConflictError   = common.ConflictError
+	RateLimitError  = common.RateLimitError
+)
+
+// Max amount of bytes of a response body kept in a ResponseError.
+const MAX_ERROR_BODY_SIZE = 1 << 20
+
+// Time waited before retrying a rate limited request without a valid Retry-After header.
+const DEFAULT_RETRY_AFTER = time.Second
+
+// Default max time waited before retrying a rate limited request, see APIClient.MaxRetryAfter.
+const DEFAULT_MAX_RETRY_AFTER = time.Minute
+
+// ResponseError is returned when the Publit API responds with a status that is not accepted.
+// Use errors.As to retrieve it from a returned error.
+type ResponseError struct {
+	// StatusCode is the status code of the response.
+	StatusCode int
+	// Status is the status line of the response, e.g. "400 Bad Request".
+	Status string
+	// Header holds the headers of the response.
+	Header http.Header
+	// Body is the raw response body, at most MAX_ERROR_BODY_SIZE bytes.
+	Body []byte
+	// RequestURL is the URL of the request, if known.
+	RequestURL string
+	// RequestID is the request id of the response, or of the request if the response has none.
+	RequestID string
+	// APIErrorResponse is the error information given by the Publit API. Nil if no information was given.
+	APIErrorResponse *common.APIErrorResponse
+	// RetryAfter is the time the server asked the client to wait before retrying, as given by the Retry-After header.
+	// Zero if the response had no valid Retry-After header.
+	RetryAfter time.Duration
+}
+
+// Error returns the error message of the ResponseError.
+func (e *ResponseError) Error() string {
+	msg := ""
+	switch {
+	case e.APIErrorResponse != nil:
+		msg = e.APIErrorResponse.Error()
+	case e.StatusCode == http.StatusUnauthorized:
+		// Special message for unauthorized reponse.
+		msg = fmt.Sprintf(`Unauthorized. Code: "%v"`, e.StatusCode)
+	default:
+		msg = fmt.Sprintf(`Response not ok. No information given. Code: "%v"`, e.StatusCode)
+	}
+
+	if e.RequestID != "" {
+		msg = fmt.Sprintf(`%s, Request ID: "%v"`, msg, e.RequestID)
+	}
+
+	return msg
+}
+
+// Unwrap returns the category error of the response, such as *NotFoundError, which in turn unwraps to the
+// APIErrorResponse. Both can be retrieved with errors.As. See common.NewCategoryError.
+func (e *ResponseError) Unwrap() error {
+	err := common.NewCategoryError(e.StatusCode, e.APIErrorResponse)
+	if rateLimitErr, ok := err.(*RateLimitError); ok {
+		rateLimitErr.RetryAfter = e.RetryAfter
+	}
+	return err
+}
+
+// Is reports if the ResponseError belongs to the target error category.
+func (e *ResponseError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized, client.ErrAuthenticationFailed:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrValidation:
+		return e.StatusCode == http.StatusBadRequest || e.StatusCode == http.StatusUnprocessableEntity
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	}
+	return false
+}
+
+// TransportError is returned when a request fails without a response from the Publit API, see client.TransportError.
+type TransportError = client.TransportError
+
+// DecodeError is returned when a successful response body could not be decoded into the result.
+type DecodeError struct {
+	// StatusCode and ContentType of the response.
+	StatusCode  int
+	ContentType string
+	// Err is the error of the codec.
+	Err error
+}
+
+// Error returns the error message of the DecodeError.
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf(`Could not decode response. Code: "%v", Content-Type: "%v", Error: "%v"`, e.StatusCode, e.ContentType, e.Err)
+}
+
+// Unwrap returns the error of the codec.
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// MakeResponseError attempts to make a better response error from response.
+// The returned error is a *ResponseError.
+func MakeResponseError(resp *http.Response) error {
+	e := &ResponseError{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Header:     resp.Header,
+	}
+
+	e.RequestID = resp.Header.Get(HEADER_REQUEST_ID)
+
+	if resp.Request != nil && resp.Request.URL != nil {
+		e.RequestURL = resp.Request.URL.String()
+	}
+
+	if resp.Request != nil && e.RequestID == "" {
+		e.RequestID = resp.Request.Header.Get(HEADER_REQUEST_ID)
+	}
+
+	e.RetryAfter, _ = parseRetryAfter(resp.Header, time.Now())
+
+	if resp.Body != nil {
+		// The body is kept even if it could not be read completely.
+		e.Body, _ = ioutil.ReadAll(io.LimitReader(resp.Body, MAX_ERROR_BODY_SIZE))
+	}
+
+	if isJSONMediaType(resp.Header.Get("Content-Type")) {
+		APIErr := &common.APIErrorResponse{}
+		err := json.Unmarshal(e.Body, APIErr)
+		if err == nil && APIErr.HasInformation() { // Only use the API error if it has information.
+			e.APIErrorResponse = APIErr
+		}
+	}
+
+	return e
+}
+
+// isJSONMediaType reports if the Content-Type is json, either application/json or a type with a +json suffix, with any parameters.
+func isJSONMediaType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// parseRetryAfter parses the Retry-After header, given either as delay seconds or as a HTTP-date.
+// Dates in the past give a zero duration. Reports false if the header is missing or invalid.
+func parseRetryAfter(h http.Header, now time.Time) (time.Duration, bool) {
+	v := strings.TrimSpace(h.Get("Retry-After"))
+	if v == "" {
+		return 0, false
+	}
+
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+
+	t, err := http.ParseTime(v)
+	if err != nil {
+		return 0, false
+	}
+
+	if d := t.Sub(now); d > 0 {
+		return d, true
+	}
+	return 0, true
+}
@@ -0,0 +1,283 @@
+package APIClient_test
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	. "github.com/publitsweden/APIUtilityGoSDK/APIClient"
+	"github.com/publitsweden/APIUtilityGoSDK/client"
+	"github.com/publitsweden/APIUtilityGoSDK/common"
+)
+
+func TestResponseErrorCanBeInspected(t *testing.T) {
+	t.Parallel()
+
+	errorMessage := []byte(`{"Code":404,"Type":"NotFound","Errors":[{"Info":"Some error","Type":"NotFound"}],"CombinedInfo":"Some error"}`)
+	u, _ := url.Parse("https://test.publit.com/someapi/v2.0/someendpoint")
+
+	resp := &http.Response{
+		Status:     "404 Not Found",
+		StatusCode: http.StatusNotFound,
+		Header: http.Header{
+			"Content-Type": []string{"application/json"},
+		},
+		Body:    ioutil.NopCloser(bytes.NewBuffer(errorMessage)),
+		Request: &http.Request{URL: u},
+	}
+
+	err := MakeResponseError(resp)
+
+	var respErr *ResponseError
+	if !errors.As(err, &respErr) {
+		t.Fatal("Expected error to be a *ResponseError but was not.")
+	}
+
+	if respErr.StatusCode != http.StatusNotFound {
+		t.Errorf("Unexpected status code. Expected %d, got %d", http.StatusNotFound, respErr.StatusCode)
+	}
+
+	if respErr.RequestURL != u.String() {
+		t.Errorf("Unexpected request URL. Expected %s, got %s", u.String(), respErr.RequestURL)
+	}
+
+	if respErr.APIErrorResponse == nil || respErr.APIErrorResponse.Type != "NotFound" {
+		t.Error("Expected API error response to be parsed but was not.")
+	}
+
+	var APIErr *common.APIErrorResponse
+	if !errors.As(err, &APIErr) || APIErr != respErr.APIErrorResponse {
+		t.Error("Expected API error response to be retrievable with errors.As but was not.")
+	}
+
+	if !bytes.Equal(respErr.Body, errorMessage) {
+		t.Errorf("Unexpected body. Expected %s, got %s", errorMessage, respErr.Body)
+	}
+
+	if respErr.Header.Get("Content-Type") != "application/json" {
+		t.Error("Expected response headers to be kept but were not.")
+	}
+
+	if respErr.Status != resp.Status {
+		t.Errorf("Unexpected status. Expected %s, got %s", resp.Status, respErr.Status)
+	}
+}
+
+func TestResponseErrorKeepsBodyWithoutAPIErrorInformation(t *testing.T) {
+	t.Parallel()
+
+	caller := &MockAPICaller{}
+	caller.Response = createCallerResponse(http.StatusBadGateway, "<html>Bad gateway</html>")
+
+	c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI}
+	err := c.Get(NewEndpoint(), &struct{}{})
+
+	var respErr *ResponseError
+	if !errors.As(err, &respErr) {
+		t.Fatal("Expected error to be a *ResponseError but was not.")
+	}
+
+	if string(respErr.Body) != "<html>Bad gateway</html>" {
+		t.Errorf("Unexpected body. Got %s", respErr.Body)
+	}
+}
+
+func TestResponseErrorCategories(t *testing.T) {
+	t.Parallel()
+
+	table := []struct {
+		StatusCode int
+		Category   error
+	}{
+		{http.StatusUnauthorized, ErrUnauthorized},
+		{http.StatusNotFound, ErrNotFound},
+		{http.StatusBadRequest, ErrValidation},
+		{http.StatusUnprocessableEntity, ErrValidation},
+		{http.StatusTooManyRequests, ErrRateLimited},
+		{http.StatusConflict, ErrConflict},
+	}
+
+	categories := []error{ErrUnauthorized, ErrNotFound, ErrValidation, ErrRateLimited, ErrConflict}
+
+	for _, v := range table {
+		caller := &MockAPICaller{}
+		caller.Response = createCallerResponse(v.StatusCode, "")
+
+		c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI}
+		err := c.Get(NewEndpoint(), &struct{}{})
+
+		for _, category := range categories {
+			if errors.Is(err, category) != (category == v.Category) {
+				t.Errorf("Unexpected category match of %v for status %d.", category, v.StatusCode)
+			}
+		}
+
+		if errors.Is(err, client.ErrAuthenticationFailed) != (v.StatusCode == http.StatusUnauthorized) {
+			t.Errorf("Unexpected authentication failure match for status %d.", v.StatusCode)
+		}
+	}
+}
+
+func TestResponseErrorUnwrapsToCategoryError(t *testing.T) {
+	t.Parallel()
+
+	resp := createCallerResponse(http.StatusNotFound, `{"Code":404,"Type":"NotFound","CombinedInfo":"No such book"}`)
+	resp.Header = http.Header{"Content-Type": {"application/json"}}
+	err := MakeResponseError(resp)
+
+	var notFoundErr *NotFoundError
+	if !errors.As(err, &notFoundErr) || notFoundErr.Response == nil || notFoundErr.Response.CombinedInfo != "No such book" {
+		t.Errorf("Expected a NotFoundError with the API error response, got %v", err)
+	}
+
+	resp = createCallerResponse(http.StatusTooManyRequests, "")
+	resp.Header = http.Header{"Retry-After": {"30"}}
+	err = MakeResponseError(resp)
+
+	var rateLimitErr *RateLimitError
+	if !errors.As(err, &rateLimitErr) || rateLimitErr.RetryAfter != 30*time.Second || rateLimitErr.Response != nil {
+		t.Errorf("Expected a RateLimitError retrying after 30s, got %v", err)
+	}
+
+	var APIErr *common.APIErrorResponse
+	if errors.As(err, &APIErr) {
+		t.Error("Did not expect an API error response without information.")
+	}
+
+	if errors.Unwrap(MakeResponseError(createCallerResponse(http.StatusInternalServerError, ""))) != nil {
+		t.Error("Did not expect a category error of an internal server error.")
+	}
+}
+
+func TestResponseErrorExposesRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	table := []struct {
+		RetryAfter string
+		Min        time.Duration
+		Max        time.Duration
+	}{
+		{"120", 120 * time.Second, 120 * time.Second},
+		{time.Now().Add(time.Hour).UTC().Format(http.TimeFormat), 59 * time.Minute, time.Hour},
+		{"Wed, 21 Oct 2015 07:28:00 GMT", 0, 0},
+		{"invalid", 0, 0},
+	}
+
+	for _, v := range table {
+		resp := createCallerResponse(http.StatusTooManyRequests, "")
+		resp.Header = http.Header{"Retry-After": {v.RetryAfter}}
+
+		var respErr *ResponseError
+		if !errors.As(MakeResponseError(resp), &respErr) {
+			t.Fatal("Expected a ResponseError.")
+		}
+
+		if respErr.RetryAfter < v.Min || respErr.RetryAfter > v.Max {
+			t.Errorf("Unexpected RetryAfter for %q. Got %v", v.RetryAfter, respErr.RetryAfter)
+		}
+	}
+}
+
+func TestResponseErrorParsesJSONMediaTypes(t *testing.T) {
+	t.Parallel()
+
+	errorMessage := `{"Code":400,"Type":"Validation","CombinedInfo":"Some error"}`
+
+	table := map[string]bool{
+		"application/json":                true,
+		"application/json; charset=utf-8": true,
+		"Application/JSON":                true,
+		"application/problem+json":        true,
+		"text/plain":                      false,
+		"application/jsonp":               false,
+		"invalid;;":                       false,
+	}
+
+	for contentType, parsed := range table {
+		resp := createCallerResponse(http.StatusBadRequest, errorMessage)
+		resp.Header = http.Header{"Content-Type": {contentType}}
+
+		var respErr *ResponseError
+		if !errors.As(MakeResponseError(resp), &respErr) {
+			t.Fatal("Expected a ResponseError.")
+		}
+
+		if (respErr.APIErrorResponse != nil) != parsed {
+			t.Errorf("Unexpected parsing of error body with Content-Type %q.", contentType)
+		}
+	}
+}
+
+func TestErrorsAreClassified(t *testing.T) {
+	t.Parallel()
+
+	t.Run(
+		"Transport errors",
+		func(t *testing.T) {
+			caller := &MockAPICaller{}
+			caller.ReturnErrors = true
+
+			c := &APIClient{Client: caller, BaseURL: "https://test.publit.com", API: TestAPI}
+			err := c.Get(NewEndpoint(), &struct{}{})
+
+			var transportErr *TransportError
+			if !errors.As(err, &transportErr) {
+				t.Fatalf("Expected a TransportError, got %v", err)
+			}
+
+			if transportErr.Method != http.MethodGet || transportErr.URL != "https://test.publit.com/someapi/v2.0/someendpoint" {
+				t.Errorf("Unexpected request of TransportError. Got %s %s", transportErr.Method, transportErr.URL)
+			}
+		},
+	)
+
+	t.Run(
+		"Missing responses",
+		func(t *testing.T) {
+			c := &APIClient{Client: &MockAPICaller{}, BaseURL: "somebaseurl", API: TestAPI}
+			err := c.Get(NewEndpoint(), &struct{}{})
+
+			var transportErr *TransportError
+			if !errors.As(err, &transportErr) {
+				t.Errorf("Expected a TransportError, got %v", err)
+			}
+		},
+	)
+
+	t.Run(
+		"Decode errors",
+		func(t *testing.T) {
+			caller := &MockAPICaller{}
+			caller.Response = createCallerResponse(http.StatusOK, "not json")
+
+			c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI}
+			err := c.Get(NewEndpoint(), &struct{}{})
+
+			var decodeErr *DecodeError
+			if !errors.As(err, &decodeErr) || decodeErr.StatusCode != http.StatusOK {
+				t.Errorf("Expected a DecodeError, got %v", err)
+			}
+		},
+	)
+
+	t.Run(
+		"API errors",
+		func(t *testing.T) {
+			caller := &MockAPICaller{}
+			caller.Response = createCallerResponse(http.StatusBadRequest, "")
+
+			c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI}
+			err := c.Get(NewEndpoint(), &struct{}{})
+
+			var respErr *ResponseError
+			var transportErr *TransportError
+			if !errors.As(err, &respErr) || errors.As(err, &transportErr) {
+				t.Errorf("Expected only a ResponseError, got %v", err)
+			}
+		},
+	)
+}
@@ -0,0 +1,141 @@
+// Copyright 2018 Publit Sweden AB. All rights reserved.
+
+package APIClient
+
+import (
+	"bytes"
+	"container/list"
+	"io/ioutil"
+	"net/http"
+	"sync"
+)
+
+// ETagCache is a cache of GET responses indexed like ResponseCache and validated with ETags.
+// Add it to an APIClient with APIClient.Use(cache.Middleware()). Like a ResponseCache it must not be shared between
+// APIClients.
+// Once a response with an ETag has been cached, following GET requests with the same key are sent with If-None-Match
+// and a 304 Not Modified response is replaced with the cached response, so the cached body is decoded as usual.
+// The least recently used entry is evicted when the cache is full.
+type ETagCache struct {
+	// MaxEntries is the max amount of cached responses. Defaults to DEFAULT_CACHE_SIZE.
+	MaxEntries int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+}
+
+// etagEntry is a cached response.
+type etagEntry struct {
+	key    string
+	etag   string
+	header http.Header
+	body   []byte
+}
+
+// NewETagCache creates a new empty ETagCache.
+func NewETagCache() *ETagCache {
+	return &ETagCache{entries: map[string]*list.Element{}, lru: list.New()}
+}
+
+// Middleware returns the middleware performing conditional GET requests against the cache.
+func (e *ETagCache) Middleware() Middleware {
+	return func(next CallFunc) CallFunc {
+		return func(r *http.Request) (*http.Response, error) {
+			if r.Method != http.MethodGet || r.Header.Get("If-None-Match") != "" {
+				return next(r)
+			}
+
+			key := cacheKey(r)
+			entry, cached := e.get(key)
+			if cached {
+				r.Header.Set("If-None-Match", entry.etag)
+			}
+
+			resp, err := next(r)
+			if err != nil || resp == nil {
+				return resp, err
+			}
+
+			if cached && resp.StatusCode == http.StatusNotModified {
+				if resp.Body != nil {
+					resp.Body.Close()
+				}
+				return cachedResponse(r, entry.header, entry.body), nil
+			}
+
+			etag := resp.Header.Get("ETag")
+			if resp.StatusCode != http.StatusOK || etag == "" || resp.Body == nil {
+				return resp, err
+			}
+
+			body, err := ioutil.ReadAll(resp.Body)
+			resp.Body.Close()
+			resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+			if err != nil {
+				return resp, err
+			}
+
+			e.set(&etagEntry{key: key, etag: etag, header: resp.Header.Clone(), body: body})
+
+			return resp, nil
+		}
+	}
+}
+
+// Len returns the amount of cached responses.
+func (e *ETagCache) Len() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return len(e.entries)
+}
+
+// Clear removes all cached responses.
+func (e *ETagCache) Clear() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.entries = map[string]*list.Element{}
+	e.lru = list.New()
+}
+
+func (e *ETagCache) get(key string) (*etagEntry, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	el, ok := e.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	e.lru.MoveToFront(el)
+	return el.Value.(*etagEntry), true
+}
+
+func (e *ETagCache) set(entry *etagEntry) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.entries == nil {
+		e.entries = map[string]*list.Element{}
+		e.lru = list.New()
+	}
+
+	if el, ok := e.entries[entry.key]; ok {
+		el.Value = entry
+		e.lru.MoveToFront(el)
+		return
+	}
+
+	e.entries[entry.key] = e.lru.PushFront(entry)
+
+	max := e.MaxEntries
+	if max <= 0 {
+		max = DEFAULT_CACHE_SIZE
+	}
+
+	for e.lru.Len() > max {
+		oldest := e.lru.Back()
+		e.lru.Remove(oldest)
+		delete(e.entries, oldest.Value.(*etagEntry).key)
+	}
+}
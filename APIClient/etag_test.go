@@ -0,0 +1,101 @@
+package APIClient_test
+
+import (
+	"net/http"
+	"testing"
+
+	. "github.com/publitsweden/APIUtilityGoSDK/APIClient"
+	"github.com/publitsweden/APIUtilityGoSDK/common"
+)
+
+func TestETagCacheServesCachedBodyOnNotModified(t *testing.T) {
+	t.Parallel()
+
+	caller := &MockAPICaller{}
+	caller.T = t
+	ifNoneMatch := []string{}
+	caller.CallTestCallback = func(t *testing.T, r *http.Request) {
+		ifNoneMatch = append(ifNoneMatch, r.Header.Get("If-None-Match"))
+	}
+
+	c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI}
+	c.Use(NewETagCache().Middleware())
+
+	caller.Response = createCallerResponse(http.StatusOK, `{"some":"body"}`)
+	caller.Response.Header = http.Header{"Etag": []string{`"v1"`}}
+
+	model := &struct {
+		Some string `json:"some"`
+	}{}
+	if err := c.Get(NewEndpoint(), model); err != nil {
+		t.Error("Expected Get to pass but received error.", err)
+	}
+
+	caller.Response = createCallerResponse(http.StatusNotModified, "")
+
+	cachedModel := &struct {
+		Some string `json:"some"`
+	}{}
+	if err := c.Get(NewEndpoint(), cachedModel); err != nil {
+		t.Error("Expected Get to pass but received error.", err)
+	}
+
+	if cachedModel.Some != "body" {
+		t.Error("Expected cached body to be decoded but was not.")
+	}
+
+	if len(ifNoneMatch) != 2 || ifNoneMatch[0] != "" || ifNoneMatch[1] != `"v1"` {
+		t.Errorf("Unexpected If-None-Match headers sent. Got %q", ifNoneMatch)
+	}
+}
+
+func TestETagCacheIgnoresOtherMethods(t *testing.T) {
+	t.Parallel()
+
+	caller := &MockAPICaller{}
+	caller.T = t
+	caller.CallTestCallback = func(t *testing.T, r *http.Request) {
+		if r.Header.Get("If-None-Match") != "" {
+			t.Error("Did not expect If-None-Match header on non GET request.")
+		}
+	}
+
+	c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI}
+	c.Use(NewETagCache().Middleware())
+
+	for i := 0; i < 2; i++ {
+		caller.Response = createCallerResponse(http.StatusOK, `{}`)
+		caller.Response.Header = http.Header{"Etag": []string{`"v1"`}}
+
+		if err := c.Post(NewEndpoint(), &struct{}{}, &struct{}{}); err != nil {
+			t.Error("Received an error but was not expecting to.", err)
+		}
+	}
+}
+
+func TestETagCacheIsBounded(t *testing.T) {
+	t.Parallel()
+
+	caller := &MockAPICaller{}
+	caller.T = t
+	caller.CallTestCallback = func(t *testing.T, r *http.Request) {
+		caller.Response = createCallerResponse(http.StatusOK, `{}`)
+		caller.Response.Header = http.Header{"Etag": []string{`"v1"`}}
+	}
+
+	cache := NewETagCache()
+	cache.MaxEntries = 2
+
+	c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI}
+	c.Use(cache.Middleware())
+
+	for i := 0; i < 5; i++ {
+		if err := c.Get(NewEndpoint(), &struct{}{}, common.QueryLimit(i+1, 0)); err != nil {
+			t.Error("Expected Get to pass but received error.", err)
+		}
+	}
+
+	if cache.Len() != 2 {
+		t.Errorf("Expected 2 cached responses, got %d", cache.Len())
+	}
+}
@@ -0,0 +1,32 @@
+// Copyright 2018 Publit Sweden AB. All rights reserved.
+
+package APIClient
+
+import "net/http"
+
+// Get performs a GET request against the endpoint and returns the response decoded into a new T.
+func Get[T any](c *APIClient, endpoint Endpointer, opts ...RequestOption) (T, error) {
+	return do[T](c, http.MethodGet, endpoint, nil, opts...)
+}
+
+// Post performs a POST request against the endpoint and returns the response decoded into a new T.
+func Post[T any](c *APIClient, endpoint Endpointer, payload interface{}, opts ...RequestOption) (T, error) {
+	return do[T](c, http.MethodPost, endpoint, payload, opts...)
+}
+
+// Put performs a PUT request against the endpoint and returns the response decoded into a new T.
+func Put[T any](c *APIClient, endpoint Endpointer, payload interface{}, opts ...RequestOption) (T, error) {
+	return do[T](c, http.MethodPut, endpoint, payload, opts...)
+}
+
+// Delete performs a DELETE request against the endpoint and returns the response decoded into a new T.
+func Delete[T any](c *APIClient, endpoint Endpointer, opts ...RequestOption) (T, error) {
+	return do[T](c, http.MethodDelete, endpoint, nil, opts...)
+}
+
+// do performs the request and decodes the response into a new T.
+func do[T any](c *APIClient, method string, endpoint Endpointer, payload interface{}, opts ...RequestOption) (T, error) {
+	var model T
+	err := c.Do(method, endpoint, payload, &model, opts...)
+	return model, err
+}
@@ -0,0 +1,69 @@
+package APIClient_test
+
+import (
+	"net/http"
+	"testing"
+
+	. "github.com/publitsweden/APIUtilityGoSDK/APIClient"
+)
+
+type TestModel struct {
+	Name string `json:"name"`
+}
+
+func TestCanGetTypedModel(t *testing.T) {
+	t.Parallel()
+
+	caller := &MockAPICaller{}
+	caller.Response = createCallerResponse(http.StatusOK, `{"name":"some name"}`)
+
+	c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI}
+
+	model, err := Get[TestModel](c, NewEndpoint())
+
+	if err != nil {
+		t.Error("Expected Get to pass but received error.", err)
+	}
+
+	if model.Name != "some name" {
+		t.Error("Decoded model did not match expected.")
+	}
+}
+
+func TestCanPostTypedModel(t *testing.T) {
+	t.Parallel()
+
+	caller := &MockAPICaller{}
+	caller.T = t
+	caller.Response = createCallerResponse(http.StatusCreated, `[{"name":"new name"}]`)
+	caller.CallTestCallback = func(t *testing.T, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("Unexpected method. Expected %s, got %s", http.MethodPost, r.Method)
+		}
+	}
+
+	c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI}
+
+	models, err := Post[[]TestModel](c, NewEndpoint(), TestModel{Name: "name"}, WithAcceptedStatuses(http.StatusCreated))
+
+	if err != nil {
+		t.Error("Expected Post to pass but received error.", err)
+	}
+
+	if len(models) != 1 || models[0].Name != "new name" {
+		t.Errorf("Decoded models did not match expected. Got %+v", models)
+	}
+}
+
+func TestTypedHelpersReturnErrors(t *testing.T) {
+	t.Parallel()
+
+	caller := &MockAPICaller{}
+	caller.Response = createCallerResponse(http.StatusNotFound, "")
+
+	c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI}
+
+	if _, err := Delete[TestModel](c, NewEndpoint()); err == nil {
+		t.Error("Expected an error due to status not ok but did not receive one.")
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright 2018 Publit Sweden AB. All rights reserved.
+
+package APIClient
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/publitsweden/APIUtilityGoSDK/common"
+)
+
+// ListResult is the envelope of index responses from the Publit APIs, holding a page of records and pagination information.
+// It has the fields of common.ListResponse, and the two types convert to each other.
+type ListResult[T any] common.ListResponse[T]
+
+// HasMore reports if there are more records after this page.
+func (l ListResult[T]) HasMore() bool {
+	return common.ListResponse[T](l).HasMore()
+}
+
+// NextOffset returns the offset of the page following this one.
+func (l ListResult[T]) NextOffset() int {
+	return common.ListResponse[T](l).NextOffset()
+}
+
+// List performs a GET request against an index endpoint and decodes the response envelope into a ListResult.
+// Offset and Limit of the result are taken from the limit query parameter of the request, set e.g. with common.QueryLimit.
+func List[T any](c *APIClient, endpoint Endpointer, opts ...RequestOption) (ListResult[T], error) {
+	result, err := do[ListResult[T]](c, http.MethodGet, endpoint, nil, opts...)
+	if err != nil {
+		return result, err
+	}
+
+	q := url.Values{}
+	for _, v := range c.newRequestOptions(opts...).query {
+		v(q)
+	}
+	result.Offset, result.Limit = parseLimit(q.Get(common.QUERY_KEY_LIMIT))
+
+	return result, nil
+}
+
+// parseLimit parses the "offset,limit" value of the limit query parameter.
+// Zero values are returned for missing or invalid parts.
+func parseLimit(v string) (offset, limit int) {
+	parts := strings.SplitN(v, ",", 2)
+	if len(parts) != 2 {
+		return 0, 0
+	}
+
+	offset, _ = strconv.Atoi(strings.TrimSpace(parts[0]))
+	limit, _ = strconv.Atoi(strings.TrimSpace(parts[1]))
+	return offset, limit
+}
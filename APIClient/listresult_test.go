@@ -0,0 +1,57 @@
+package APIClient_test
+
+import (
+	"net/http"
+	"testing"
+
+	. "github.com/publitsweden/APIUtilityGoSDK/APIClient"
+	"github.com/publitsweden/APIUtilityGoSDK/common"
+)
+
+func TestCanListTypedRecords(t *testing.T) {
+	t.Parallel()
+
+	caller := &MockAPICaller{}
+	caller.Response = createCallerResponse(http.StatusOK, `{"data":[{"name":"first"},{"name":"second"}],"count":5}`)
+
+	c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI}
+
+	list, err := List[TestModel](c, NewEndpoint(), WithQuery(common.QueryLimit(2, 2)))
+
+	if err != nil {
+		t.Fatal("Expected List to pass but received error.", err)
+	}
+
+	if len(list.Data) != 2 || list.Data[1].Name != "second" {
+		t.Errorf("Unexpected records. Got %+v", list.Data)
+	}
+
+	if list.Count != 5 || list.Offset != 2 || list.Limit != 2 {
+		t.Errorf("Unexpected pagination. Got count %d, offset %d, limit %d", list.Count, list.Offset, list.Limit)
+	}
+
+	if !list.HasMore() || list.NextOffset() != 4 {
+		t.Error("Expected list to have more records from offset 4.")
+	}
+}
+
+func TestListResultWithoutMoreRecords(t *testing.T) {
+	t.Parallel()
+
+	list := ListResult[TestModel]{Data: []TestModel{{Name: "last"}}, Count: 5, Offset: 4, Limit: 2}
+
+	if list.HasMore() {
+		t.Error("Did not expect list to have more records.")
+	}
+}
+
+func TestListResultConvertsToCommonListResponse(t *testing.T) {
+	t.Parallel()
+
+	list := ListResult[TestModel]{Data: []TestModel{{Name: "first"}}, Count: 3, Offset: 0, Limit: 1}
+	response := common.ListResponse[TestModel](list)
+
+	if response.Count != 3 || !response.HasMore() || response.NextOffset() != 1 {
+		t.Errorf("Unexpected list response %+v", response)
+	}
+}
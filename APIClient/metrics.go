@@ -0,0 +1,61 @@
+// Copyright 2018 Publit Sweden AB. All rights reserved.
+
+package APIClient
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// CallMetrics describes a call made by the APIClient.
+type CallMetrics struct {
+	// Method is the http method of the request.
+	Method string
+	// Endpoint is the endpoint of the request as given by the Endpointer, or the URL path if the request was not made against an Endpointer.
+	Endpoint string
+	// StatusCode is the status code of the response. 0 if no response was received.
+	StatusCode int
+	// Retries is the amount of times the request was retried by the APIClient.
+	Retries int
+	// Duration is the time spent on the call, including retries.
+	Duration time.Duration
+	// Err is the error of the call, if any. Responses with error statuses are not errors at this level.
+	Err error
+}
+
+// Observer is invoked after every call made by the APIClient, e.g. for feeding a metrics system.
+type Observer interface {
+	Observe(m CallMetrics)
+}
+
+// ObserverFunc is an adapter allowing an ordinary function to be used as an Observer.
+type ObserverFunc func(m CallMetrics)
+
+// Observe calls f(m).
+func (f ObserverFunc) Observe(m CallMetrics) {
+	f(m)
+}
+
+// WithObserver sets the Observer of the APIClient.
+func WithObserver(observer Observer) Option {
+	return func(c *APIClient) {
+		c.Observer = observer
+	}
+}
+
+// endpointKey is the context key holding the endpoint of a request.
+type endpointKey struct{}
+
+// withEndpoint returns a context holding the endpoint.
+func withEndpoint(ctx context.Context, endpoint string) context.Context {
+	return context.WithValue(ctx, endpointKey{}, endpoint)
+}
+
+// requestEndpoint returns the endpoint of the request, or the URL path if it has none.
+func requestEndpoint(r *http.Request) string {
+	if e, ok := r.Context().Value(endpointKey{}).(string); ok {
+		return e
+	}
+	return r.URL.Path
+}
@@ -0,0 +1,59 @@
+package APIClient_test
+
+import (
+	"net/http"
+	"testing"
+
+	. "github.com/publitsweden/APIUtilityGoSDK/APIClient"
+)
+
+func TestObserverIsNotifiedAfterEveryCall(t *testing.T) {
+	t.Parallel()
+
+	observed := []CallMetrics{}
+	caller := &MockAPICaller{}
+
+	c, _ := NewAPIClient(
+		"https://test.publit.com",
+		TestAPI,
+		WithCaller(caller),
+		WithObserver(ObserverFunc(func(m CallMetrics) {
+			observed = append(observed, m)
+		})),
+	)
+
+	caller.Response = createCallerResponse(http.StatusOK, `{}`)
+	c.Get(NewEndpoint(), &struct{}{})
+
+	caller.Response = createCallerResponse(http.StatusNotFound, "")
+	c.Delete(NewEndpoint(), &struct{}{})
+
+	caller.Response = createCallerResponse(http.StatusBadRequest, "")
+	caller.ReturnErrors = true
+	c.Post(NewEndpoint(), &struct{}{}, &struct{}{})
+
+	if len(observed) != 3 {
+		t.Fatalf("Expected 3 observed calls, got %d", len(observed))
+	}
+
+	expected := []struct {
+		Method     string
+		StatusCode int
+		HasErr     bool
+	}{
+		{http.MethodGet, http.StatusOK, false},
+		{http.MethodDelete, http.StatusNotFound, false},
+		{http.MethodPost, http.StatusBadRequest, true},
+	}
+
+	for i, e := range expected {
+		m := observed[i]
+		if m.Method != e.Method || m.StatusCode != e.StatusCode || (m.Err != nil) != e.HasErr {
+			t.Errorf("Unexpected metrics for call %d. Got %+v", i, m)
+		}
+
+		if m.Endpoint != "someendpoint" {
+			t.Errorf("Unexpected endpoint. Expected someendpoint, got %s", m.Endpoint)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright 2018 Publit Sweden AB. All rights reserved.
+
+package APIClient
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// RequestOption configures a single request made by the APIClient.
+// Request options are accepted by APIClient.Do for all http methods.
+type RequestOption func(o *requestOptions)
+
+// requestOptions holds the configuration of a single request.
+type requestOptions struct {
+	query            []func(q url.Values)
+	headers          []func(h *http.Header)
+	ctx              context.Context
+	accountID        int
+	timeout          time.Duration
+	acceptedStatuses []int
+	codec            Codec
+	version          string
+	success          func(resp *http.Response) bool
+}
+
+// newRequestOptions creates request options from the APIClient defaults and the given options.
+func (c *APIClient) newRequestOptions(opts ...RequestOption) *requestOptions {
+	o := &requestOptions{
+		acceptedStatuses: c.AcceptedStatuses,
+		codec:            c.Codec,
+		version:          c.version(),
+	}
+
+	for _, v := range opts {
+		v(o)
+	}
+
+	if o.codec == nil {
+		o.codec = JSONCodec
+	}
+
+	return o
+}
+
+// succeeded reports if the response is successful, by the success predicate if set and the accepted statuses otherwise.
+func (o *requestOptions) succeeded(resp *http.Response) bool {
+	if o.success != nil {
+		return o.success(resp)
+	}
+	return isAccepted(resp.StatusCode, o.acceptedStatuses)
+}
+
+// context returns the request context with the timeout applied.
+// The returned cancel func must always be called when the request is done.
+func (o *requestOptions) context() (context.Context, context.CancelFunc) {
+	ctx := o.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if o.timeout > 0 {
+		return context.WithTimeout(ctx, o.timeout)
+	}
+
+	return context.WithCancel(ctx)
+}
+
+// WithQuery adds query param funcs (such as the ones in the common package) to the request.
+func WithQuery(queryParams ...func(q url.Values)) RequestOption {
+	return func(o *requestOptions) {
+		o.query = append(o.query, queryParams...)
+	}
+}
+
+// WithHeaders adds header funcs to the request.
+func WithHeaders(headers ...func(h *http.Header)) RequestOption {
+	return func(o *requestOptions) {
+		o.headers = append(o.headers, headers...)
+	}
+}
+
+// WithHeader sets a header on the request.
+func WithHeader(key, value string) RequestOption {
+	return WithHeaders(func(h *http.Header) {
+		h.Set(key, value)
+	})
+}
+
+// WithContext sets the context of the request.
+func WithContext(ctx context.Context) RequestOption {
+	return func(o *requestOptions) {
+		o.ctx = ctx
+	}
+}
+
+// WithAccountID makes the request act for the given Publit account instead of the AccountID of the client.Client,
+// see client.ContextWithAccountID.
+func WithAccountID(accountID int) RequestOption {
+	return func(o *requestOptions) {
+		o.accountID = accountID
+	}
+}
+
+// WithTimeout sets a timeout for the whole request, including reading the response body.
+func WithTimeout(timeout time.Duration) RequestOption {
+	return func(o *requestOptions) {
+		o.timeout = timeout
+	}
+}
+
+// WithAcceptedStatuses overrides APIClient.AcceptedStatuses for the request.
+func WithAcceptedStatuses(codes ...int) RequestOption {
+	return func(o *requestOptions) {
+		o.acceptedStatuses = codes
+	}
+}
+
+// WithSuccess sets a predicate deciding if the response of the request is successful, replacing the accepted statuses.
+// Useful for endpoints with unusual semantics, e.g. treating 409 Conflict as "already exists".
+// Unsuccessful responses are returned as a *ResponseError, successful responses are decoded unless they have no content.
+func WithSuccess(success func(resp *http.Response) bool) RequestOption {
+	return func(o *requestOptions) {
+		o.success = success
+	}
+}
+
+// WithCodec overrides APIClient.Codec for the request.
+func WithCodec(codec Codec) RequestOption {
+	return func(o *requestOptions) {
+		o.codec = codec
+	}
+}
+
+// WithRequestAPIVersion overrides APIClient.Version for the request, e.g. for calling a single v3 endpoint.
+func WithRequestAPIVersion(version string) RequestOption {
+	return func(o *requestOptions) {
+		o.version = version
+	}
+}
+
+// WithLocale sets the locale of the request, e.g. "sv_SE", as the Accept-Language header read by localized metadata
+// endpoints, see LocaleHeader. Store and price endpoints instead read the locale query param set by common.QueryLocale.
+// Use the one documented by the endpoint.
+func WithLocale(locale string) RequestOption {
+	return WithHeaders(LocaleHeader(locale))
+}
+
+// LocaleHeader returns a header func setting the Accept-Language header to the locale.
+// Locales in "sv_SE" format are converted to language tags ("sv-SE"). Usable with Get, Post, Put and Delete header funcs.
+func LocaleHeader(locale string) func(h *http.Header) {
+	tag := strings.ReplaceAll(locale, "_", "-")
+	return func(h *http.Header) {
+		h.Set("Accept-Language", tag)
+	}
+}
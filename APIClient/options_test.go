@@ -0,0 +1,218 @@
+package APIClient_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/publitsweden/APIUtilityGoSDK/APIClient"
+	"github.com/publitsweden/APIUtilityGoSDK/client"
+	"github.com/publitsweden/APIUtilityGoSDK/common"
+)
+
+func TestDoAcceptsRequestOptions(t *testing.T) {
+	t.Parallel()
+
+	type ctxKey string
+
+	caller := &MockAPICaller{}
+	caller.T = t
+	caller.Response = createCallerResponse(http.StatusOK, `{"name":"newTestName"}`)
+	caller.CallTestCallback = func(t *testing.T, r *http.Request) {
+		if r.Method != http.MethodPut {
+			t.Errorf("Unexpected method. Expected %s, got %s", http.MethodPut, r.Method)
+		}
+
+		if r.URL.Query().Get(common.QUERY_KEY_LIMIT) != "0,1" {
+			t.Error("Expected query param to be set but was not.")
+		}
+
+		if r.Header.Get("X-Some-Header") != "value" {
+			t.Error("Expected header to be set but was not.")
+		}
+
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Error("Expected json content type to be set but was not.")
+		}
+
+		if r.Context().Value(ctxKey("key")) != "value" {
+			t.Error("Expected request to carry the given context but it did not.")
+		}
+
+		if _, ok := r.Context().Deadline(); !ok {
+			t.Error("Expected request context to have a deadline but it did not.")
+		}
+	}
+
+	c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI}
+
+	i := struct {
+		Name string `json:"name"`
+	}{Name: "test"}
+
+	err := c.Do(
+		http.MethodPut,
+		NewEndpoint(),
+		&i,
+		&i,
+		WithQuery(common.QueryLimit(1, 0)),
+		WithHeader("X-Some-Header", "value"),
+		WithContext(context.WithValue(context.Background(), ctxKey("key"), "value")),
+		WithTimeout(time.Minute),
+	)
+
+	if err != nil {
+		t.Error("Received an error but was not expecting to.", err)
+	}
+
+	if i.Name != "newTestName" {
+		t.Error("Struct did not have expected value.")
+	}
+}
+
+func TestDoAcceptsStatusesPerCall(t *testing.T) {
+	t.Parallel()
+
+	caller := &MockAPICaller{}
+	caller.Response = createCallerResponse(http.StatusCreated, `{}`)
+
+	c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI}
+
+	model := &struct{}{}
+	if err := c.Do(http.MethodPost, NewEndpoint(), model, model); err == nil {
+		t.Error("Expected an error due to status not ok but did not receive one.")
+	}
+
+	caller.Response = createCallerResponse(http.StatusCreated, `{}`)
+	if err := c.Do(http.MethodPost, NewEndpoint(), model, model, WithAcceptedStatuses(http.StatusCreated)); err != nil {
+		t.Error("Received an error but was not expecting to.", err)
+	}
+}
+
+func TestCanBuildRequestWithoutSendingIt(t *testing.T) {
+	t.Parallel()
+
+	caller := &MockAPICaller{}
+	caller.T = t
+	caller.CallTestCallback = func(t *testing.T, r *http.Request) {
+		t.Error("Did not expect any request to be sent.")
+	}
+
+	c := &APIClient{Client: caller, BaseURL: "https://test.publit.com", API: TestAPI}
+
+	payload := struct {
+		Name string `json:"name"`
+	}{Name: "test"}
+
+	req, err := c.BuildRequest(
+		http.MethodPost,
+		NewEndpoint(),
+		&payload,
+		WithQuery(common.QueryLimit(1, 0)),
+		WithHeader("X-Some-Header", "value"),
+	)
+
+	if err != nil {
+		t.Fatal("Received an error but was not expecting to.", err)
+	}
+
+	expectedURL := "https://test.publit.com/someapi/v2.0/someendpoint?limit=0%2C1"
+	if req.URL.String() != expectedURL {
+		t.Errorf("Unexpected URL. Expected %s, got %s", expectedURL, req.URL.String())
+	}
+
+	if req.Method != http.MethodPost {
+		t.Errorf("Unexpected method. Got %s", req.Method)
+	}
+
+	if req.Header.Get("X-Some-Header") != "value" || req.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("Unexpected headers. Got %v", req.Header)
+	}
+
+	if req.Header.Get("Authorization") != "" {
+		t.Error("Did not expect request to be authenticated.")
+	}
+
+	b, _ := ioutil.ReadAll(req.Body)
+	if string(b) != `{"name":"test"}` {
+		t.Errorf("Unexpected body. Got %s", b)
+	}
+}
+
+func TestAPIVersionCanBeSetPerClientAndRequest(t *testing.T) {
+	t.Parallel()
+
+	c, err := NewAPIClient("https://test.publit.com", TestAPI, WithCaller(&MockAPICaller{}), WithAPIVersion("v3.0"))
+	if err != nil {
+		t.Fatal("Received an error but was not expecting to.", err)
+	}
+
+	req, _ := c.BuildRequest(http.MethodGet, NewEndpoint(), nil)
+	if req.URL.String() != "https://test.publit.com/someapi/v3.0/someendpoint" {
+		t.Errorf("Unexpected URL. Got %s", req.URL.String())
+	}
+
+	req, _ = c.BuildRequest(http.MethodGet, NewEndpoint(), nil, WithRequestAPIVersion("v2.0"))
+	if req.URL.String() != "https://test.publit.com/someapi/v2.0/someendpoint" {
+		t.Errorf("Unexpected URL. Got %s", req.URL.String())
+	}
+}
+
+func TestDoAcceptsSuccessPredicatePerCall(t *testing.T) {
+	t.Parallel()
+
+	caller := &MockAPICaller{}
+	c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI}
+
+	alreadyExists := WithSuccess(func(resp *http.Response) bool {
+		return resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusConflict
+	})
+
+	caller.Response = createCallerResponse(http.StatusConflict, `{"name":"existing"}`)
+	model := &struct {
+		Name string `json:"name"`
+	}{}
+	if err := c.Do(http.MethodPost, NewEndpoint(), model, model, alreadyExists); err != nil || model.Name != "existing" {
+		t.Errorf("Expected conflict to be successful. Got %+v, %v", model, err)
+	}
+
+	caller.Response = createCallerResponse(http.StatusAccepted, "")
+	caller.Response.Body = ioutil.NopCloser(strings.NewReader(""))
+	accepted := WithSuccess(func(resp *http.Response) bool { return resp.StatusCode == http.StatusAccepted })
+	if err := c.Do(http.MethodPost, NewEndpoint(), model, model, accepted); err != nil {
+		t.Error("Expected accepted response with empty body to be successful.", err)
+	}
+
+	caller.Response = createCallerResponse(http.StatusOK, `{}`)
+	if err := c.Do(http.MethodPost, NewEndpoint(), model, model, accepted); err == nil {
+		t.Error("Expected an error due to the predicate but did not receive one.")
+	}
+}
+
+func TestLocaleCanBeSetPerRequest(t *testing.T) {
+	t.Parallel()
+
+	c := &APIClient{BaseURL: "https://test.publit.com", API: TestAPI}
+
+	req, _ := c.BuildRequest(http.MethodGet, NewEndpoint(), nil, WithLocale("sv_SE"))
+	if req.Header.Get("Accept-Language") != "sv-SE" {
+		t.Errorf("Unexpected Accept-Language header. Got %q", req.Header.Get("Accept-Language"))
+	}
+}
+
+func TestWithAccountIDSetsAccountOfRequest(t *testing.T) {
+	t.Parallel()
+	c := &APIClient{BaseURL: "https://api.publit.test", API: "someapi"}
+
+	r, err := c.BuildRequest(http.MethodGet, NewEndpoint(), nil, WithAccountID(12))
+	if err != nil {
+		t.Fatalf("Received an error but did not expect one: %v", err)
+	}
+
+	if id, ok := client.AccountIDFromContext(r.Context()); !ok || id != 12 {
+		t.Errorf("Expected account id 12 in request context, got %v", id)
+	}
+}
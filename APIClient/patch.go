@@ -0,0 +1,30 @@
+// Copyright 2018 Publit Sweden AB. All rights reserved.
+
+package APIClient
+
+import (
+	"io"
+	"net/http"
+)
+
+// MergePatchCodec encodes payloads as JSON merge patches (RFC 7396). Responses are decoded as json.
+// Payloads are encoded as json as is, so attributes are only left out of a struct payload by its json tags, eg. with
+// omitempty on pointer fields. A nil value in a map payload removes the attribute.
+var MergePatchCodec Codec = mergePatchCodec{}
+
+type mergePatchCodec struct{}
+
+func (mergePatchCodec) ContentType() string { return "application/merge-patch+json" }
+
+func (mergePatchCodec) Encode(w io.Writer, v interface{}) error { return JSONCodec.Encode(w, v) }
+
+func (mergePatchCodec) Decode(r io.Reader, v interface{}) error { return JSONCodec.Decode(r, v) }
+
+// PatchMerge performs a PATCH request with the patch encoded as a JSON merge patch, see MergePatchCodec.
+// Attributes left out of the patch are not changed, so use omitempty pointer fields in a struct patch to only send the
+// set attributes, or a map[string]interface{} patch. A nil value in a map patch removes the attribute.
+// The response body is decoded into result as json.
+func (c *APIClient) PatchMerge(endpoint Endpointer, patch interface{}, result interface{}, opts ...RequestOption) error {
+	opts = append([]RequestOption{WithCodec(MergePatchCodec), WithHeader("Accept", JSONCodec.ContentType())}, opts...)
+	return c.Do(http.MethodPatch, endpoint, patch, result, opts...)
+}
@@ -0,0 +1,65 @@
+package APIClient_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	. "github.com/publitsweden/APIUtilityGoSDK/APIClient"
+)
+
+type PatchModel struct {
+	Name      *string `json:"name,omitempty"`
+	Pages     *int    `json:"pages,omitempty"`
+	Published *bool   `json:"published,omitempty"`
+}
+
+func TestPatchMergeSendsPatchAsIs(t *testing.T) {
+	t.Parallel()
+
+	pages := 0
+	table := []struct {
+		Patch    interface{}
+		Expected string
+	}{
+		{&PatchModel{Pages: &pages}, `{"pages":0}`},
+		{&struct {
+			Name  string `json:"name"`
+			Pages int    `json:"pages"`
+		}{}, `{"name":"","pages":0}`},
+		{map[string]interface{}{"name": nil, "pages": 0}, `{"name":null,"pages":0}`},
+	}
+
+	for _, v := range table {
+		caller := &MockAPICaller{}
+		caller.T = t
+		caller.Response = createCallerResponse(http.StatusOK, `{"name":"test","pages":10}`)
+
+		expected := v.Expected
+		caller.CallTestCallback = func(t *testing.T, r *http.Request) {
+			if r.Method != http.MethodPatch {
+				t.Errorf("Unexpected method. Got %s", r.Method)
+			}
+
+			if r.Header.Get("Content-Type") != "application/merge-patch+json" || r.Header.Get("Accept") != "application/json" {
+				t.Errorf("Unexpected headers. Got %v", r.Header)
+			}
+
+			b, _ := ioutil.ReadAll(r.Body)
+			if string(b) != expected {
+				t.Errorf("Unexpected body. Expected %s, got %s", expected, b)
+			}
+		}
+
+		c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI}
+
+		result := &PatchModel{}
+		if err := c.PatchMerge(NewEndpoint(), v.Patch, result); err != nil {
+			t.Error("Received an error but was not expecting to.", err)
+		}
+
+		if result.Name == nil || *result.Name != "test" {
+			t.Error("Expected response to be decoded but was not.")
+		}
+	}
+}
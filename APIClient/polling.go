@@ -0,0 +1,47 @@
+// Copyright 2018 Publit Sweden AB. All rights reserved.
+
+package APIClient
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/publitsweden/APIUtilityGoSDK/client"
+)
+
+// Backoff returns the time to wait before the given attempt, starting at attempt 1.
+type Backoff = client.Backoff
+
+// ConstantBackoff waits the same duration before every attempt.
+func ConstantBackoff(d time.Duration) Backoff {
+	return client.ConstantBackoff(d)
+}
+
+// ExponentialBackoff doubles the wait for every attempt, starting at initial and capped at max.
+func ExponentialBackoff(initial, max time.Duration) Backoff {
+	return client.ExponentialBackoff(initial, max)
+}
+
+// WaitFor polls the endpoint with GET requests until isDone reports that the decoded response is in a terminal state,
+// and returns that response. Used for endpoints returning a job reference that has to be polled until completion.
+// Waits according to backoff between polls. Polling stops with the context error when ctx is done, and with the error
+// of the request if a poll fails.
+func WaitFor[T any](ctx context.Context, c *APIClient, endpoint Endpointer, isDone func(model T) bool, backoff Backoff, opts ...RequestOption) (T, error) {
+	opts = append(opts[:len(opts):len(opts)], WithContext(ctx))
+
+	for attempt := 1; ; attempt++ {
+		model, err := do[T](c, http.MethodGet, endpoint, nil, opts...)
+		if err != nil || isDone(model) {
+			return model, err
+		}
+
+		t := time.NewTimer(backoff(attempt))
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return model, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package APIClient_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	. "github.com/publitsweden/APIUtilityGoSDK/APIClient"
+)
+
+type TestJob struct {
+	State string `json:"state"`
+}
+
+func TestWaitForPollsUntilDone(t *testing.T) {
+	t.Parallel()
+
+	polls := 0
+	caller := &MockAPICaller{}
+	caller.T = t
+	caller.CallTestCallback = func(t *testing.T, r *http.Request) {
+		polls++
+		state := "pending"
+		if polls == 3 {
+			state = "done"
+		}
+		caller.Response = createCallerResponse(http.StatusOK, fmt.Sprintf(`{"state":"%s"}`, state))
+	}
+
+	c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI}
+
+	job, err := WaitFor(
+		context.Background(),
+		c,
+		NewEndpoint(),
+		func(j TestJob) bool { return j.State == "done" },
+		ConstantBackoff(time.Millisecond),
+	)
+
+	if err != nil {
+		t.Error("Expected WaitFor to pass but received error.", err)
+	}
+
+	if job.State != "done" || polls != 3 {
+		t.Errorf("Expected 3 polls ending in done state, got %d polls and state %s", polls, job.State)
+	}
+}
+
+func TestWaitForStopsWhenContextIsDone(t *testing.T) {
+	t.Parallel()
+
+	caller := &MockAPICaller{}
+	caller.T = t
+	caller.CallTestCallback = func(t *testing.T, r *http.Request) {
+		caller.Response = createCallerResponse(http.StatusOK, `{"state":"pending"}`)
+	}
+
+	c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := WaitFor(ctx, c, NewEndpoint(), func(j TestJob) bool { return false }, ConstantBackoff(5*time.Millisecond))
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded error, got %v", err)
+	}
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	t.Parallel()
+
+	b := ExponentialBackoff(10*time.Millisecond, 50*time.Millisecond)
+
+	expected := []time.Duration{10, 20, 40, 50, 50}
+	for i, e := range expected {
+		if d := b(i + 1); d != e*time.Millisecond {
+			t.Errorf("Unexpected backoff for attempt %d. Expected %v, got %v", i+1, e*time.Millisecond, d)
+		}
+	}
+}
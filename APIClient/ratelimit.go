@@ -0,0 +1,54 @@
+// Copyright 2018 Publit Sweden AB. All rights reserved.
+
+package APIClient
+
+import (
+	"net/http"
+
+	"github.com/publitsweden/APIUtilityGoSDK/client"
+)
+
+// RateLimiter is a token bucket rate limiter also following the X-RateLimit headers of responses, see
+// client.RateLimiter. Add it to an APIClient with APIClient.Use(RateLimitMiddleware(limiter)) or with the WithRateLimit
+// option, or to a client.Client with client.WithRateLimiter.
+// Requests blocked by the limiter wait until they are allowed or their context is done.
+type RateLimiter = client.RateLimiter
+
+// NewRateLimiter creates a RateLimiter allowing requestsPerSecond requests per second on average, with bursts of at most burst requests.
+// A burst lower than 1 is set to 1. A requestsPerSecond of 0 or lower, or NaN, is clamped to 0, which only limits
+// requests according to the rate limit headers of responses.
+func NewRateLimiter(requestsPerSecond float64, burst int) *RateLimiter {
+	return client.NewRateLimiter(requestsPerSecond, burst)
+}
+
+// WithRateLimit adds a RateLimiter to the APIClient.
+func WithRateLimit(requestsPerSecond float64, burst int) Option {
+	return func(c *APIClient) {
+		c.Use(RateLimitMiddleware(NewRateLimiter(requestsPerSecond, burst)))
+	}
+}
+
+// WithRateLimitRetries sets the max amount of retries of rate limited requests, see APIClient.RateLimitRetries.
+func WithRateLimitRetries(retries int) Option {
+	return func(c *APIClient) {
+		c.RateLimitRetries = retries
+	}
+}
+
+// RateLimitMiddleware returns the middleware applying the rate limit of the limiter to requests, and updating it from
+// the rate limit headers of responses.
+func RateLimitMiddleware(l *RateLimiter) Middleware {
+	return func(next CallFunc) CallFunc {
+		return func(r *http.Request) (*http.Response, error) {
+			if err := l.Wait(r.Context()); err != nil {
+				return nil, err
+			}
+
+			resp, err := next(r)
+			if resp != nil {
+				l.Update(resp)
+			}
+			return resp, err
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package APIClient_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	. "github.com/publitsweden/APIUtilityGoSDK/APIClient"
+)
+
+func TestRateLimiterLimitsRequests(t *testing.T) {
+	t.Parallel()
+
+	c, _ := NewAPIClient("https://test.publit.com", TestAPI, WithCaller(&ConcurrentMockAPICaller{}), WithRateLimit(50, 1))
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := c.Get(NewEndpoint(), &struct{}{}); err != nil {
+			t.Error("Expected Get to pass but received error.", err)
+		}
+	}
+
+	// The first request uses the burst, the following two wait 20ms each.
+	if elapsed := time.Since(start); elapsed < 35*time.Millisecond {
+		t.Errorf("Expected requests to be rate limited, but took only %v", elapsed)
+	}
+}
+
+func TestRateLimiterRespectsRequestContext(t *testing.T) {
+	t.Parallel()
+
+	l := NewRateLimiter(0.1, 1)
+	c := &APIClient{Client: &ConcurrentMockAPICaller{}, BaseURL: "somebaseurl", API: TestAPI}
+	c.Use(RateLimitMiddleware(l))
+
+	if err := c.Get(NewEndpoint(), &struct{}{}); err != nil {
+		t.Error("Expected Get to pass but received error.", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := c.Do(http.MethodGet, NewEndpoint(), nil, &struct{}{}, WithContext(ctx))
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded error, got %v", err)
+	}
+}
+
+func TestRateLimitedRequestsAreRetried(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	caller := &MockAPICaller{}
+	caller.T = t
+	caller.CallTestCallback = func(t *testing.T, r *http.Request) {
+		calls++
+		b, _ := ioutil.ReadAll(r.Body)
+		if string(b) != `{"name":"test"}` {
+			t.Errorf("Expected body to be resent. Got %s", b)
+		}
+
+		caller.Response = createCallerResponse(http.StatusOK, `{}`)
+		if calls == 1 {
+			caller.Response = createCallerResponse(http.StatusTooManyRequests, "")
+			caller.Response.Header = http.Header{"Retry-After": {"0"}}
+		}
+	}
+
+	var retries int
+	c := &APIClient{
+		Client:           caller,
+		BaseURL:          "somebaseurl",
+		API:              TestAPI,
+		RateLimitRetries: 2,
+		Observer:         ObserverFunc(func(m CallMetrics) { retries = m.Retries }),
+	}
+
+	payload := struct {
+		Name string `json:"name"`
+	}{Name: "test"}
+
+	if err := c.Post(NewEndpoint(), &payload, &struct{}{}); err != nil {
+		t.Error("Received an error but was not expecting to.", err)
+	}
+
+	if calls != 2 || retries != 1 {
+		t.Errorf("Expected 2 calls and 1 retry, got %d calls and %d retries", calls, retries)
+	}
+}
+
+func TestRateLimitedRequestsAreNotRetriedByDefault(t *testing.T) {
+	t.Parallel()
+
+	caller := &MockAPICaller{}
+	caller.Response = createCallerResponse(http.StatusTooManyRequests, "")
+	caller.Response.Header = http.Header{"Retry-After": {"30"}}
+
+	c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI}
+
+	err := c.Get(NewEndpoint(), &struct{}{})
+
+	var respErr *ResponseError
+	if !errors.As(err, &respErr) || !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("Expected a rate limited ResponseError, got %v", err)
+	}
+
+	if respErr.RetryAfter != 30*time.Second {
+		t.Errorf("Unexpected RetryAfter. Got %v", respErr.RetryAfter)
+	}
+}
+
+func TestRateLimitedRequestsAreNotRetriedBeyondMaxRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	caller := &MockAPICaller{}
+	caller.T = t
+	caller.CallTestCallback = func(t *testing.T, r *http.Request) {
+		calls++
+		caller.Response = createCallerResponse(http.StatusTooManyRequests, "")
+		caller.Response.Header = http.Header{"Retry-After": {"3600"}}
+	}
+
+	c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI, RateLimitRetries: 2, MaxRetryAfter: time.Second}
+
+	start := time.Now()
+	err := c.Get(NewEndpoint(), &struct{}{})
+
+	var respErr *ResponseError
+	if !errors.As(err, &respErr) || !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("Expected a rate limited ResponseError, got %v", err)
+	}
+
+	if calls != 1 || time.Since(start) > time.Second || respErr.RetryAfter != time.Hour {
+		t.Errorf("Expected no retry. Got %d calls after %v, RetryAfter %v", calls, time.Since(start), respErr.RetryAfter)
+	}
+}
+
+func TestRateLimiterWithoutRateDoesNotLimit(t *testing.T) {
+	t.Parallel()
+
+	for _, rps := range []float64{0, -1} {
+		l := NewRateLimiter(rps, 1)
+		for i := 0; i < 3; i++ {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			err := l.Wait(ctx)
+			cancel()
+			if err != nil {
+				t.Errorf("Expected rate %v not to limit requests, got %v", rps, err)
+			}
+		}
+	}
+}
+
+func TestRateLimitMiddlewareFollowsRateLimitHeaders(t *testing.T) {
+	t.Parallel()
+
+	caller := &MockAPICaller{}
+	caller.T = t
+	caller.CallTestCallback = func(t *testing.T, r *http.Request) {
+		caller.Response = createCallerResponse(http.StatusOK, `{}`)
+		caller.Response.Header = http.Header{"X-Ratelimit-Remaining": {"0"}, "X-Ratelimit-Reset": {"60"}}
+	}
+
+	c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI}
+	c.Use(RateLimitMiddleware(NewRateLimiter(0, 1)))
+
+	if err := c.Get(NewEndpoint(), &struct{}{}); err != nil {
+		t.Fatal("Expected Get to pass but received error.", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := c.Do(http.MethodGet, NewEndpoint(), nil, &struct{}{}, WithContext(ctx)); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected request to wait for the rate limit reset, got %v", err)
+	}
+}
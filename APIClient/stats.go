@@ -0,0 +1,133 @@
+// Copyright 2018 Publit Sweden AB. All rights reserved.
+
+package APIClient
+
+import (
+	"sort"
+	"time"
+)
+
+// Default max amount of latencies kept per endpoint for computing percentiles, see APIClient.StatsSampleSize.
+const DEFAULT_STATS_SAMPLE_SIZE = 1000
+
+// Default max amount of endpoints with call statistics of their own, see APIClient.StatsMaxEndpoints.
+const DEFAULT_STATS_MAX_ENDPOINTS = 100
+
+// Endpoint of the call statistics of the calls against endpoints beyond APIClient.StatsMaxEndpoints.
+const STATS_OTHER_ENDPOINT = "*"
+
+// CallStats are aggregate statistics of the calls made against an endpoint.
+type CallStats struct {
+	// Endpoint is the endpoint of the calls, see CallMetrics.Endpoint.
+	Endpoint string
+	// Count is the amount of calls.
+	Count int
+	// Errors is the amount of calls failing with an error or an error status (400 or above).
+	Errors int
+	// ErrorRate is Errors divided by Count.
+	ErrorRate float64
+	// P50 and P95 are the median and 95th percentile latencies of the most recent calls.
+	P50 time.Duration
+	P95 time.Duration
+}
+
+// endpointStats holds the recorded calls of an endpoint.
+type endpointStats struct {
+	count     int
+	errors    int
+	latencies []time.Duration
+	next      int
+}
+
+// add records a call, keeping at most sampleSize latencies. The oldest latencies are dropped first.
+func (s *endpointStats) add(latency time.Duration, failed bool, sampleSize int) {
+	s.count++
+	if failed {
+		s.errors++
+	}
+
+	if len(s.latencies) < sampleSize {
+		s.latencies = append(s.latencies, latency)
+		return
+	}
+	s.next %= len(s.latencies)
+	s.latencies[s.next] = latency
+	s.next = (s.next + 1) % len(s.latencies)
+}
+
+// addCallStats records a call in the statistics of its endpoint.
+// Once StatsMaxEndpoints endpoints are recorded, calls against other endpoints are recorded under STATS_OTHER_ENDPOINT,
+// so endpoints with ids in them do not grow the statistics without bound.
+func (c *APIClient) addCallStats(endpoint string, latency time.Duration, failed bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.stats == nil {
+		c.stats = map[string]*endpointStats{}
+	}
+
+	maxEndpoints := c.StatsMaxEndpoints
+	if maxEndpoints <= 0 {
+		maxEndpoints = DEFAULT_STATS_MAX_ENDPOINTS
+	}
+	sampleSize := c.StatsSampleSize
+	if sampleSize <= 0 {
+		sampleSize = DEFAULT_STATS_SAMPLE_SIZE
+	}
+
+	s, ok := c.stats[endpoint]
+	if !ok && len(c.stats) >= maxEndpoints {
+		endpoint = STATS_OTHER_ENDPOINT
+		s, ok = c.stats[endpoint]
+	}
+	if !ok {
+		s = &endpointStats{}
+		c.stats[endpoint] = s
+	}
+	s.add(latency, failed, sampleSize)
+}
+
+// GetCallStats returns statistics of the calls made by the APIClient, indexed by endpoint.
+// Calls against endpoints beyond StatsMaxEndpoints are indexed by STATS_OTHER_ENDPOINT.
+func (c *APIClient) GetCallStats() map[string]CallStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := make(map[string]CallStats, len(c.stats))
+	for endpoint, s := range c.stats {
+		latencies := make([]time.Duration, len(s.latencies))
+		copy(latencies, s.latencies)
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+		stats[endpoint] = CallStats{
+			Endpoint:  endpoint,
+			Count:     s.count,
+			Errors:    s.errors,
+			ErrorRate: float64(s.errors) / float64(s.count),
+			P50:       percentile(latencies, 50),
+			P95:       percentile(latencies, 95),
+		}
+	}
+
+	return stats
+}
+
+// ResetCallStats removes all recorded call statistics.
+func (c *APIClient) ResetCallStats() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stats = nil
+}
+
+// percentile returns the nearest-rank percentile of the sorted latencies.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
@@ -0,0 +1,82 @@
+package APIClient_test
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	. "github.com/publitsweden/APIUtilityGoSDK/APIClient"
+)
+
+func TestCallStatsAreRecordedPerEndpoint(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	caller := &MockAPICaller{}
+	caller.T = t
+	caller.CallTestCallback = func(t *testing.T, r *http.Request) {
+		calls++
+		caller.Response = createCallerResponse(http.StatusOK, `{}`)
+		if calls%4 == 0 {
+			caller.Response = createCallerResponse(http.StatusInternalServerError, "")
+		}
+	}
+
+	c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI}
+	c.Use(func(next CallFunc) CallFunc {
+		return func(r *http.Request) (*http.Response, error) {
+			time.Sleep(time.Millisecond)
+			return next(r)
+		}
+	})
+
+	for i := 0; i < 8; i++ {
+		c.Get(NewEndpoint(), &struct{}{})
+	}
+
+	stats, ok := c.GetCallStats()["someendpoint"]
+	if !ok {
+		t.Fatal("Expected stats for endpoint.")
+	}
+
+	if stats.Count != 8 || stats.Errors != 2 || stats.ErrorRate != 0.25 {
+		t.Errorf("Unexpected stats. Got %+v", stats)
+	}
+
+	if stats.P50 < time.Millisecond || stats.P95 < stats.P50 {
+		t.Errorf("Unexpected latency percentiles. Got p50 %v, p95 %v", stats.P50, stats.P95)
+	}
+
+	c.ResetCallStats()
+	if len(c.GetCallStats()) != 0 {
+		t.Error("Expected stats to be reset.")
+	}
+}
+
+func TestCallStatsAreBounded(t *testing.T) {
+	t.Parallel()
+
+	caller := &MockAPICaller{}
+	caller.T = t
+	caller.Response = createCallerResponse(http.StatusOK, `{}`)
+
+	c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI, StatsMaxEndpoints: 2, StatsSampleSize: 3}
+
+	for i := 0; i < 5; i++ {
+		c.Get(pathEndpoint("books/"+strconv.Itoa(i)), &struct{}{})
+	}
+
+	stats := c.GetCallStats()
+	if len(stats) != 3 {
+		t.Fatalf("Expected stats of 2 endpoints and the other endpoints. Got %+v", stats)
+	}
+	if other := stats[STATS_OTHER_ENDPOINT]; other.Count != 3 {
+		t.Errorf("Expected 3 calls recorded under %q. Got %+v", STATS_OTHER_ENDPOINT, other)
+	}
+}
+
+// pathEndpoint is an Endpointer of the endpoint path.
+type pathEndpoint string
+
+func (e pathEndpoint) GetEndpoint() (string, error) { return string(e), nil }
@@ -0,0 +1,74 @@
+// Copyright 2018 Publit Sweden AB. All rights reserved.
+
+package APIClient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// Span attribute keys set by the Tracing middleware.
+const (
+	SPAN_ATTR_METHOD      = "http.method"
+	SPAN_ATTR_URL         = "http.url"
+	SPAN_ATTR_STATUS_CODE = "http.status_code"
+	SPAN_ATTR_ENDPOINT    = "publit.endpoint"
+)
+
+// Tracer starts spans for calls made by the APIClient.
+// It mirrors the parts of OpenTelemetry's trace.Tracer used by the APIClient, so an OpenTelemetry tracer can be plugged in
+// with a thin adapter while keeping the dependency optional for users not using tracing.
+type Tracer interface {
+	// Start starts a span and returns a context holding it.
+	Start(ctx context.Context, spanName string) (context.Context, Span)
+}
+
+// Span is a single traced operation started by a Tracer.
+type Span interface {
+	SetAttribute(key string, value interface{})
+	RecordError(err error)
+	End()
+}
+
+// TracePropagator injects the trace context held by ctx into the headers of an outgoing request.
+// With OpenTelemetry this is typically otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(h)).
+type TracePropagator func(ctx context.Context, h http.Header)
+
+// Tracing returns a middleware creating a span for every request, with method, URL, endpoint and status code attributes.
+// If propagate is not nil it is used for propagating the trace context to the Publit API through the request headers.
+func Tracing(tracer Tracer, propagate TracePropagator) Middleware {
+	return func(next CallFunc) CallFunc {
+		return func(r *http.Request) (*http.Response, error) {
+			endpoint := requestEndpoint(r)
+			ctx, span := tracer.Start(r.Context(), fmt.Sprintf("Publit %s %s", r.Method, endpoint))
+			defer span.End()
+
+			span.SetAttribute(SPAN_ATTR_METHOD, r.Method)
+			span.SetAttribute(SPAN_ATTR_URL, r.URL.String())
+			span.SetAttribute(SPAN_ATTR_ENDPOINT, endpoint)
+
+			r = r.WithContext(ctx)
+			if propagate != nil {
+				propagate(ctx, r.Header)
+			}
+
+			resp, err := next(r)
+			if err != nil {
+				span.RecordError(err)
+			}
+			if resp != nil {
+				span.SetAttribute(SPAN_ATTR_STATUS_CODE, resp.StatusCode)
+			}
+
+			return resp, err
+		}
+	}
+}
+
+// WithTracer adds the Tracing middleware to the APIClient.
+func WithTracer(tracer Tracer, propagate TracePropagator) Option {
+	return func(c *APIClient) {
+		c.Use(Tracing(tracer, propagate))
+	}
+}
@@ -0,0 +1,83 @@
+package APIClient_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	. "github.com/publitsweden/APIUtilityGoSDK/APIClient"
+)
+
+func TestTracingCreatesSpanPerCall(t *testing.T) {
+	t.Parallel()
+
+	tracer := &MockTracer{}
+	caller := &MockAPICaller{}
+	caller.T = t
+	caller.Response = createCallerResponse(http.StatusOK, `{}`)
+	caller.CallTestCallback = func(t *testing.T, r *http.Request) {
+		if r.Header.Get("traceparent") != "sometraceparent" {
+			t.Error("Expected trace header to be propagated but was not.")
+		}
+
+		if r.Context().Value(spanKey{}) == nil {
+			t.Error("Expected request context to hold the span but it did not.")
+		}
+	}
+
+	c, _ := NewAPIClient(
+		"https://test.publit.com",
+		TestAPI,
+		WithCaller(caller),
+		WithTracer(tracer, func(ctx context.Context, h http.Header) {
+			h.Set("traceparent", "sometraceparent")
+		}),
+	)
+
+	if err := c.Get(NewEndpoint(), &struct{}{}); err != nil {
+		t.Error("Expected Get to pass but received error.", err)
+	}
+
+	if len(tracer.Spans) != 1 {
+		t.Fatalf("Expected exactly 1 span, got %d", len(tracer.Spans))
+	}
+
+	span := tracer.Spans[0]
+
+	if span.Name != "Publit GET someendpoint" {
+		t.Errorf("Unexpected span name. Got %s", span.Name)
+	}
+
+	if !span.Ended {
+		t.Error("Expected span to be ended but was not.")
+	}
+
+	if span.Attributes[SPAN_ATTR_STATUS_CODE] != http.StatusOK || span.Attributes[SPAN_ATTR_ENDPOINT] != "someendpoint" {
+		t.Errorf("Unexpected span attributes. Got %v", span.Attributes)
+	}
+}
+
+type spanKey struct{}
+
+type MockTracer struct {
+	Spans []*MockSpan
+}
+
+func (m *MockTracer) Start(ctx context.Context, spanName string) (context.Context, Span) {
+	s := &MockSpan{Name: spanName, Attributes: map[string]interface{}{}}
+	m.Spans = append(m.Spans, s)
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+type MockSpan struct {
+	Name       string
+	Attributes map[string]interface{}
+	Errors     []error
+	Ended      bool
+}
+
+func (s *MockSpan) SetAttribute(key string, value interface{}) { s.Attributes[key] = value }
+
+func (s *MockSpan) RecordError(err error) { s.Errors = append(s.Errors, err) }
+
+func (s *MockSpan) End() { s.Ended = true }
@@ -0,0 +1,70 @@
+// Copyright 2018 Publit Sweden AB. All rights reserved.
+
+package APIClient
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// Upsert creates a resource with a POST against the create endpoint, and updates the existing resource with a PUT
+// against the update endpoint if the resource already exists.
+// The resource is considered existing if the POST fails with 409 Conflict, or with a validation error of a duplicate type.
+// Reports if the resource was created. The options are applied to both requests.
+// An io.Reader payload is read into memory, so it can be sent by both requests.
+func (c *APIClient) Upsert(create Endpointer, update Endpointer, payload interface{}, result interface{}, opts ...RequestOption) (bool, error) {
+	var body []byte
+	if r, ok := payload.(io.Reader); ok {
+		b, err := ioutil.ReadAll(r)
+		if err != nil {
+			return false, err
+		}
+		body = b
+		payload = bytes.NewReader(body)
+	}
+
+	err := c.Do(http.MethodPost, create, payload, result, opts...)
+	if err == nil {
+		return true, nil
+	}
+
+	if !isDuplicateError(err) {
+		return false, err
+	}
+
+	if body != nil {
+		payload = bytes.NewReader(body)
+	}
+
+	return false, c.Do(http.MethodPut, update, payload, result, opts...)
+}
+
+// isDuplicateError reports if the error is a conflict, or a validation error with a duplicate error type.
+func isDuplicateError(err error) bool {
+	if errors.Is(err, ErrConflict) {
+		return true
+	}
+
+	var respErr *ResponseError
+	if !errors.As(err, &respErr) || !errors.Is(err, ErrValidation) || respErr.APIErrorResponse == nil {
+		return false
+	}
+
+	if isDuplicateType(respErr.APIErrorResponse.Type) {
+		return true
+	}
+	for _, v := range respErr.APIErrorResponse.Errors {
+		if v != nil && isDuplicateType(v.Type) {
+			return true
+		}
+	}
+	return false
+}
+
+func isDuplicateType(t string) bool {
+	return strings.Contains(strings.ToLower(t), "duplicate")
+}
@@ -0,0 +1,88 @@
+package APIClient_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	. "github.com/publitsweden/APIUtilityGoSDK/APIClient"
+)
+
+func TestUpsert(t *testing.T) {
+	t.Parallel()
+
+	table := map[string]struct {
+		CreateStatus int
+		CreateBody   string
+		Created      bool
+		Methods      []string
+		Fails        bool
+	}{
+		"Creates new resource": {http.StatusOK, `{}`, true, []string{http.MethodPost}, false},
+		"Updates on conflict":  {http.StatusConflict, "", false, []string{http.MethodPost, http.MethodPut}, false},
+		"Updates on duplicate": {
+			http.StatusUnprocessableEntity,
+			`{"Code":422,"Type":"ValidationError","errors":[{"Info":"isbn taken","Type":"Duplicate"}],"CombinedInfo":"isbn taken"}`,
+			false,
+			[]string{http.MethodPost, http.MethodPut},
+			false,
+		},
+		"Fails on other errors": {http.StatusBadRequest, "", false, []string{http.MethodPost}, true},
+	}
+
+	for name, v := range table {
+		v := v
+		var methods []string
+
+		caller := &MockAPICaller{}
+		caller.T = t
+		caller.CallTestCallback = func(t *testing.T, r *http.Request) {
+			methods = append(methods, r.Method)
+			caller.Response = createCallerResponse(http.StatusOK, `{}`)
+			if r.Method == http.MethodPost {
+				caller.Response = createCallerResponse(v.CreateStatus, v.CreateBody)
+				caller.Response.Header = http.Header{"Content-Type": {"application/json"}}
+			}
+		}
+
+		c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI}
+
+		created, err := c.Upsert(NewEndpoint(), NewEndpoint(), &struct{}{}, &struct{}{})
+
+		if (err != nil) != v.Fails || created != v.Created {
+			t.Errorf("%s: Unexpected result. Got created %v, error %v", name, created, err)
+		}
+
+		if len(methods) != len(v.Methods) || methods[len(methods)-1] != v.Methods[len(v.Methods)-1] {
+			t.Errorf("%s: Unexpected requests. Expected %v, got %v", name, v.Methods, methods)
+		}
+	}
+}
+
+func TestUpsertResendsReaderPayload(t *testing.T) {
+	t.Parallel()
+
+	var bodies []string
+
+	caller := &MockAPICaller{}
+	caller.T = t
+	caller.CallTestCallback = func(t *testing.T, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+		caller.Response = createCallerResponse(http.StatusOK, `{}`)
+		if r.Method == http.MethodPost {
+			caller.Response = createCallerResponse(http.StatusConflict, "")
+		}
+	}
+
+	c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI}
+
+	if _, err := c.Upsert(NewEndpoint(), NewEndpoint(), strings.NewReader(`{"isbn":"123"}`), &struct{}{}); err != nil {
+		t.Fatalf("Received an error but did not expect one: %v", err)
+	}
+
+	if len(bodies) != 2 || bodies[0] != `{"isbn":"123"}` || bodies[1] != bodies[0] {
+		t.Errorf("Expected the payload to be sent by both requests, got %q", bodies)
+	}
+}
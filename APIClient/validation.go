@@ -0,0 +1,58 @@
+// Copyright 2018 Publit Sweden AB. All rights reserved.
+
+package APIClient
+
+// Validator is implemented by payload models able to validate themselves.
+// Payloads implementing Validator are validated before they are encoded and sent.
+type Validator interface {
+	Validate() error
+}
+
+// PayloadValidator validates a payload before it is encoded and sent.
+type PayloadValidator func(payload interface{}) error
+
+// PayloadError is returned when a payload fails validation before being sent.
+// It matches ErrValidation with errors.Is, like a validation error response from the API would.
+type PayloadError struct {
+	// Err is the error returned from the validator.
+	Err error
+}
+
+// Error returns the error message of the PayloadError.
+func (e *PayloadError) Error() string {
+	return "Invalid payload. " + e.Err.Error()
+}
+
+// Unwrap returns the error of the validator.
+func (e *PayloadError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports if target is ErrValidation.
+func (e *PayloadError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// WithPayloadValidators adds validators run on every payload before it is sent, see APIClient.PayloadValidators.
+func WithPayloadValidators(validators ...PayloadValidator) Option {
+	return func(c *APIClient) {
+		c.PayloadValidators = append(c.PayloadValidators, validators...)
+	}
+}
+
+// validatePayload runs the Validate method of the payload, if any, and the payload validators of the APIClient.
+func (c *APIClient) validatePayload(payload interface{}) error {
+	if v, ok := payload.(Validator); ok {
+		if err := v.Validate(); err != nil {
+			return &PayloadError{Err: err}
+		}
+	}
+
+	for _, validate := range c.PayloadValidators {
+		if err := validate(payload); err != nil {
+			return &PayloadError{Err: err}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package APIClient_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	. "github.com/publitsweden/APIUtilityGoSDK/APIClient"
+)
+
+type ValidatedModel struct {
+	Name string `json:"name"`
+}
+
+func (m *ValidatedModel) Validate() error {
+	if m.Name == "" {
+		return errors.New("Name is required")
+	}
+	return nil
+}
+
+func TestPayloadsAreValidatedBeforeSending(t *testing.T) {
+	t.Parallel()
+
+	caller := &MockAPICaller{}
+	caller.T = t
+	caller.CallTestCallback = func(t *testing.T, r *http.Request) {
+		t.Error("Did not expect an invalid payload to be sent.")
+	}
+
+	c := &APIClient{Client: caller, BaseURL: "somebaseurl", API: TestAPI}
+
+	err := c.Post(NewEndpoint(), &ValidatedModel{}, nil)
+
+	var payloadErr *PayloadError
+	if !errors.As(err, &payloadErr) || !errors.Is(err, ErrValidation) {
+		t.Errorf("Expected a validation PayloadError, got %v", err)
+	}
+}
+
+func TestPayloadValidatorsAreRun(t *testing.T) {
+	t.Parallel()
+
+	someErr := errors.New("Payload too large")
+
+	caller := &MockAPICaller{}
+	caller.Response = createCallerResponse(http.StatusOK, `{}`)
+
+	c, _ := NewAPIClient(
+		"https://test.publit.com",
+		TestAPI,
+		WithCaller(caller),
+		WithPayloadValidators(func(payload interface{}) error {
+			if m, ok := payload.(*ValidatedModel); ok && len(m.Name) > 5 {
+				return someErr
+			}
+			return nil
+		}),
+	)
+
+	if err := c.Put(NewEndpoint(), &ValidatedModel{Name: "too long name"}, nil); !errors.Is(err, someErr) {
+		t.Errorf("Expected the validator error, got %v", err)
+	}
+
+	if err := c.Put(NewEndpoint(), &ValidatedModel{Name: "ok"}, nil); err != nil {
+		t.Error("Received an error but was not expecting to.", err)
+	}
+}
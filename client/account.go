@@ -0,0 +1,58 @@
+// Copyright 2018 Publit Sweden AB. All rights reserved.
+
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// accountIDKey is the context key of the account id override.
+type accountIDKey struct{}
+
+// ContextWithAccountID returns a context making the requests using it act for the given account instead of
+// Client.AccountID. Lets one client serve several Publit accounts. Tokens are kept per account.
+func ContextWithAccountID(ctx context.Context, accountID int) context.Context {
+	return context.WithValue(ctx, accountIDKey{}, accountID)
+}
+
+// AccountIDFromContext returns the account id set by ContextWithAccountID, if any.
+func AccountIDFromContext(ctx context.Context) (int, bool) {
+	id, ok := ctx.Value(accountIDKey{}).(int)
+	return id, ok
+}
+
+// accountID returns the id of the account the request acts for.
+func (c *Client) accountID(r *http.Request) int {
+	if id, ok := AccountIDFromContext(r.Context()); ok {
+		return id
+	}
+	return c.defaultAccountID()
+}
+
+// token returns the token of the account. Must be called with M held.
+func (c *Client) token(accountID int) string {
+	if accountID == c.defaultAccountID() {
+		return c.Token
+	}
+	return c.accountTokens[accountID]
+}
+
+// setToken sets the token of the account, forgetting the expiry of the previous token. Must be called with M held.
+func (c *Client) setToken(accountID int, token string) {
+	delete(c.tokenExpiries, accountID)
+
+	if accountID == c.defaultAccountID() {
+		c.Token = token
+		return
+	}
+
+	if c.accountTokens == nil {
+		c.accountTokens = map[int]string{}
+	}
+	if token == "" {
+		delete(c.accountTokens, accountID)
+		return
+	}
+	c.accountTokens[accountID] = token
+}
@@ -0,0 +1,65 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCallActsForAccountFromContext(t *testing.T) {
+	t.Parallel()
+
+	tokens := map[string]string{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, _, _ := r.BasicAuth()
+		tokens[user] = r.Header.Get("token")
+		w.Header().Set("token", "token-"+user)
+	}))
+	defer ts.Close()
+
+	store := NewMemoryTokenStore()
+	c := New(
+		WithTokenStore(store),
+		func(c *Client) {
+			c.User = "someuser"
+			c.Password = "somepassword"
+			c.AccountID = 1
+			c.Logger = &MockLogger{}
+		},
+	)
+
+	call := func(ctx context.Context) string {
+		r, _ := http.NewRequestWithContext(ctx, HTTP_GET, ts.URL, nil)
+		if _, err := c.Call(r); err != nil {
+			t.Fatalf("Received an error but did not expect one: %v", err)
+		}
+		user, password, _ := r.BasicAuth()
+		return user + ":" + password
+	}
+
+	if auth := call(ContextWithAccountID(context.Background(), 2)); auth != "someuser;2:somepassword" {
+		t.Errorf("Expected auth of account 2, got %q", auth)
+	}
+
+	if auth := call(context.Background()); auth != "someuser;1:somepassword" {
+		t.Errorf("Expected auth of default account, got %q, token of account 2 must not be used", auth)
+	}
+
+	if auth := call(ContextWithAccountID(context.Background(), 2)); auth != "someuser;2:" || tokens["someuser;2"] != "token-someuser;2" {
+		t.Errorf("Expected token of account 2 to be used, got %q", auth)
+	}
+
+	if c.GetAuthToken() != "token-someuser;1" {
+		t.Errorf("Expected token of default account to be kept separately, got %q", c.GetAuthToken())
+	}
+
+	if token, _ := store.Get("someuser;2"); token != "token-someuser;2" {
+		t.Errorf("Expected token of account 2 to be stored, got %q", token)
+	}
+
+	c.UnsetAuthToken()
+	if _, err := store.Get("someuser;2"); err != ErrTokenNotFound {
+		t.Error("Expected tokens of all accounts to be unset.")
+	}
+}
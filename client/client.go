@@ -4,10 +4,12 @@
 package client
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
 	"sync"
+	"time"
 
 	"github.com/publitsweden/APIUtilityGoSDK/APILog"
 )
@@ -15,6 +17,10 @@ import (
 // Client is a struct that holds credential information needed to connect to the Publit API.
 // This is a generic object and does not in itself contain specific information needed to access endpoints.
 // To connect to the API endpoints use the API libraries together with this.
+//
+// A Client is safe for concurrent use once configured, e.g. by New. Fields must not be assigned while the client is in
+// use; User, Password, AccountID, Logger and HTTPClient can be changed at runtime with SetCredentials, SetLogger and
+// SetHTTPClient instead.
 type Client struct {
 	//User name of the user attempting to authorise against the Publit APIs.
 	User string
@@ -22,14 +28,75 @@ type Client struct {
 	Password string
 	// AccountID the id of the Publit account the client wants to connect to.
 	AccountID int
+	// BaseURL is the base URL of the Publit APIs. Optional.
+	// Used by API clients built on the client that do not set a base URL of their own.
+	BaseURL string
 	// HTTPClient an object that implement the Doer interface.
 	HTTPClient Doer
+	// Timeout is the overall timeout of a request, including reading the response body. Optional.
+	Timeout time.Duration
+	// DialTimeout is the timeout of connecting to the Publit APIs. Optional.
+	DialTimeout time.Duration
+	// TLSHandshakeTimeout is the timeout of the TLS handshake. Optional, defaults to the one of http.DefaultTransport.
+	TLSHandshakeTimeout time.Duration
+	// Retries is the number of times a failed request is retried, see WithRetries. Defaults to no retries.
+	Retries int
+	// RetryBackoff is the time to wait between retries.
+	RetryBackoff Backoff
+	// RetryPolicy decides which failed requests are retried. Defaults to DefaultRetryPolicy.
+	RetryPolicy RetryPolicy
+	// RateLimiter limits the rate of requests of the client, see NewRateLimiter. Optional.
+	RateLimiter *RateLimiter
+	// DisableCompression stops the client from requesting gzip or deflate encoded responses and decoding them.
+	// Compression is never requested for requests setting their own Accept-Encoding header.
+	DisableCompression bool
+	// Debug makes the client log dumps of requests and responses at Debug level, see WithDebug.
+	// Credential headers are scrubbed from the dumps, but bodies are logged as is.
+	Debug bool
+	// DebugMaxBody is the number of bytes of a body included in debug dumps, see DEFAULT_DEBUG_MAX_BODY.
+	DebugMaxBody int
+	// OnRequest, OnResponse and OnError are hooks called in order by CallRaw, see WithOnRequest.
+	// OnRequest hooks are called after DefaultHeaders are set, and OnError hooks also for failures to authenticate in Call.
+	OnRequest  []RequestHook
+	OnResponse []ResponseHook
+	OnError    []ErrorHook
+	// DefaultHeaders are set on every request, unless the request already has the header, see WithDefaultHeader.
+	DefaultHeaders http.Header
+	// DialContext makes connections for the HTTPClient, e.g. to resolve the Publit hosts in split-horizon DNS setups. Optional.
+	DialContext DialFunc
+	// PinnedAddresses maps hosts to the addresses connections are made to instead of the resolved ones, see WithPinnedAddresses.
+	PinnedAddresses map[string][]string
 	// Token is the authorisation token that can be recieved from the Publit APIs.
 	Token string
 	// Logger is the logger object used for logging informational and debug messages.
 	Logger Logger
 	// M is a mutex and is used for not causing race-conditions on the Token attribute if several goroutines simultanously is trying to update it.
 	M *sync.Mutex
+	// RefreshSkew is how long before the expiry of a JWT token the token is replaced with a new one, see DEFAULT_REFRESH_SKEW.
+	// Expiring tokens are dropped before a call, so the call authenticates with the credentials and gets a new token in its response.
+	RefreshSkew time.Duration
+	// TokenRotation is the policy for adopting tokens received in responses. Defaults to TOKEN_ROTATION_WHEN_EMPTY.
+	TokenRotation TokenRotation
+	// TokenStore persists the token of the client, so it can be reused across process restarts. Optional.
+	// A client without token loads it from the store, and received tokens are saved to it.
+	TokenStore TokenStore
+	// OAuth2 makes the client authenticate with OAuth2 access tokens instead of User, Password and Token, see WithOAuth2.
+	OAuth2 *OAuth2Config
+	// CredentialProvider gives User, Password and AccountID if User is not set. New defaults it to the
+	// DefaultCredentialChain. The provider is resolved on the first call.
+	CredentialProvider CredentialProvider
+
+	// oauth2Token is the current OAuth2 access token, and oauth2Fetch the pending request for a new one. Guarded by M.
+	oauth2Token *oauth2Token
+	oauth2Fetch *oauth2Fetch
+	// accountTokens are the tokens of accounts other than AccountID, see ContextWithAccountID. Guarded by M.
+	accountTokens map[int]string
+	// tokenExpiries are the expiries of tokens given by the Token-Expires header, by account. Guarded by M.
+	tokenExpiries map[int]time.Time
+	// configErr is the error of a config func given to New, see ConfigError.
+	configErr error
+	// cfg guards User, Password, AccountID, Logger and HTTPClient once the client is in use.
+	cfg sync.RWMutex
 }
 
 // Doer is an interface representing the ability to do a request.
@@ -46,6 +113,9 @@ type Logger interface {
 
 // New creates a New API Client.
 // Automatically sets HTTPClient to http.DefaultClient and Logger to APILog.APILog if not explicitly set. And also sets an empty sync.Mutex to M.
+// If any of Timeout, DialTimeout, TLSHandshakeTimeout, DialContext or PinnedAddresses is set, HTTPClient instead defaults
+// to a http.Client using them. They are not applied to an explicitly set HTTPClient.
+// CredentialProvider defaults to the DefaultCredentialChain, used on the first call if User is not set.
 func New(configFunc ...func(c *Client)) *Client {
 	c := &Client{}
 	c.M = &sync.Mutex{}
@@ -54,6 +124,10 @@ func New(configFunc ...func(c *Client)) *Client {
 		v(c)
 	}
 
+	if c.HTTPClient == nil && c.hasTransportSettings() {
+		c.HTTPClient = c.newHTTPClient()
+	}
+
 	if c.HTTPClient == nil {
 		c.HTTPClient = http.DefaultClient
 	}
@@ -62,102 +136,273 @@ func New(configFunc ...func(c *Client)) *Client {
 		c.Logger = APILog.New()
 	}
 
+	if c.CredentialProvider == nil {
+		c.CredentialProvider = &defaultCredentials{}
+	}
+
 	return c
 }
 
 // Call performs an authenticated request defined by http.Request.
 // Call automatically sets the authentication portion of the request.
 func (c *Client) Call(r *http.Request) (*http.Response, error) {
-	c.setAuth(r)
-	return c.CallRaw(r)
+	if err := c.setAuth(r); err != nil {
+		c.onError(r, err)
+		return nil, err
+	}
+
+	resp, err := c.CallRaw(r)
+	if err == nil && c.OAuth2 != nil && resp.StatusCode == http.StatusUnauthorized {
+		c.dropOAuth2Token(r)
+	}
+	return resp, err
 }
 
 // CallRaw performs request directly from http.Request (without automatic authentication).
+// Returns a TransportError if the request fails without a response.
 func (c *Client) CallRaw(r *http.Request) (*http.Response, error) {
-	c.Logger.Info(fmt.Sprintf("Calling URL: %s %s %s %s", r.Method, r.Host, r.URL.Path, r.URL.RawQuery))
-	resp, err := c.HTTPClient.Do(r)
+	c.setDefaultHeaders(r)
+	c.onRequest(r)
+	decompress := c.requestCompression(r)
 
-	if err != nil {
-		c.Logger.Debug(err)
+	c.GetLogger().Info(fmt.Sprintf("Calling URL: %s %s %s %s", r.Method, r.Host, r.URL.Path, r.URL.RawQuery))
+	if c.Debug {
+		c.debugRequest(r)
 	}
 
-	c.Logger.Info(fmt.Sprintf("Request URL: [%s %s %s] responded with status: %s %d", r.Method, r.Host, r.URL.Path, resp.Status, resp.StatusCode))
+	resp, err := c.do(r)
 
-	// IF token is not set attempt to set it using the response from the request
-	c.M.Lock()
-	t := c.Token
-	c.M.Unlock()
+	if err == nil && resp == nil {
+		err = errors.New("No response received")
+	}
 
-	if t == "" {
-		// No need to handle token error here since that is not the main objective of this method
-		c.setTokenFromResponse(resp)
+	// Return early on transport errors. Any response is kept, but is not used since http.Client closes its body.
+	if err != nil {
+		c.GetLogger().Debug(err)
+		err = NewTransportError(r, err)
+		c.onError(r, err)
+		return resp, err
 	}
 
-	return resp, err
+	if decompress {
+		decompressResponse(resp)
+	}
+
+	if c.Debug {
+		c.debugResponse(resp)
+	}
+
+	c.GetLogger().Info(fmt.Sprintf("Request URL: [%s %s %s] responded with status: %s %d", r.Method, r.Host, r.URL.Path, resp.Status, resp.StatusCode))
+
+	c.adoptToken(r, resp)
+	c.onResponse(r, resp)
+
+	return resp, nil
 }
 
 // SetNewAPIToken performs a given *http.Request and sets Client.Token.
 // Does not return any other information but errors if any occured.
+// Returns ErrAuthenticationFailed if the credentials are rejected, ErrTokenMissing if the response has no token,
+// and a TransportError if the request fails without a response.
 func (c *Client) SetNewAPIToken(r *http.Request) error {
-	c.setAuth(r)
-
 	resp, err := c.Call(r)
 
 	if err != nil {
-		c.Logger.Debug(err)
+		c.GetLogger().Debug(err)
+		return err
+	}
+	if resp.Body != nil {
+		defer resp.Body.Close()
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		err := fmt.Errorf(`%w. Code: "%v"`, ErrAuthenticationFailed, resp.StatusCode)
+		c.GetLogger().Debug(err)
 		return err
 	}
 
-	err = c.setTokenFromResponse(resp)
+	err = c.setTokenFromResponse(c.accountID(r), resp)
 
 	if err != nil {
-		c.Logger.Debug(err)
+		c.GetLogger().Debug(err)
 		return err
 	}
 
 	return nil
 }
 
-func (c *Client) setTokenFromResponse(r *http.Response) error {
+// SetNewAPITokenContext performs the request with the given context and sets Client.Token, like SetNewAPIToken.
+// The token request is cancelled when ctx is done.
+func (c *Client) SetNewAPITokenContext(ctx context.Context, r *http.Request) error {
+	return c.SetNewAPIToken(r.WithContext(ctx))
+}
+
+func (c *Client) setTokenFromResponse(accountID int, r *http.Response) error {
 	token := r.Header.Get("token")
 	if token == "" {
-		err := errors.New("No token received in header. Could not set token from response.")
-		c.Logger.Debug(err)
-		return err
-
+		c.GetLogger().Debug(ErrTokenMissing)
+		return ErrTokenMissing
 	}
 
 	c.M.Lock()
-	c.Token = token
+	c.setToken(accountID, token)
+	c.setTokenExpiry(accountID, r)
 	c.M.Unlock()
 
+	c.storeToken(accountID, token)
+
 	return nil
 }
 
-func (c *Client) setAuth(r *http.Request) {
-	username := c.User + ";"
-	if c.AccountID != 0 {
-		username = fmt.Sprintf("%v;%v", c.User, c.AccountID)
+func (c *Client) setAuth(r *http.Request) error {
+	if c.OAuth2 != nil {
+		return c.setOAuth2Auth(r)
 	}
 
-	password := c.Password
-	if c.Token != "" {
-		r.Header.Set("token", c.Token)
+	if err := c.resolveCredentials(); err != nil {
+		return err
+	}
+
+	accountID := c.accountID(r)
+	c.loadToken(accountID)
+	c.refreshExpiringToken(accountID)
+
+	creds := c.GetCredentials()
+	username := creds.User + ";"
+	if accountID != 0 {
+		username = fmt.Sprintf("%v;%v", creds.User, accountID)
+	}
+
+	c.M.Lock()
+	token := c.token(accountID)
+	c.M.Unlock()
+
+	password := creds.Password
+	if token != "" {
+		r.Header.Set("token", token)
 		password = ""
 	}
 
 	r.SetBasicAuth(username, password)
+	return nil
+}
+
+// WithDefaultHeader adds a header to Client.DefaultHeaders.
+func WithDefaultHeader(key, value string) func(c *Client) {
+	return func(c *Client) {
+		if c.DefaultHeaders == nil {
+			c.DefaultHeaders = http.Header{}
+		}
+		c.DefaultHeaders.Add(key, value)
+	}
+}
+
+// setDefaultHeaders sets the DefaultHeaders not already set on the request.
+func (c *Client) setDefaultHeaders(r *http.Request) {
+	for k, v := range c.DefaultHeaders {
+		if _, ok := r.Header[k]; ok {
+			continue
+		}
+		if r.Header == nil {
+			r.Header = http.Header{}
+		}
+		r.Header[k] = append([]string(nil), v...)
+	}
 }
 
 // GetAuthToken getter for authentication token.
 func (c *Client) GetAuthToken() string {
+	c.M.Lock()
+	defer c.M.Unlock()
+
 	return c.Token
 }
 
-// UnsetAuthToken unsets authentication token.
+// GetCredentials returns User, Password and AccountID of the client.
+func (c *Client) GetCredentials() Credentials {
+	c.cfg.RLock()
+	defer c.cfg.RUnlock()
+
+	return Credentials{User: c.User, Password: c.Password, AccountID: c.AccountID}
+}
+
+// SetCredentials sets User, Password and AccountID of the client.
+// Tokens of the client are unset, so following calls authenticate with the new credentials.
+func (c *Client) SetCredentials(creds Credentials) {
+	c.cfg.Lock()
+	c.User = creds.User
+	c.Password = creds.Password
+	c.AccountID = creds.AccountID
+	c.cfg.Unlock()
+
+	c.M.Lock()
+	c.Token = ""
+	c.accountTokens = nil
+	c.tokenExpiries = nil
+	c.M.Unlock()
+}
+
+// GetLogger returns the Logger of the client.
+func (c *Client) GetLogger() Logger {
+	c.cfg.RLock()
+	defer c.cfg.RUnlock()
+
+	return c.Logger
+}
+
+// SetLogger sets the Logger of the client.
+func (c *Client) SetLogger(l Logger) {
+	c.cfg.Lock()
+	defer c.cfg.Unlock()
+
+	c.Logger = l
+}
+
+// GetHTTPClient returns the HTTPClient of the client.
+func (c *Client) GetHTTPClient() Doer {
+	c.cfg.RLock()
+	defer c.cfg.RUnlock()
+
+	return c.HTTPClient
+}
+
+// SetHTTPClient sets the HTTPClient of the client.
+func (c *Client) SetHTTPClient(d Doer) {
+	c.cfg.Lock()
+	defer c.cfg.Unlock()
+
+	c.HTTPClient = d
+}
+
+// defaultAccountID returns the AccountID of the client.
+func (c *Client) defaultAccountID() int {
+	c.cfg.RLock()
+	defer c.cfg.RUnlock()
+
+	return c.AccountID
+}
+
+// UnsetAuthToken unsets authentication token, including the tokens of other accounts, see ContextWithAccountID.
 // If need to re-authenticate, this can be used to force re-authentication for the next call.
 func (c *Client) UnsetAuthToken() {
 	c.M.Lock()
 	c.Token = ""
+	accountIDs := []int{c.defaultAccountID()}
+	for id := range c.accountTokens {
+		accountIDs = append(accountIDs, id)
+	}
+	c.accountTokens = nil
+	c.tokenExpiries = nil
 	c.M.Unlock()
+
+	for _, id := range accountIDs {
+		c.storeToken(id, "")
+	}
+}
+
+// CloseIdleConnections closes idle connections of the HTTPClient, if it supports it (like *http.Client).
+func (c *Client) CloseIdleConnections() {
+	if ic, ok := c.GetHTTPClient().(interface{ CloseIdleConnections() }); ok {
+		ic.CloseIdleConnections()
+	}
 }
@@ -1,6 +1,7 @@
 package client
 
 import (
+	"context"
 	"encoding/base64"
 	"errors"
 	"fmt"
@@ -8,7 +9,9 @@ import (
 	"log"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
+	"time"
 )
 
 const (
@@ -244,6 +247,29 @@ func TestCanSetAPIToken(t *testing.T) {
 	}
 }
 
+func TestSetNewAPITokenContextIsCancellable(t *testing.T) {
+	t.Parallel()
+	c := New(func(c *Client) {
+		c.User = "someuser"
+		c.Password = "somepassword"
+		c.HTTPClient = DoerFunc(func(r *http.Request) (*http.Response, error) {
+			<-r.Context().Done()
+			return nil, r.Context().Err()
+		})
+		c.Logger = &MockLogger{}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	r := httptest.NewRequest(HTTP_POST, "http://someurl.test", nil)
+	r.RequestURI = ""
+
+	if err := c.SetNewAPITokenContext(ctx, r); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context error, got %v", err)
+	}
+}
+
 func TestNewAPITokenCanHandleErrors(t *testing.T) {
 	t.Parallel()
 	c := New(
@@ -291,6 +317,88 @@ func TestNewAPITokenCanHandleErrors(t *testing.T) {
 
 }
 
+func TestCallRawReturnsTransportErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]Doer{
+		"Error without response": DoerFunc(func(r *http.Request) (*http.Response, error) { return nil, errors.New("connection refused") }),
+		"No response":            DoerFunc(func(r *http.Request) (*http.Response, error) { return nil, nil }),
+		"Error with response":    MockClient{ReturnError: true},
+	}
+
+	for name, doer := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := New(func(c *Client) {
+				c.HTTPClient = doer
+				c.Logger = &MockLogger{}
+			})
+
+			r := httptest.NewRequest(HTTP_GET, "http://someurl.test/path", nil)
+			r.RequestURI = ""
+
+			_, err := c.Call(r)
+
+			var te *TransportError
+			if !errors.As(err, &te) {
+				t.Fatalf("Expected a TransportError, got %v", err)
+			}
+			if te.Method != HTTP_GET || te.URL != "http://someurl.test/path" {
+				t.Errorf("Unexpected request of TransportError. Got %s %s", te.Method, te.URL)
+			}
+			if c.GetAuthToken() != "" {
+				t.Error("Did not expect a token to be set from a failed request.")
+			}
+		})
+	}
+}
+
+func TestNewAPITokenReturnsTypedErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		doer     Doer
+		expected error
+	}{
+		"Rejected credentials": {
+			DoerFunc(func(r *http.Request) (*http.Response, error) {
+				return &http.Response{StatusCode: http.StatusUnauthorized, Header: http.Header{}}, nil
+			}),
+			ErrAuthenticationFailed,
+		},
+		"No token in response": {MockClient{}, ErrTokenMissing},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := New(func(c *Client) {
+				c.User = "someuser"
+				c.Password = "wrongpassword"
+				c.HTTPClient = tt.doer
+				c.Logger = &MockLogger{}
+			})
+
+			r := httptest.NewRequest(HTTP_POST, "http://someurl.test", nil)
+			r.RequestURI = ""
+
+			if err := c.SetNewAPIToken(r); !errors.Is(err, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, err)
+			}
+		})
+	}
+
+	c := New(func(c *Client) {
+		c.HTTPClient = DoerFunc(func(r *http.Request) (*http.Response, error) { return nil, errors.New("connection refused") })
+		c.Logger = &MockLogger{}
+	})
+	r := httptest.NewRequest(HTTP_POST, "http://someurl.test", nil)
+	r.RequestURI = ""
+
+	var te *TransportError
+	if err := c.SetNewAPIToken(r); !errors.As(err, &te) || errors.Is(err, ErrAuthenticationFailed) {
+		t.Errorf("Expected transport error distinct from authentication errors, got %v", err)
+	}
+}
+
 func TestCanGetToken(t *testing.T) {
 	t.Parallel()
 	token := "sometoken"
@@ -322,6 +430,102 @@ func TestCanUnsetToken(t *testing.T) {
 	}
 }
 
+func TestClientCanBeReconfiguredConcurrently(t *testing.T) {
+	t.Parallel()
+	c := New(
+		func(c *Client) {
+			c.User = "someuser"
+			c.Password = "somepassword"
+			c.HTTPClient = MockClient{SetTokenHeader: true}
+			c.Logger = &MockLogger{}
+		},
+	)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			r := httptest.NewRequest(HTTP_GET, "http://someurl.test", nil)
+			r.RequestURI = ""
+			c.Call(r)
+			c.GetAuthToken()
+		}()
+		go func(i int) {
+			defer wg.Done()
+			c.SetCredentials(Credentials{User: "otheruser", Password: "otherpassword", AccountID: i})
+			c.SetLogger(&MockLogger{})
+			c.SetHTTPClient(MockClient{SetTokenHeader: true})
+		}(i)
+	}
+	wg.Wait()
+
+	if creds := c.GetCredentials(); creds.User != "otheruser" || creds.Password != "otherpassword" {
+		t.Errorf("Unexpected credentials %+v", creds)
+	}
+}
+
+func TestSetCredentialsUnsetsToken(t *testing.T) {
+	t.Parallel()
+	c := New(func(c *Client) { c.Token = "sometoken" })
+
+	c.SetCredentials(Credentials{User: "otheruser"})
+
+	if c.GetAuthToken() != "" {
+		t.Error("Expected token to be unset but was not")
+	}
+}
+
+func TestCanCloseIdleConnections(t *testing.T) {
+	t.Parallel()
+	doer := &ClosableMockClient{}
+	c := New(
+		func(c *Client) {
+			c.HTTPClient = doer
+		},
+	)
+
+	c.CloseIdleConnections()
+
+	if !doer.IdleClosed {
+		t.Error("Expected idle connections to be closed but were not")
+	}
+
+	// Doers without support for closing connections are left as is.
+	New().CloseIdleConnections()
+	c = New(func(c *Client) { c.HTTPClient = MockClient{} })
+	c.CloseIdleConnections()
+}
+
+func TestCallSetsDefaultHeaders(t *testing.T) {
+	t.Parallel()
+	c := New(
+		WithDefaultHeader("X-Tenant", "sometenant"),
+		WithDefaultHeader("X-Feature", "a"),
+		WithDefaultHeader("X-Feature", "b"),
+		func(c *Client) {
+			c.HTTPClient = MockClient{}
+			c.Logger = &MockLogger{}
+		},
+	)
+
+	r := httptest.NewRequest(HTTP_GET, "http://someurl.test", nil)
+	r.RequestURI = ""
+	r.Header.Set("X-Tenant", "othertenant")
+
+	if _, err := c.Call(r); err != nil {
+		t.Errorf("Received an error but did not expect one: %v", err.Error())
+	}
+
+	if r.Header.Get("X-Tenant") != "othertenant" {
+		t.Error("Expected header of request to take precedence over default header.")
+	}
+
+	if v := r.Header.Values("X-Feature"); len(v) != 2 || v[0] != "a" || v[1] != "b" {
+		t.Errorf("Expected default header to be set, got %v", v)
+	}
+}
+
 func b64enc(str string) string {
 	return base64.StdEncoding.EncodeToString([]byte(str))
 }
@@ -351,6 +555,22 @@ func (m MockClient) Do(r *http.Request) (*http.Response, error) {
 	}, nil
 }
 
+// DoerFunc is an adapter allowing an ordinary function to be used as a Doer.
+type DoerFunc func(r *http.Request) (*http.Response, error)
+
+func (f DoerFunc) Do(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+type ClosableMockClient struct {
+	MockClient
+	IdleClosed bool
+}
+
+func (m *ClosableMockClient) CloseIdleConnections() {
+	m.IdleClosed = true
+}
+
 type MockLogger struct {
 	DebugCallback func(message interface{})
 	InfoCallback  func(message interface{})
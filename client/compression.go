@@ -0,0 +1,108 @@
+// Copyright 2018 Publit Sweden AB. All rights reserved.
+
+package client
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// ACCEPT_ENCODING is the Accept-Encoding header set on requests by CallRaw, unless Client.DisableCompression is set.
+const ACCEPT_ENCODING = "gzip, deflate"
+
+// requestCompression sets the Accept-Encoding header on the request, unless compression is disabled or the caller set
+// the header. Reports whether responses to the request should be decompressed by the client.
+//
+// Like http.Transport, compression is not requested for HEAD requests and requests with a Range header, as the ranges
+// and lengths of the response refer to the encoded content.
+//
+// The client owns decoding of the responses it asked to be compressed. If the Accept-Encoding header is already set,
+// eg. by the APIClient GzipCompression middleware, whoever set it decodes the response.
+func (c *Client) requestCompression(r *http.Request) bool {
+	if c.DisableCompression || r.Header.Get("Accept-Encoding") != "" || !acceptsCompression(r) {
+		return false
+	}
+
+	if r.Header == nil {
+		r.Header = http.Header{}
+	}
+	r.Header.Set("Accept-Encoding", ACCEPT_ENCODING)
+	return true
+}
+
+// acceptsCompression reports whether compressed responses may be requested, ie. the request is not a HEAD request and
+// has no Range header.
+func acceptsCompression(r *http.Request) bool {
+	return r.Method != http.MethodHead && r.Header.Get("Range") == ""
+}
+
+// decompressResponse replaces a gzip or deflate encoded body of the response with the decoded body, like http.Transport
+// does when it requests compression itself. The body is decoded lazily, so decoding errors are returned when reading it.
+func decompressResponse(resp *http.Response) {
+	if resp.Body == nil || resp.Body == http.NoBody {
+		return
+	}
+
+	var decode func(r io.Reader) (io.ReadCloser, error)
+	switch strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding"))) {
+	case "gzip", "x-gzip":
+		decode = func(r io.Reader) (io.ReadCloser, error) { return gzip.NewReader(r) }
+	case "deflate":
+		decode = newDeflateReader
+	default:
+		return
+	}
+
+	resp.Body = &decompressingBody{body: resp.Body, decode: decode}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+}
+
+// newDeflateReader decodes deflate content, which should be zlib wrapped but is raw deflate from some servers.
+func newDeflateReader(r io.Reader) (io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+
+	header, err := br.Peek(2)
+	if err == nil && isZlibHeader(header) {
+		return zlib.NewReader(br)
+	}
+
+	return flate.NewReader(br), nil
+}
+
+// isZlibHeader reports whether the bytes are a zlib header using deflate, see RFC 1950.
+func isZlibHeader(b []byte) bool {
+	return b[0]&0x0f == 8 && (uint16(b[0])<<8|uint16(b[1]))%31 == 0
+}
+
+// decompressingBody decodes the body on the first read.
+type decompressingBody struct {
+	body    io.ReadCloser
+	decode  func(r io.Reader) (io.ReadCloser, error)
+	decoder io.ReadCloser
+	err     error
+}
+
+func (b *decompressingBody) Read(p []byte) (int, error) {
+	if b.decoder == nil && b.err == nil {
+		b.decoder, b.err = b.decode(b.body)
+	}
+	if b.err != nil {
+		return 0, b.err
+	}
+	return b.decoder.Read(p)
+}
+
+func (b *decompressingBody) Close() error {
+	if b.decoder != nil {
+		b.decoder.Close()
+	}
+	return b.body.Close()
+}
@@ -0,0 +1,136 @@
+package client
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const compressedMessage = `{"message":"Received request"}`
+
+func compress(encoding string) []byte {
+	b := &bytes.Buffer{}
+
+	var w io.WriteCloser
+	switch encoding {
+	case "gzip":
+		w = gzip.NewWriter(b)
+	case "deflate":
+		w = zlib.NewWriter(b)
+	case "raw deflate":
+		w, _ = flate.NewWriter(b, flate.DefaultCompression)
+	}
+
+	w.Write([]byte(compressedMessage))
+	w.Close()
+	return b.Bytes()
+}
+
+func TestCallDecompressesResponses(t *testing.T) {
+	t.Parallel()
+
+	for _, encoding := range []string{"gzip", "deflate", "raw deflate"} {
+		encoding := encoding
+		t.Run(encoding, func(t *testing.T) {
+			t.Parallel()
+			body := compress(encoding)
+
+			acceptEncoding := ""
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				acceptEncoding = r.Header.Get("Accept-Encoding")
+				if encoding == "raw deflate" {
+					w.Header().Set("Content-Encoding", "deflate")
+				} else {
+					w.Header().Set("Content-Encoding", encoding)
+				}
+				w.Write(body)
+			}))
+			defer ts.Close()
+
+			c := New(func(c *Client) {
+				c.HTTPClient = &http.Client{Transport: &http.Transport{DisableCompression: true}}
+				c.Logger = &MockLogger{}
+			})
+
+			r, _ := http.NewRequest(HTTP_GET, ts.URL, nil)
+			resp, err := c.CallRaw(r)
+			if err != nil {
+				t.Fatalf("Received an error but did not expect one: %v", err)
+			}
+			defer resp.Body.Close()
+
+			b, err := ioutil.ReadAll(resp.Body)
+			if err != nil || string(b) != compressedMessage {
+				t.Errorf("Expected decompressed body, got %q, %v", b, err)
+			}
+
+			if acceptEncoding != ACCEPT_ENCODING || resp.Header.Get("Content-Encoding") != "" || !resp.Uncompressed {
+				t.Errorf("Unexpected encoding headers. Accept-Encoding %q, Content-Encoding %q", acceptEncoding, resp.Header.Get("Content-Encoding"))
+			}
+		})
+	}
+}
+
+func TestCallKeepsEncodingRequestedByCaller(t *testing.T) {
+	t.Parallel()
+	body := compress("gzip")
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write(body)
+	}))
+	defer ts.Close()
+
+	c := New(func(c *Client) { c.Logger = &MockLogger{} })
+
+	r, _ := http.NewRequest(HTTP_GET, ts.URL, nil)
+	r.Header.Set("Accept-Encoding", "gzip")
+	resp, err := c.CallRaw(r)
+	if err != nil {
+		t.Fatalf("Received an error but did not expect one: %v", err)
+	}
+	defer resp.Body.Close()
+
+	b, _ := ioutil.ReadAll(resp.Body)
+	if !bytes.Equal(b, body) {
+		t.Error("Expected body to be left encoded when the caller sets Accept-Encoding.")
+	}
+}
+
+func TestCallDoesNotRequestCompressionForRangeAndHeadRequests(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ae := r.Header.Get("Accept-Encoding"); ae != "" {
+			t.Errorf("Expected no Accept-Encoding header for %s request with Range %q, got %q.", r.Method, r.Header.Get("Range"), ae)
+		}
+	}))
+	defer ts.Close()
+
+	// Disable the transport's own compression to observe the header set by the client.
+	c := New(func(c *Client) {
+		c.Logger = &MockLogger{}
+		c.HTTPClient = &http.Client{Transport: &http.Transport{DisableCompression: true}}
+	})
+
+	r, _ := http.NewRequest(HTTP_GET, ts.URL, nil)
+	r.Header.Set("Range", "bytes=10-")
+	if resp, err := c.CallRaw(r); err != nil {
+		t.Fatalf("Received an error but did not expect one: %v", err)
+	} else {
+		resp.Body.Close()
+	}
+
+	r, _ = http.NewRequest(http.MethodHead, ts.URL, nil)
+	if resp, err := c.CallRaw(r); err != nil {
+		t.Fatalf("Received an error but did not expect one: %v", err)
+	} else {
+		resp.Body.Close()
+	}
+}
@@ -0,0 +1,204 @@
+// Copyright 2018 Publit Sweden AB. All rights reserved.
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Environment variable selecting the profile used by NewFromConfig.
+const ENV_PROFILE = "PUBLIT_PROFILE"
+
+// Name of the profile used by NewFromConfig if neither PUBLIT_PROFILE nor Config.DefaultProfile is set.
+const DEFAULT_PROFILE = "default"
+
+// Credentials reference of a profile reading the credentials from the environment, see EnvCredentials.
+const CREDENTIALS_ENV = "env"
+
+// ConfigDecoder decodes the contents of a config file into v, eg. json.Unmarshal.
+type ConfigDecoder func(data []byte, v interface{}) error
+
+var (
+	configDecodersMu sync.RWMutex
+	configDecoders   = map[string]ConfigDecoder{
+		".json": json.Unmarshal,
+	}
+)
+
+// RegisterConfigDecoder registers the decoder of config files with the given extension, eg. ".yaml".
+// JSON is supported by default. To support YAML register the Unmarshal function of a YAML package:
+//
+//	client.RegisterConfigDecoder(".yaml", yaml.Unmarshal)
+//	client.RegisterConfigDecoder(".yml", yaml.Unmarshal)
+func RegisterConfigDecoder(ext string, decoder ConfigDecoder) {
+	configDecodersMu.Lock()
+	defer configDecodersMu.Unlock()
+
+	configDecoders[strings.ToLower(ext)] = decoder
+}
+
+// Config holds the client settings of several named environments.
+type Config struct {
+	// DefaultProfile is the name of the profile used if PUBLIT_PROFILE is not set.
+	DefaultProfile string `json:"default_profile" yaml:"default_profile"`
+	// Profiles by name.
+	Profiles map[string]Profile `json:"profiles" yaml:"profiles"`
+}
+
+// Profile holds the client settings of one environment.
+type Profile struct {
+	// BaseURL is the base URL of the Publit APIs, see Client.BaseURL.
+	BaseURL string `json:"base_url" yaml:"base_url"`
+	// Environment names an environment, such as "production", used if BaseURL is not set. See LookupEnvironment.
+	Environment Environment `json:"environment" yaml:"environment"`
+	// User, Password and AccountID set the credentials explicitly.
+	User      string `json:"user" yaml:"user"`
+	Password  string `json:"password" yaml:"password"`
+	AccountID int    `json:"account_id" yaml:"account_id"`
+	// Credentials references where the credentials are read from if User is not set.
+	// Either CREDENTIALS_ENV or the path of a credentials file, see FileCredentials.
+	// Relative paths are relative to the config file.
+	Credentials string `json:"credentials" yaml:"credentials"`
+	// Timeout is the overall timeout of requests, eg. "30s".
+	Timeout Duration `json:"timeout" yaml:"timeout"`
+	// LogLevel is one of the LOG_LEVEL_* strings.
+	LogLevel string `json:"log_level" yaml:"log_level"`
+}
+
+// Duration is a time.Duration read from strings like "1m30s".
+type Duration time.Duration
+
+// UnmarshalText parses the duration with time.ParseDuration.
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+
+	*d = Duration(v)
+	return nil
+}
+
+// MarshalText formats the duration like time.Duration.String.
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// LoadConfig reads a config file, decoded by the ConfigDecoder registered for its extension.
+func LoadConfig(path string) (*Config, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+
+	configDecodersMu.RLock()
+	decoder, ok := configDecoders[ext]
+	configDecodersMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("No decoder registered for %q config files, see RegisterConfigDecoder", ext)
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &Config{}
+	if err := decoder(b, config); err != nil {
+		return nil, fmt.Errorf("Could not read config file %s. %v", path, err)
+	}
+
+	// Credentials files are relative to the config file.
+	dir := filepath.Dir(path)
+	for name, p := range config.Profiles {
+		if p.Credentials != "" && p.Credentials != CREDENTIALS_ENV && !filepath.IsAbs(p.Credentials) {
+			p.Credentials = filepath.Join(dir, p.Credentials)
+			config.Profiles[name] = p
+		}
+	}
+
+	return config, nil
+}
+
+// NewFromConfig creates a new client from a profile in a config file, see LoadConfig.
+// The profile is named by PUBLIT_PROFILE, otherwise by Config.DefaultProfile, otherwise DEFAULT_PROFILE.
+// The configFunc are applied after the profile, see New.
+func NewFromConfig(path string, configFunc ...func(c *Client)) (*Client, error) {
+	config, err := LoadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+
+	profile := os.Getenv(ENV_PROFILE)
+	if profile == "" {
+		profile = config.DefaultProfile
+	}
+	if profile == "" {
+		profile = DEFAULT_PROFILE
+	}
+
+	return config.NewClient(profile, configFunc...)
+}
+
+// NewClient creates a new client from the named profile.
+// The configFunc are applied after the profile, see New.
+func (c *Config) NewClient(profile string, configFunc ...func(c *Client)) (*Client, error) {
+	p, ok := c.Profiles[profile]
+	if !ok {
+		return nil, fmt.Errorf("Profile %q not found in config", profile)
+	}
+
+	if err := validateBaseURL(p.BaseURL); err != nil {
+		return nil, fmt.Errorf("Invalid base_url of profile %q. %v", profile, err)
+	}
+
+	if p.BaseURL == "" && p.Environment != "" {
+		baseURL, err := LookupEnvironment(p.Environment)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid environment of profile %q. %v", profile, err)
+		}
+		p.BaseURL = baseURL
+	}
+
+	level, err := parseLogLevel(p.LogLevel)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid log_level of profile %q. %v", profile, err)
+	}
+
+	config := []func(c *Client){
+		func(c *Client) {
+			c.User = p.User
+			c.Password = p.Password
+			c.AccountID = p.AccountID
+			c.BaseURL = p.BaseURL
+
+			switch p.Credentials {
+			case "":
+			case CREDENTIALS_ENV:
+				c.CredentialProvider = EnvCredentials()
+			default:
+				c.CredentialProvider = FileCredentials(p.Credentials)
+			}
+
+			c.Timeout = time.Duration(p.Timeout)
+		},
+	}
+	config = append(config, configFunc...)
+
+	// The log level applies to any logger set by configFunc.
+	if level != nil {
+		config = append(config, WithLogLevel(*level))
+	}
+
+	cl := New(config...)
+	if err := cl.ConfigError(); err != nil {
+		return nil, err
+	}
+
+	return cl, nil
+}
@@ -0,0 +1,107 @@
+package client
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testConfig = `{
+	"default_profile": "staging",
+	"profiles": {
+		"staging": {
+			"base_url": "https://staging.publit.test",
+			"user": "staginguser",
+			"password": "stagingpassword",
+			"account_id": 2,
+			"timeout": "30s"
+		},
+		"production": {
+			"base_url": "https://api.publit.test",
+			"credentials": "credentials.json",
+			"log_level": "info"
+		}
+	}
+}`
+
+func writeConfig(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestNewFromConfig(t *testing.T) {
+	t.Setenv(ENV_PROFILE, "")
+	path := writeConfig(t, "publit.json", testConfig)
+
+	c, err := NewFromConfig(path)
+	if err != nil {
+		t.Fatalf("Received an error but did not expect one: %v", err)
+	}
+
+	if c.User != "staginguser" || c.Password != "stagingpassword" || c.AccountID != 2 || c.BaseURL != "https://staging.publit.test" {
+		t.Errorf("Client was not configured from default profile: %+v", c)
+	}
+
+	if c.Timeout != 30*time.Second {
+		t.Errorf("Expected timeout of profile, got %v", c.Timeout)
+	}
+
+	t.Setenv(ENV_PROFILE, "production")
+	ioutil.WriteFile(filepath.Join(filepath.Dir(path), "credentials.json"), []byte(`{"user":"produser","password":"prodpassword"}`), 0600)
+
+	c, err = NewFromConfig(path)
+	if err != nil {
+		t.Fatalf("Received an error but did not expect one: %v", err)
+	}
+
+	if err := c.resolveCredentials(); err != nil || c.User != "produser" {
+		t.Errorf("Expected credentials from referenced file relative to config, got %q, %v", c.User, err)
+	}
+
+	if _, ok := c.Logger.(leveledLogger); !ok {
+		t.Error("Expected logger with log level.")
+	}
+}
+
+func TestNewFromConfigErrors(t *testing.T) {
+	t.Setenv(ENV_PROFILE, "missing")
+
+	if _, err := NewFromConfig(writeConfig(t, "publit.json", testConfig)); err == nil {
+		t.Error("Expected an error for missing profile but did not receive one.")
+	}
+
+	if _, err := NewFromConfig(writeConfig(t, "publit.toml", "")); err == nil {
+		t.Error("Expected an error for unsupported config format but did not receive one.")
+	}
+
+	if _, err := NewFromConfig(writeConfig(t, "publit.json", `{"profiles":{"missing":{"timeout":"soon"}}}`)); err == nil {
+		t.Error("Expected an error for invalid timeout but did not receive one.")
+	}
+}
+
+func TestCanRegisterConfigDecoder(t *testing.T) {
+	t.Setenv(ENV_PROFILE, "")
+
+	// A minimal "key: value" decoder standing in for a YAML package.
+	RegisterConfigDecoder(".test-yaml", func(data []byte, v interface{}) error {
+		p := Profile{}
+		for _, line := range strings.Split(string(data), "\n") {
+			kv := strings.SplitN(line, ": ", 2)
+			if len(kv) == 2 && kv[0] == "user" {
+				p.User = kv[1]
+			}
+		}
+		v.(*Config).Profiles = map[string]Profile{DEFAULT_PROFILE: p}
+		return nil
+	})
+
+	c, err := NewFromConfig(writeConfig(t, "publit.test-yaml", "user: yamluser"))
+	if err != nil || c.User != "yamluser" {
+		t.Errorf("Expected client from registered decoder, got %v", err)
+	}
+}
@@ -0,0 +1,200 @@
+// Copyright 2018 Publit Sweden AB. All rights reserved.
+
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// Environment variables read by EnvCredentials.
+const (
+	ENV_USER       = "PUBLIT_USER"
+	ENV_PASSWORD   = "PUBLIT_PASSWORD"
+	ENV_ACCOUNT_ID = "PUBLIT_ACCOUNT_ID"
+)
+
+// Path of the credentials file used by DefaultCredentialChain, relative to the home directory of the user.
+const DEFAULT_CREDENTIALS_FILE = ".publit/credentials.json"
+
+// ErrNoCredentials is returned by a CredentialProvider that has no credentials to give.
+var ErrNoCredentials = errors.New("No credentials found")
+
+// Credentials are the credentials used to authenticate against the Publit APIs.
+type Credentials struct {
+	User      string `json:"user"`
+	Password  string `json:"password"`
+	AccountID int    `json:"account_id"`
+}
+
+// CredentialProvider gives the credentials of a client.
+// The provider of a client is resolved lazily on the first call, and only if the User of the client is not set.
+type CredentialProvider interface {
+	// Credentials returns the credentials, or ErrNoCredentials if the provider has none.
+	Credentials() (Credentials, error)
+}
+
+// CredentialProviderFunc is an adapter allowing an ordinary function to be used as a CredentialProvider.
+type CredentialProviderFunc func() (Credentials, error)
+
+// Credentials calls f().
+func (f CredentialProviderFunc) Credentials() (Credentials, error) {
+	return f()
+}
+
+// WithCredentialProvider sets Client.CredentialProvider.
+func WithCredentialProvider(provider CredentialProvider) func(c *Client) {
+	return func(c *Client) {
+		c.CredentialProvider = provider
+	}
+}
+
+// StaticCredentials gives the given credentials.
+func StaticCredentials(user, password string, accountID int) CredentialProvider {
+	return CredentialProviderFunc(func() (Credentials, error) {
+		return Credentials{User: user, Password: password, AccountID: accountID}, nil
+	})
+}
+
+// EnvCredentials gives credentials from the PUBLIT_USER, PUBLIT_PASSWORD and PUBLIT_ACCOUNT_ID environment variables.
+// Has no credentials if PUBLIT_USER is not set.
+func EnvCredentials() CredentialProvider {
+	return CredentialProviderFunc(func() (Credentials, error) {
+		creds := Credentials{User: os.Getenv(ENV_USER), Password: os.Getenv(ENV_PASSWORD)}
+		if creds.User == "" {
+			return creds, ErrNoCredentials
+		}
+
+		if v := os.Getenv(ENV_ACCOUNT_ID); v != "" {
+			id, err := strconv.Atoi(v)
+			if err != nil {
+				return creds, fmt.Errorf("Invalid %s %q. %v", ENV_ACCOUNT_ID, v, err)
+			}
+			creds.AccountID = id
+		}
+
+		return creds, nil
+	})
+}
+
+// FileCredentials gives credentials from a json file with the attributes user, password and account_id.
+// Has no credentials if the file does not exist.
+func FileCredentials(path string) CredentialProvider {
+	return CredentialProviderFunc(func() (Credentials, error) {
+		creds := Credentials{}
+
+		b, err := ioutil.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			return creds, ErrNoCredentials
+		}
+		if err != nil {
+			return creds, err
+		}
+
+		if err := json.Unmarshal(b, &creds); err != nil {
+			return creds, fmt.Errorf("Could not read credentials file %s. %v", path, err)
+		}
+		if creds.User == "" {
+			return creds, ErrNoCredentials
+		}
+
+		return creds, nil
+	})
+}
+
+// ChainCredentials gives the credentials of the first provider having credentials.
+// Errors other than ErrNoCredentials stop the chain.
+func ChainCredentials(providers ...CredentialProvider) CredentialProvider {
+	return CredentialProviderFunc(func() (Credentials, error) {
+		for _, p := range providers {
+			creds, err := p.Credentials()
+			if errors.Is(err, ErrNoCredentials) {
+				continue
+			}
+			return creds, err
+		}
+
+		return Credentials{}, ErrNoCredentials
+	})
+}
+
+// DefaultCredentialChain gives credentials from the environment, see EnvCredentials, and otherwise from the
+// DEFAULT_CREDENTIALS_FILE in the home directory of the user. Credentials set explicitly on the client take precedence.
+// New uses it for clients without a CredentialProvider, in which case a client is left without credentials if the
+// chain has none.
+func DefaultCredentialChain() CredentialProvider {
+	providers := []CredentialProvider{EnvCredentials()}
+
+	if home, err := os.UserHomeDir(); err == nil {
+		providers = append(providers, FileCredentials(filepath.Join(home, DEFAULT_CREDENTIALS_FILE)))
+	}
+
+	return ChainCredentials(providers...)
+}
+
+// defaultCredentials is the CredentialProvider set by New. It resolves the DefaultCredentialChain once, and having no
+// credentials is not an error, so clients without credentials work as before.
+type defaultCredentials struct {
+	once     sync.Once
+	resolved int32
+	creds    Credentials
+	err      error
+}
+
+// Credentials returns the credentials of the DefaultCredentialChain, resolved on the first call.
+func (d *defaultCredentials) Credentials() (Credentials, error) {
+	d.once.Do(func() {
+		d.creds, d.err = DefaultCredentialChain().Credentials()
+		if errors.Is(d.err, ErrNoCredentials) {
+			d.err = nil
+		}
+		atomic.StoreInt32(&d.resolved, 1)
+	})
+	return d.creds, d.err
+}
+
+// empty reports if the chain has been resolved without finding any credentials.
+func (d *defaultCredentials) empty() bool {
+	return atomic.LoadInt32(&d.resolved) == 1 && d.err == nil && d.creds.User == ""
+}
+
+// resolveCredentials sets the credentials of the client from the CredentialProvider, unless the User is already set.
+// Resolved clients only take the read lock.
+func (c *Client) resolveCredentials() error {
+	c.cfg.RLock()
+	resolved := c.CredentialProvider == nil || c.User != ""
+	if d, ok := c.CredentialProvider.(*defaultCredentials); ok && d.empty() {
+		resolved = true
+	}
+	c.cfg.RUnlock()
+	if resolved {
+		return nil
+	}
+
+	c.cfg.Lock()
+	defer c.cfg.Unlock()
+
+	if c.CredentialProvider == nil || c.User != "" {
+		return nil
+	}
+
+	creds, err := c.CredentialProvider.Credentials()
+	if err != nil {
+		return fmt.Errorf("Could not resolve credentials. %w", err)
+	}
+
+	c.User = creds.User
+	c.Password = creds.Password
+	if c.AccountID == 0 {
+		c.AccountID = creds.AccountID
+	}
+
+	return nil
+}
@@ -0,0 +1,129 @@
+package client
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func TestCredentialChain(t *testing.T) {
+	t.Setenv(ENV_USER, "")
+
+	file := filepath.Join(t.TempDir(), "credentials.json")
+	ioutil.WriteFile(file, []byte(`{"user":"fileuser","password":"filepassword","account_id":2}`), 0600)
+
+	chain := ChainCredentials(EnvCredentials(), FileCredentials(file))
+
+	creds, err := chain.Credentials()
+	if err != nil || creds.User != "fileuser" || creds.AccountID != 2 {
+		t.Errorf("Expected credentials from file. Got %+v, %v", creds, err)
+	}
+
+	t.Setenv(ENV_USER, "envuser")
+	t.Setenv(ENV_PASSWORD, "envpassword")
+	t.Setenv(ENV_ACCOUNT_ID, "3")
+
+	creds, err = chain.Credentials()
+	if err != nil || creds.User != "envuser" || creds.Password != "envpassword" || creds.AccountID != 3 {
+		t.Errorf("Expected credentials from environment. Got %+v, %v", creds, err)
+	}
+
+	t.Setenv(ENV_ACCOUNT_ID, "invalid")
+	if _, err := chain.Credentials(); err == nil {
+		t.Error("Expected an error for invalid account id but did not receive one.")
+	}
+
+	if _, err := ChainCredentials(FileCredentials(filepath.Join(t.TempDir(), "missing.json"))).Credentials(); err != ErrNoCredentials {
+		t.Errorf("Expected no credentials error, got %v", err)
+	}
+}
+
+func TestCallResolvesCredentialsLazily(t *testing.T) {
+	t.Parallel()
+
+	resolved := 0
+	provider := CredentialProviderFunc(func() (Credentials, error) {
+		resolved++
+		return Credentials{User: "provideduser", Password: "providedpassword"}, nil
+	})
+
+	c := New(
+		func(c *Client) {
+			c.HTTPClient = MockClient{}
+			c.Logger = &MockLogger{}
+		},
+		WithCredentialProvider(provider),
+	)
+
+	if resolved != 0 {
+		t.Error("Did not expect credentials to be resolved before the first call.")
+	}
+
+	for i := 0; i < 2; i++ {
+		r := httptest.NewRequest(HTTP_GET, "http://someurl.test", nil)
+		r.RequestURI = ""
+		if _, err := c.Call(r); err != nil {
+			t.Errorf("Received an error but did not expect one: %v", err)
+		}
+
+		if r.Header.Get("Authorization") != "Basic "+b64enc("provideduser;:providedpassword") {
+			t.Error("Expected provided credentials to be used.")
+		}
+	}
+
+	if resolved != 1 {
+		t.Errorf("Expected credentials to be resolved once, got %d", resolved)
+	}
+
+	// Explicit credentials take precedence.
+	c = New(
+		func(c *Client) {
+			c.User = "explicituser"
+			c.HTTPClient = MockClient{}
+			c.Logger = &MockLogger{}
+		},
+		WithCredentialProvider(provider),
+	)
+	r := httptest.NewRequest(HTTP_GET, "http://someurl.test", nil)
+	r.RequestURI = ""
+	c.Call(r)
+
+	if resolved != 1 || c.User != "explicituser" {
+		t.Error("Did not expect provider to be used when credentials are set explicitly.")
+	}
+}
+
+func TestNewResolvesDefaultCredentialChain(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(ENV_USER, "")
+
+	c := New(func(c *Client) {
+		c.HTTPClient = MockClient{}
+		c.Logger = &MockLogger{}
+	})
+
+	r := httptest.NewRequest(HTTP_GET, "http://someurl.test", nil)
+	r.RequestURI = ""
+	if _, err := c.Call(r); err != nil {
+		t.Errorf("Expected client without credentials to call. Received error: %v", err)
+	}
+
+	t.Setenv(ENV_USER, "envuser")
+	t.Setenv(ENV_PASSWORD, "envpassword")
+
+	c = New(func(c *Client) {
+		c.HTTPClient = MockClient{}
+		c.Logger = &MockLogger{}
+	})
+
+	r = httptest.NewRequest(HTTP_GET, "http://someurl.test", nil)
+	r.RequestURI = ""
+	if _, err := c.Call(r); err != nil {
+		t.Errorf("Received an error but did not expect one: %v", err)
+	}
+
+	if r.Header.Get("Authorization") != "Basic "+b64enc("envuser;:envpassword") {
+		t.Error("Expected credentials from the environment to be used.")
+	}
+}
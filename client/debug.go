@@ -0,0 +1,103 @@
+// Copyright 2018 Publit Sweden AB. All rights reserved.
+
+package client
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+)
+
+// DEFAULT_DEBUG_MAX_BODY is the number of bytes of a body included in debug dumps if not set by WithDebug.
+const DEFAULT_DEBUG_MAX_BODY = 4096
+
+// DEBUG_SCRUBBED replaces the values of credential headers in debug dumps.
+const DEBUG_SCRUBBED = "[SCRUBBED]"
+
+// Headers carrying credentials, scrubbed from debug dumps.
+var debugScrubHeaders = []string{"Authorization", "Proxy-Authorization", "Token", "Cookie", "Set-Cookie"}
+
+// WithDebug makes the client log dumps of requests and responses at Debug level, see Client.Debug.
+// Bodies are truncated after maxBody bytes. A maxBody of 0 defaults to DEFAULT_DEBUG_MAX_BODY.
+func WithDebug(maxBody int) func(c *Client) {
+	return func(c *Client) {
+		if maxBody == 0 {
+			maxBody = DEFAULT_DEBUG_MAX_BODY
+		}
+		c.Debug = true
+		c.DebugMaxBody = maxBody
+	}
+}
+
+// debugRequest logs a dump of the request, with credentials scrubbed.
+func (c *Client) debugRequest(r *http.Request) {
+	dr := r.Clone(r.Context())
+	dr.Header = scrubHeaders(r.Header)
+
+	dump, err := httputil.DumpRequestOut(dr, false)
+	if err != nil {
+		c.GetLogger().Debug(fmt.Sprintf("Could not dump request. %v", err))
+		return
+	}
+
+	body := c.peekBody(&r.Body)
+	c.GetLogger().Debug(fmt.Sprintf("Request:\n%s%s", dump, body))
+}
+
+// debugResponse logs a dump of the response, with credentials scrubbed.
+func (c *Client) debugResponse(resp *http.Response) {
+	dr := *resp
+	dr.Header = scrubHeaders(resp.Header)
+
+	dump, err := httputil.DumpResponse(&dr, false)
+	if err != nil {
+		c.GetLogger().Debug(fmt.Sprintf("Could not dump response. %v", err))
+		return
+	}
+
+	body := c.peekBody(&resp.Body)
+	c.GetLogger().Debug(fmt.Sprintf("Response:\n%s%s", dump, body))
+}
+
+// peekBody returns the start of the body, truncated after DebugMaxBody bytes, and puts the read bytes back into it.
+func (c *Client) peekBody(body *io.ReadCloser) string {
+	if *body == nil || *body == http.NoBody {
+		return ""
+	}
+
+	max := c.DebugMaxBody
+	if max <= 0 {
+		max = DEFAULT_DEBUG_MAX_BODY
+	}
+
+	prefix, err := io.ReadAll(io.LimitReader(*body, int64(max)+1))
+	*body = readCloser{Reader: io.MultiReader(bytes.NewReader(prefix), *body), Closer: *body}
+
+	if err != nil {
+		return fmt.Sprintf("[could not read body: %v]", err)
+	}
+
+	if len(prefix) > max {
+		return fmt.Sprintf("%s... [truncated]", prefix[:max])
+	}
+	return string(prefix)
+}
+
+// readCloser combines a Reader with the Closer of another body.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// scrubHeaders returns a copy of the header with the credential headers scrubbed.
+func scrubHeaders(h http.Header) http.Header {
+	h = h.Clone()
+	for _, k := range debugScrubHeaders {
+		if _, ok := h[k]; ok {
+			h.Set(k, DEBUG_SCRUBBED)
+		}
+	}
+	return h
+}
@@ -0,0 +1,79 @@
+package client
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDebugDumpsRequestsAndResponses(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		w.Header().Set("Token", "secrettoken")
+		w.Write(b)
+	}))
+	defer ts.Close()
+
+	dumps := []string{}
+	c := New(
+		WithDebug(8),
+		func(c *Client) {
+			c.User = "someuser"
+			c.Password = "secretpassword"
+			c.Logger = &MockLogger{DebugCallback: func(message interface{}) {
+				dumps = append(dumps, fmt.Sprint(message))
+			}}
+		},
+	)
+
+	r, _ := http.NewRequest(HTTP_POST, ts.URL, strings.NewReader("somerequestbody"))
+	resp, err := c.Call(r)
+	if err != nil {
+		t.Fatalf("Received an error but did not expect one: %v", err)
+	}
+
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "somerequestbody" {
+		t.Errorf("Expected bodies to be intact after dumping, got %q", body)
+	}
+
+	if len(dumps) != 2 {
+		t.Fatalf("Expected request and response dumps, got %v", dumps)
+	}
+
+	for _, d := range dumps {
+		if !strings.Contains(d, "somerequ... [truncated]") {
+			t.Errorf("Expected truncated body in dump: %s", d)
+		}
+		if strings.Contains(d, "secret") || !strings.Contains(d, DEBUG_SCRUBBED) {
+			t.Errorf("Expected credentials to be scrubbed from dump: %s", d)
+		}
+	}
+}
+
+func TestDebugIsOptIn(t *testing.T) {
+	t.Parallel()
+	debugs := 0 // Number of dumps logged
+	c := New(func(c *Client) {
+		c.HTTPClient = MockClient{}
+		c.Logger = &MockLogger{DebugCallback: func(message interface{}) {
+			if m := fmt.Sprint(message); strings.HasPrefix(m, "Request:") || strings.HasPrefix(m, "Response:") {
+				debugs++
+			}
+		}}
+	})
+
+	r := httptest.NewRequest(HTTP_GET, "http://someurl.test", nil)
+	r.RequestURI = ""
+	c.Call(r)
+
+	if debugs != 0 {
+		t.Errorf("Expected no dumps to be logged, got %d", debugs)
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright 2018 Publit Sweden AB. All rights reserved.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"net"
+)
+
+// DialFunc makes a connection to the address on the named network, see net.Dialer.DialContext.
+type DialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// WithDialContext sets Client.DialContext.
+func WithDialContext(dial DialFunc) func(c *Client) {
+	return func(c *Client) {
+		c.DialContext = dial
+	}
+}
+
+// WithPinnedAddresses pins connections to host to the given addresses, e.g. the IPs of a regional endpoint.
+// Addresses without port use the port of the request. The addresses are tried in order until a connection is made.
+// TLS is still verified against host. With a proxy, only connections to a pinned proxy host are affected.
+func WithPinnedAddresses(host string, addrs ...string) func(c *Client) {
+	return func(c *Client) {
+		if c.PinnedAddresses == nil {
+			c.PinnedAddresses = map[string][]string{}
+		}
+		c.PinnedAddresses[host] = append(c.PinnedAddresses[host], addrs...)
+	}
+}
+
+// pinnedDial dials the pinned addresses of a host instead of the host, and other addresses with dial.
+func pinnedDial(pinned map[string][]string, dial DialFunc) DialFunc {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return dial(ctx, network, addr)
+		}
+
+		addrs, ok := pinned[host]
+		if !ok || len(addrs) == 0 {
+			return dial(ctx, network, addr)
+		}
+
+		var lastErr error
+		for _, a := range addrs {
+			if _, _, err := net.SplitHostPort(a); err != nil {
+				a = net.JoinHostPort(a, port)
+			}
+
+			conn, err := dial(ctx, network, a)
+			if err == nil {
+				return conn, nil
+			}
+			lastErr = err
+		}
+
+		return nil, fmt.Errorf("Could not connect to any pinned address of %s. %w", host, lastErr)
+	}
+}
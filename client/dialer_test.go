@@ -0,0 +1,64 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPinnedAddressesAreDialed(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Host))
+	}))
+	defer ts.Close()
+
+	_, port, _ := net.SplitHostPort(strings.TrimPrefix(ts.URL, "http://"))
+
+	dialed := []string{}
+	c := New(
+		WithDialContext(func(ctx context.Context, network, addr string) (net.Conn, error) {
+			dialed = append(dialed, addr)
+			if strings.HasPrefix(addr, "127.0.0.2") {
+				return nil, errors.New("connection refused")
+			}
+			return (&net.Dialer{}).DialContext(ctx, network, addr)
+		}),
+		WithPinnedAddresses("api.publit.test", "127.0.0.2", "127.0.0.1"),
+		func(c *Client) { c.Logger = &MockLogger{} },
+	)
+
+	r, _ := http.NewRequest(HTTP_GET, "http://api.publit.test:"+port, nil)
+	resp, err := c.CallRaw(r)
+	if err != nil {
+		t.Fatalf("Received an error but did not expect one: %v", err)
+	}
+	resp.Body.Close()
+
+	if len(dialed) != 2 || dialed[0] != "127.0.0.2:"+port || dialed[1] != "127.0.0.1:"+port {
+		t.Errorf("Expected pinned addresses to be dialed in order, got %v", dialed)
+	}
+}
+
+func TestPinnedDialKeepsOtherHosts(t *testing.T) {
+	t.Parallel()
+
+	dialed := ""
+	dial := pinnedDial(map[string][]string{"api.publit.test": {"10.0.0.1:8443"}}, func(ctx context.Context, network, addr string) (net.Conn, error) {
+		dialed = addr
+		return nil, errors.New("not connected")
+	})
+
+	dial(context.Background(), "tcp", "other.publit.test:443")
+	if dialed != "other.publit.test:443" {
+		t.Errorf("Expected other hosts to be dialed as is, got %q", dialed)
+	}
+
+	if _, err := dial(context.Background(), "tcp", "api.publit.test:443"); dialed != "10.0.0.1:8443" || err == nil {
+		t.Errorf("Expected pinned address with port to be dialed, got %q", dialed)
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright 2018 Publit Sweden AB. All rights reserved.
+
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/publitsweden/APIUtilityGoSDK/APILog"
+)
+
+// Environment variables read by NewFromEnv, in addition to the ones read by EnvCredentials.
+const (
+	ENV_TOKEN     = "PUBLIT_TOKEN"
+	ENV_BASE_URL  = "PUBLIT_BASE_URL"
+	ENV_LOG_LEVEL = "PUBLIT_LOG_LEVEL"
+)
+
+// Log levels accepted in PUBLIT_LOG_LEVEL.
+const (
+	LOG_LEVEL_DEBUG = "debug"
+	LOG_LEVEL_INFO  = "info"
+	LOG_LEVEL_NONE  = "none"
+)
+
+// NewFromEnv creates a new client configured from the environment.
+// Reads PUBLIT_USER, PUBLIT_PASSWORD, PUBLIT_ACCOUNT_ID, PUBLIT_TOKEN, PUBLIT_BASE_URL, PUBLIT_ENVIRONMENT and
+// PUBLIT_LOG_LEVEL. PUBLIT_ENVIRONMENT names an environment, such as "production", used if PUBLIT_BASE_URL is not set.
+// PUBLIT_USER is required together with either PUBLIT_PASSWORD or PUBLIT_TOKEN.
+// The configFunc are applied after the environment, see New, and the log level wraps the resulting Logger.
+func NewFromEnv(configFunc ...func(c *Client)) (*Client, error) {
+	creds, err := EnvCredentials().Credentials()
+	if errors.Is(err, ErrNoCredentials) {
+		return nil, fmt.Errorf("%s is not set", ENV_USER)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	token := os.Getenv(ENV_TOKEN)
+	if creds.Password == "" && token == "" {
+		return nil, fmt.Errorf("Either %s or %s must be set", ENV_PASSWORD, ENV_TOKEN)
+	}
+
+	baseURL := os.Getenv(ENV_BASE_URL)
+	if err := validateBaseURL(baseURL); err != nil {
+		return nil, fmt.Errorf("Invalid %s. %v", ENV_BASE_URL, err)
+	}
+
+	if env := os.Getenv(ENV_ENVIRONMENT); baseURL == "" && env != "" {
+		if baseURL, err = LookupEnvironment(Environment(env)); err != nil {
+			return nil, fmt.Errorf("Invalid %s. %v", ENV_ENVIRONMENT, err)
+		}
+	}
+
+	level, err := parseLogLevel(os.Getenv(ENV_LOG_LEVEL))
+	if err != nil {
+		return nil, fmt.Errorf("Invalid %s. %v", ENV_LOG_LEVEL, err)
+	}
+
+	config := []func(c *Client){
+		func(c *Client) {
+			c.User = creds.User
+			c.Password = creds.Password
+			c.AccountID = creds.AccountID
+			c.Token = token
+			c.BaseURL = baseURL
+		},
+	}
+	config = append(config, configFunc...)
+
+	// The log level applies to any logger set by configFunc.
+	if level != nil {
+		config = append(config, WithLogLevel(*level))
+	}
+
+	c := New(config...)
+	if err := c.ConfigError(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// WithLogLevel makes the client only log messages of the given level, eg. APILog.LEVEL_INFO.
+// Wraps the Logger of the client, which is created by New if not set.
+func WithLogLevel(level APILog.LogLevel) func(c *Client) {
+	return func(c *Client) {
+		if c.Logger == nil {
+			c.Logger = APILog.New()
+		}
+		c.Logger = leveledLogger{Logger: c.Logger, level: level}
+	}
+}
+
+// leveledLogger drops messages not matching its level.
+type leveledLogger struct {
+	Logger
+	level APILog.LogLevel
+}
+
+// Debug logs the message if the level includes APILog.LEVEL_DEBUG.
+func (l leveledLogger) Debug(message interface{}) {
+	if l.level.HasLevel(APILog.LEVEL_DEBUG) {
+		l.Logger.Debug(message)
+	}
+}
+
+// Info logs the message if the level includes APILog.LEVEL_INFO.
+func (l leveledLogger) Info(message interface{}) {
+	if l.level.HasLevel(APILog.LEVEL_INFO) {
+		l.Logger.Info(message)
+	}
+}
+
+// parseLogLevel parses one of the LOG_LEVEL_* strings. Returns nil for an empty string.
+func parseLogLevel(s string) (*APILog.LogLevel, error) {
+	var level APILog.LogLevel
+
+	switch strings.ToLower(s) {
+	case "":
+		return nil, nil
+	case LOG_LEVEL_DEBUG:
+		level = APILog.LEVEL_INFO | APILog.LEVEL_DEBUG
+	case LOG_LEVEL_INFO:
+		level = APILog.LEVEL_INFO
+	case LOG_LEVEL_NONE:
+		level = 0
+	default:
+		return nil, fmt.Errorf("Unknown log level %q, expected one of %s, %s or %s", s, LOG_LEVEL_DEBUG, LOG_LEVEL_INFO, LOG_LEVEL_NONE)
+	}
+
+	return &level, nil
+}
+
+// validateBaseURL checks that a non-empty base URL is an absolute http(s) URL.
+func validateBaseURL(baseURL string) error {
+	if baseURL == "" {
+		return nil
+	}
+
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("%q is not an absolute http(s) URL", baseURL)
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package client
+
+import (
+	"testing"
+)
+
+func setEnv(t *testing.T, env map[string]string) {
+	for _, k := range []string{ENV_USER, ENV_PASSWORD, ENV_ACCOUNT_ID, ENV_TOKEN, ENV_BASE_URL, ENV_ENVIRONMENT, ENV_LOG_LEVEL} {
+		t.Setenv(k, env[k])
+	}
+}
+
+func TestNewFromEnv(t *testing.T) {
+	setEnv(t, map[string]string{
+		ENV_USER:       "envuser",
+		ENV_PASSWORD:   "envpassword",
+		ENV_ACCOUNT_ID: "12",
+		ENV_BASE_URL:   "https://api.publit.test",
+		ENV_LOG_LEVEL:  "info",
+	})
+
+	c, err := NewFromEnv()
+	if err != nil {
+		t.Fatalf("Received an error but did not expect one: %v", err)
+	}
+
+	if c.User != "envuser" || c.Password != "envpassword" || c.AccountID != 12 || c.BaseURL != "https://api.publit.test" {
+		t.Errorf("Client was not configured from environment: %+v", c)
+	}
+
+	debugs, infos := 0, 0
+	c, _ = NewFromEnv(func(c *Client) {
+		c.Logger = &MockLogger{
+			DebugCallback: func(message interface{}) { debugs++ },
+			InfoCallback:  func(message interface{}) { infos++ },
+		}
+	})
+	c.Logger.Debug("Some debug information")
+	c.Logger.Info("Some information")
+	if debugs != 0 || infos != 1 {
+		t.Errorf("Expected only info messages to be logged at info level, got %d debug and %d info.", debugs, infos)
+	}
+}
+
+func TestNewFromEnvValidates(t *testing.T) {
+	tests := map[string]map[string]string{
+		"missing user":       {ENV_PASSWORD: "pw"},
+		"missing password":   {ENV_USER: "user"},
+		"invalid account id": {ENV_USER: "user", ENV_PASSWORD: "pw", ENV_ACCOUNT_ID: "abc"},
+		"invalid base url":   {ENV_USER: "user", ENV_PASSWORD: "pw", ENV_BASE_URL: "api.publit.test"},
+		"invalid log level":  {ENV_USER: "user", ENV_PASSWORD: "pw", ENV_LOG_LEVEL: "verbose"},
+	}
+
+	for name, env := range tests {
+		t.Run(name, func(t *testing.T) {
+			setEnv(t, env)
+			if _, err := NewFromEnv(); err == nil {
+				t.Error("Expected an error but did not receive one.")
+			}
+		})
+	}
+
+	setEnv(t, map[string]string{ENV_USER: "user", ENV_TOKEN: "sometoken"})
+	c, err := NewFromEnv()
+	if err != nil || c.Token != "sometoken" {
+		t.Errorf("Expected token from environment, got %v", err)
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright 2018 Publit Sweden AB. All rights reserved.
+
+package client
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Environment names a Publit environment, see LookupEnvironment.
+// ENVIRONMENT_PRODUCTION is preset. Publit documents no public staging or sandbox hosts, so there are no presets for
+// them; register such environments with RegisterEnvironment.
+type Environment string
+
+// Environment presets.
+const ENVIRONMENT_PRODUCTION Environment = "production"
+
+// Base URL of the Publit production APIs, preset as ENVIRONMENT_PRODUCTION.
+const BASE_URL_PRODUCTION = "https://api.publit.com"
+
+// Environment variable selecting the environment used by NewFromEnv if PUBLIT_BASE_URL is not set.
+const ENV_ENVIRONMENT = "PUBLIT_ENVIRONMENT"
+
+var (
+	environmentsMu sync.RWMutex
+	environments   = map[Environment]string{
+		ENVIRONMENT_PRODUCTION: BASE_URL_PRODUCTION,
+	}
+)
+
+// RegisterEnvironment adds an environment, or replaces the base URL of a registered one or a preset.
+func RegisterEnvironment(name Environment, baseURL string) {
+	environmentsMu.Lock()
+	defer environmentsMu.Unlock()
+
+	environments[Environment(strings.ToLower(string(name)))] = baseURL
+}
+
+// LookupEnvironment returns the base URL of the environment. Names are case insensitive.
+func LookupEnvironment(name Environment) (string, error) {
+	environmentsMu.RLock()
+	defer environmentsMu.RUnlock()
+
+	baseURL, ok := environments[Environment(strings.ToLower(string(name)))]
+	if !ok {
+		return "", fmt.Errorf("Unknown Publit environment %q", name)
+	}
+	return baseURL, nil
+}
+
+// WithEnvironment sets Client.BaseURL to the base URL of the environment.
+// An unknown environment leaves BaseURL unchanged and is reported by Client.ConfigError.
+func WithEnvironment(name Environment) func(c *Client) {
+	return func(c *Client) {
+		baseURL, err := LookupEnvironment(name)
+		if err != nil {
+			c.configErr = err
+			return
+		}
+		c.BaseURL = baseURL
+	}
+}
+
+// ConfigError returns the error of a config func given to New, such as WithEnvironment with an unknown environment.
+func (c *Client) ConfigError() error {
+	return c.configErr
+}
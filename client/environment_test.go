@@ -0,0 +1,70 @@
+package client
+
+import (
+	"testing"
+)
+
+func TestEnvironments(t *testing.T) {
+	if baseURL, err := LookupEnvironment("Production"); err != nil || baseURL != BASE_URL_PRODUCTION {
+		t.Errorf("Expected production preset, got %q, %v", baseURL, err)
+	}
+
+	RegisterEnvironment("Local", "http://localhost:8080")
+	baseURL, err := LookupEnvironment("local")
+	if err != nil || baseURL != "http://localhost:8080" {
+		t.Errorf("Expected base URL of registered environment, got %q, %v", baseURL, err)
+	}
+
+	c := New(WithEnvironment("local"))
+	if c.BaseURL != "http://localhost:8080" || c.ConfigError() != nil {
+		t.Errorf("Expected base URL of registered environment, got %q, %v", c.BaseURL, c.ConfigError())
+	}
+
+	c = New(func(c *Client) { c.BaseURL = "https://api.publit.test" }, WithEnvironment("moon"))
+	if c.BaseURL != "https://api.publit.test" {
+		t.Errorf("Expected unknown environment to leave base URL unchanged, got %q", c.BaseURL)
+	}
+	if c.ConfigError() == nil {
+		t.Error("Expected an error for unknown environment but did not receive one.")
+	}
+}
+
+func TestNewFromEnvSelectsEnvironment(t *testing.T) {
+	RegisterEnvironment("env-test", "https://env.publit.test")
+	setEnv(t, map[string]string{ENV_USER: "user", ENV_PASSWORD: "pw"})
+	t.Setenv(ENV_ENVIRONMENT, "env-test")
+
+	c, err := NewFromEnv()
+	if err != nil {
+		t.Fatalf("Received an error but did not expect one: %v", err)
+	}
+
+	if c.BaseURL != "https://env.publit.test" {
+		t.Errorf("Expected base URL of environment, got %q", c.BaseURL)
+	}
+
+	t.Setenv(ENV_ENVIRONMENT, "moon")
+	if _, err := NewFromEnv(); err == nil {
+		t.Error("Expected an error for unknown environment but did not receive one.")
+	}
+
+	t.Setenv(ENV_ENVIRONMENT, "")
+	if _, err := NewFromEnv(WithEnvironment("moon")); err == nil {
+		t.Error("Expected an error for unknown environment given to NewFromEnv but did not receive one.")
+	}
+}
+
+func TestConfigProfileSelectsEnvironment(t *testing.T) {
+	RegisterEnvironment("config-test", "https://config.publit.test")
+	t.Setenv(ENV_PROFILE, "")
+	path := writeConfig(t, "publit.json", `{"profiles":{"default":{"environment":"config-test","user":"someuser"}}}`)
+
+	c, err := NewFromConfig(path)
+	if err != nil {
+		t.Fatalf("Received an error but did not expect one: %v", err)
+	}
+
+	if c.BaseURL != "https://config.publit.test" {
+		t.Errorf("Expected base URL of environment, got %q", c.BaseURL)
+	}
+}
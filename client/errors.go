@@ -0,0 +1,51 @@
+// Copyright 2018 Publit Sweden AB. All rights reserved.
+
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Authentication errors. Check returned errors against these with errors.Is.
+var (
+	// ErrAuthenticationFailed is returned when the Publit API rejects the credentials of the client.
+	ErrAuthenticationFailed = errors.New("Authentication failed")
+	// ErrTokenMissing is returned when a token was expected in a response but none was received.
+	ErrTokenMissing = errors.New("No token received in header. Could not set token from response.")
+)
+
+// TransportError is returned when a request fails without a response from the Publit API, e.g. due to a network
+// failure, a timeout or a middleware stopping the request. Use errors.As to retrieve it, or errors.Is to check the cause.
+type TransportError struct {
+	// Method and URL of the failed request.
+	Method string
+	URL    string
+	// Err is the cause of the failure.
+	Err error
+}
+
+// Error returns the error message of the TransportError.
+func (e *TransportError) Error() string {
+	return fmt.Sprintf(`Request failed. Method: "%v", URL: "%v", Error: "%v"`, e.Method, e.URL, e.Err)
+}
+
+// Unwrap returns the cause of the failure.
+func (e *TransportError) Unwrap() error {
+	return e.Err
+}
+
+// NewTransportError wraps err in a TransportError for the request, unless err already is one.
+func NewTransportError(r *http.Request, err error) error {
+	var te *TransportError
+	if errors.As(err, &te) {
+		return err
+	}
+
+	e := &TransportError{Method: r.Method, Err: err}
+	if r.URL != nil {
+		e.URL = r.URL.String()
+	}
+	return e
+}
@@ -0,0 +1,58 @@
+// Copyright 2018 Publit Sweden AB. All rights reserved.
+
+package client
+
+import (
+	"net/http"
+)
+
+// RequestHook is called with every request before it is performed. It may modify the request, eg. set headers.
+type RequestHook func(r *http.Request)
+
+// ResponseHook is called with every response received.
+type ResponseHook func(r *http.Request, resp *http.Response)
+
+// ErrorHook is called with every request failing without a response, or failing to authenticate.
+type ErrorHook func(r *http.Request, err error)
+
+// WithOnRequest adds hooks to Client.OnRequest.
+func WithOnRequest(hooks ...RequestHook) func(c *Client) {
+	return func(c *Client) {
+		c.OnRequest = append(c.OnRequest, hooks...)
+	}
+}
+
+// WithOnResponse adds hooks to Client.OnResponse.
+func WithOnResponse(hooks ...ResponseHook) func(c *Client) {
+	return func(c *Client) {
+		c.OnResponse = append(c.OnResponse, hooks...)
+	}
+}
+
+// WithOnError adds hooks to Client.OnError.
+func WithOnError(hooks ...ErrorHook) func(c *Client) {
+	return func(c *Client) {
+		c.OnError = append(c.OnError, hooks...)
+	}
+}
+
+// onRequest calls the OnRequest hooks in order.
+func (c *Client) onRequest(r *http.Request) {
+	for _, h := range c.OnRequest {
+		h(r)
+	}
+}
+
+// onResponse calls the OnResponse hooks in order.
+func (c *Client) onResponse(r *http.Request, resp *http.Response) {
+	for _, h := range c.OnResponse {
+		h(r, resp)
+	}
+}
+
+// onError calls the OnError hooks in order.
+func (c *Client) onError(r *http.Request, err error) {
+	for _, h := range c.OnError {
+		h(r, err)
+	}
+}
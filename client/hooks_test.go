@@ -0,0 +1,57 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCallInvokesHooks(t *testing.T) {
+	t.Parallel()
+
+	calls := []string{}
+	c := New(
+		WithOnRequest(
+			func(r *http.Request) { calls = append(calls, "request 1") },
+			func(r *http.Request) { r.Header.Set("X-Trace", "sometrace") },
+		),
+		WithOnResponse(func(r *http.Request, resp *http.Response) { calls = append(calls, "response") }),
+		WithOnError(func(r *http.Request, err error) { calls = append(calls, "error") }),
+		func(c *Client) {
+			c.HTTPClient = MockClient{}
+			c.Logger = &MockLogger{}
+		},
+	)
+
+	r := httptest.NewRequest(HTTP_GET, "http://someurl.test", nil)
+	r.RequestURI = ""
+	c.Call(r)
+
+	if len(calls) != 2 || calls[0] != "request 1" || calls[1] != "response" {
+		t.Errorf("Unexpected hook calls: %v", calls)
+	}
+	if r.Header.Get("X-Trace") != "sometrace" {
+		t.Error("Expected request hook to be able to set headers.")
+	}
+
+	calls = nil
+	c.HTTPClient = DoerFunc(func(r *http.Request) (*http.Response, error) { return nil, errors.New("connection refused") })
+	r = httptest.NewRequest(HTTP_GET, "http://someurl.test", nil)
+	r.RequestURI = ""
+	c.Call(r)
+
+	if len(calls) != 2 || calls[1] != "error" {
+		t.Errorf("Expected error hook to be called on transport error, got %v", calls)
+	}
+
+	calls = nil
+	c.CredentialProvider = CredentialProviderFunc(func() (Credentials, error) { return Credentials{}, ErrNoCredentials })
+	r = httptest.NewRequest(HTTP_GET, "http://someurl.test", nil)
+	r.RequestURI = ""
+	c.Call(r)
+
+	if len(calls) != 1 || calls[0] != "error" {
+		t.Errorf("Expected error hook to be called on auth error, got %v", calls)
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2018 Publit Sweden AB. All rights reserved.
+
+package client
+
+import (
+	"errors"
+)
+
+// DEFAULT_KEYRING_SERVICE is the service name secrets are stored under in the OS keyring if none is given.
+const DEFAULT_KEYRING_SERVICE = "publit"
+
+// ErrKeyringNotFound is returned by a Keyring when it holds no secret for the service and user.
+var ErrKeyringNotFound = errors.New("Secret not found in keyring")
+
+// Keyring stores secrets in the keychain of the OS, e.g. macOS Keychain, Windows Credential Manager or Secret Service.
+// The package has no keyring implementation of its own, to keep the dependency optional. Adapt a keyring package
+// in the application, e.g. github.com/zalando/go-keyring:
+//
+//	type osKeyring struct{}
+//
+//	func (osKeyring) Get(service, user string) (string, error) {
+//		secret, err := keyring.Get(service, user)
+//		if errors.Is(err, keyring.ErrNotFound) {
+//			return "", client.ErrKeyringNotFound
+//		}
+//		return secret, err
+//	}
+//
+//	func (osKeyring) Set(service, user, secret string) error { return keyring.Set(service, user, secret) }
+//	func (osKeyring) Delete(service, user string) error      { return keyring.Delete(service, user) }
+type Keyring interface {
+	// Get returns the secret, or ErrKeyringNotFound if there is none.
+	Get(service, user string) (string, error)
+	Set(service, user, secret string) error
+	Delete(service, user string) error
+}
+
+// KeyringTokenStore is a TokenStore keeping tokens in a Keyring, with the token key as user.
+type KeyringTokenStore struct {
+	Keyring Keyring
+	// Service is the service name of the tokens in the keyring.
+	Service string
+}
+
+// NewKeyringTokenStore creates a KeyringTokenStore. An empty service defaults to DEFAULT_KEYRING_SERVICE.
+func NewKeyringTokenStore(k Keyring, service string) *KeyringTokenStore {
+	if service == "" {
+		service = DEFAULT_KEYRING_SERVICE
+	}
+	return &KeyringTokenStore{Keyring: k, Service: service}
+}
+
+func (s *KeyringTokenStore) Get(key string) (string, error) {
+	token, err := s.Keyring.Get(s.Service, key)
+	if errors.Is(err, ErrKeyringNotFound) {
+		return "", ErrTokenNotFound
+	}
+	return token, err
+}
+
+func (s *KeyringTokenStore) Set(key, token string) error {
+	return s.Keyring.Set(s.Service, key, token)
+}
+
+func (s *KeyringTokenStore) Delete(key string) error {
+	err := s.Keyring.Delete(s.Service, key)
+	if errors.Is(err, ErrKeyringNotFound) {
+		return nil
+	}
+	return err
+}
+
+// KeyringCredentials gives the credentials of the user, with the password read from the keyring.
+// An empty service defaults to DEFAULT_KEYRING_SERVICE. Has no credentials if the keyring has no password for the user.
+func KeyringCredentials(k Keyring, service, user string, accountID int) CredentialProvider {
+	if service == "" {
+		service = DEFAULT_KEYRING_SERVICE
+	}
+
+	return CredentialProviderFunc(func() (Credentials, error) {
+		password, err := k.Get(service, user)
+		if errors.Is(err, ErrKeyringNotFound) {
+			return Credentials{}, ErrNoCredentials
+		}
+		if err != nil {
+			return Credentials{}, err
+		}
+
+		return Credentials{User: user, Password: password, AccountID: accountID}, nil
+	})
+}
@@ -0,0 +1,81 @@
+package client
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+// MockKeyring is an in-memory Keyring.
+type MockKeyring map[string]string
+
+func (k MockKeyring) Get(service, user string) (string, error) {
+	secret, ok := k[service+"/"+user]
+	if !ok {
+		return "", ErrKeyringNotFound
+	}
+	return secret, nil
+}
+
+func (k MockKeyring) Set(service, user, secret string) error {
+	k[service+"/"+user] = secret
+	return nil
+}
+
+func (k MockKeyring) Delete(service, user string) error {
+	if _, ok := k[service+"/"+user]; !ok {
+		return ErrKeyringNotFound
+	}
+	delete(k, service+"/"+user)
+	return nil
+}
+
+func TestKeyringTokenStore(t *testing.T) {
+	t.Parallel()
+	k := MockKeyring{}
+	s := NewKeyringTokenStore(k, "")
+
+	if _, err := s.Get("someuser;1"); err != ErrTokenNotFound {
+		t.Errorf("Expected token not found error, got %v", err)
+	}
+
+	s.Set("someuser;1", "sometoken")
+	if k[DEFAULT_KEYRING_SERVICE+"/someuser;1"] != "sometoken" {
+		t.Errorf("Expected token in keyring under default service, got %v", k)
+	}
+
+	if token, err := s.Get("someuser;1"); err != nil || token != "sometoken" {
+		t.Errorf("Expected stored token, got %q, %v", token, err)
+	}
+
+	if err := s.Delete("someuser;1"); err != nil {
+		t.Errorf("Received an error but did not expect one: %v", err)
+	}
+	if err := s.Delete("someuser;1"); err != nil {
+		t.Errorf("Did not expect an error deleting a missing token, got %v", err)
+	}
+}
+
+func TestKeyringCredentials(t *testing.T) {
+	t.Parallel()
+	k := MockKeyring{"someservice/someuser": "somepassword"}
+
+	if _, err := KeyringCredentials(k, "someservice", "otheruser", 0).Credentials(); err != ErrNoCredentials {
+		t.Errorf("Expected no credentials error, got %v", err)
+	}
+
+	c := New(
+		WithCredentialProvider(KeyringCredentials(k, "someservice", "someuser", 2)),
+		func(c *Client) {
+			c.HTTPClient = MockClient{}
+			c.Logger = &MockLogger{}
+		},
+	)
+
+	r := httptest.NewRequest(HTTP_GET, "http://someurl.test", nil)
+	r.RequestURI = ""
+	c.Call(r)
+
+	if r.Header.Get("Authorization") != "Basic "+b64enc("someuser;2:somepassword") {
+		t.Error("Expected credentials from keyring to be used.")
+	}
+}
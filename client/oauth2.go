@@ -0,0 +1,178 @@
+// Copyright 2018 Publit Sweden AB. All rights reserved.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// OAuth2Config configures authentication with access tokens from an OAuth2 client credentials flow.
+type OAuth2Config struct {
+	// TokenURL is the URL of the token endpoint of the authorization server.
+	TokenURL string
+	// ClientID and ClientSecret are the credentials of the client, sent with basic auth to the token endpoint.
+	ClientID     string
+	ClientSecret string
+	// Scopes are the requested scopes, if any.
+	Scopes []string
+}
+
+// oauth2Token is an access token received from the token endpoint.
+type oauth2Token struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"`
+	expiry      time.Time
+}
+
+// oauth2Fetch is an access token request shared by the calls waiting for it.
+type oauth2Fetch struct {
+	done  chan struct{}
+	token *oauth2Token
+	err   error
+}
+
+// WithOAuth2 makes the client authenticate requests with access tokens obtained with the OAuth2 client credentials flow,
+// instead of Publit basic auth and tokens. Access tokens are requested on the first call and refreshed before they expire,
+// see Client.RefreshSkew. Access tokens rejected with 401 Unauthorized are dropped, so the next call requests a new one.
+func WithOAuth2(config OAuth2Config) func(c *Client) {
+	return func(c *Client) {
+		c.OAuth2 = &config
+	}
+}
+
+// setOAuth2Auth sets a valid access token as bearer token of the request.
+func (c *Client) setOAuth2Auth(r *http.Request) error {
+	token, err := c.oauth2AccessToken(r)
+	if err != nil {
+		return err
+	}
+
+	tokenType := token.TokenType
+	if tokenType == "" || strings.EqualFold(tokenType, "bearer") {
+		tokenType = "Bearer"
+	}
+	r.Header.Set("Authorization", tokenType+" "+token.AccessToken)
+	return nil
+}
+
+// oauth2AccessToken returns the current access token, requesting a new one if it is missing or about to expire.
+// Concurrent calls share a single token request, and M is not held while it is made.
+func (c *Client) oauth2AccessToken(r *http.Request) (*oauth2Token, error) {
+	skew := c.RefreshSkew
+	if skew <= 0 {
+		skew = DEFAULT_REFRESH_SKEW
+	}
+
+	for {
+		c.M.Lock()
+		if c.oauth2Token != nil && (c.oauth2Token.expiry.IsZero() || time.Now().Add(skew).Before(c.oauth2Token.expiry)) {
+			token := c.oauth2Token
+			c.M.Unlock()
+			return token, nil
+		}
+
+		if f := c.oauth2Fetch; f != nil {
+			c.M.Unlock()
+
+			select {
+			case <-f.done:
+			case <-r.Context().Done():
+				return nil, r.Context().Err()
+			}
+
+			// Make a request of our own if the shared one was cancelled by the context of its caller.
+			if errors.Is(f.err, context.Canceled) || errors.Is(f.err, context.DeadlineExceeded) {
+				continue
+			}
+			return f.token, f.err
+		}
+
+		f := &oauth2Fetch{done: make(chan struct{})}
+		c.oauth2Fetch = f
+		c.M.Unlock()
+
+		f.token, f.err = c.requestOAuth2Token(r)
+
+		c.M.Lock()
+		if f.err == nil {
+			c.oauth2Token = f.token
+		}
+		c.oauth2Fetch = nil
+		c.M.Unlock()
+		close(f.done)
+
+		return f.token, f.err
+	}
+}
+
+// dropOAuth2Token drops the access token the request was authenticated with, if it is still the current one.
+func (c *Client) dropOAuth2Token(r *http.Request) {
+	c.M.Lock()
+	defer c.M.Unlock()
+
+	if c.oauth2Token != nil && strings.HasSuffix(r.Header.Get("Authorization"), " "+c.oauth2Token.AccessToken) {
+		c.oauth2Token = nil
+	}
+}
+
+// requestOAuth2Token requests a new access token with the client credentials grant, in the context of the request r.
+func (c *Client) requestOAuth2Token(r *http.Request) (*oauth2Token, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(c.OAuth2.Scopes) > 0 {
+		form.Set("scope", strings.Join(c.OAuth2.Scopes, " "))
+	}
+
+	req, err := http.NewRequestWithContext(r.Context(), http.MethodPost, c.OAuth2.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(c.OAuth2.ClientID), url.QueryEscape(c.OAuth2.ClientSecret))
+
+	resp, err := c.GetHTTPClient().Do(req)
+	if err != nil {
+		return nil, NewTransportError(req, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden:
+		// Rejected client credentials or grant, see RFC 6749 section 5.2.
+		return nil, fmt.Errorf("Could not get OAuth2 access token. %w. Code: \"%v\", Body: %q", ErrAuthenticationFailed, resp.StatusCode, body)
+	default:
+		return nil, fmt.Errorf("Could not get OAuth2 access token. Code: \"%v\", Body: %q", resp.StatusCode, body)
+	}
+
+	token := &oauth2Token{}
+	if err := json.Unmarshal(body, token); err != nil {
+		return nil, err
+	}
+	if token.AccessToken == "" {
+		return nil, fmt.Errorf("Could not get OAuth2 access token. %w", ErrTokenMissing)
+	}
+
+	if token.ExpiresIn > 0 {
+		token.expiry = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	}
+
+	c.GetLogger().Debug("Received new OAuth2 access token.")
+
+	return token, nil
+}
@@ -0,0 +1,188 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCallAuthenticatesWithOAuth2(t *testing.T) {
+	t.Parallel()
+
+	tokenRequests := 0
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tokenRequests++
+
+		id, secret, _ := r.BasicAuth()
+		if id != "someclient" || secret != "somesecret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		r.ParseForm()
+		if r.Form.Get("grant_type") != "client_credentials" || r.Form.Get("scope") != "read write" {
+			t.Errorf("Unexpected token request form. Got %v", r.Form)
+		}
+
+		// The first token expires within the refresh skew and is replaced on the next call.
+		expiresIn := 3600
+		if tokenRequests == 1 {
+			expiresIn = 1
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"access_token":"accesstoken%d","token_type":"bearer","expires_in":%d}`, tokenRequests, expiresIn)
+	}))
+	defer tokenServer.Close()
+
+	var authorization string
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+	}))
+	defer apiServer.Close()
+
+	c := New(
+		func(c *Client) {
+			c.Logger = &MockLogger{}
+		},
+		WithOAuth2(OAuth2Config{
+			TokenURL:     tokenServer.URL,
+			ClientID:     "someclient",
+			ClientSecret: "somesecret",
+			Scopes:       []string{"read", "write"},
+		}),
+	)
+
+	for i, expected := range []string{"Bearer accesstoken1", "Bearer accesstoken2", "Bearer accesstoken2"} {
+		r, _ := http.NewRequest(HTTP_GET, apiServer.URL, nil)
+		if _, err := c.Call(r); err != nil {
+			t.Fatalf("Received an error but did not expect one: %v", err)
+		}
+
+		if authorization != expected {
+			t.Errorf("Call %d: Unexpected Authorization header. Expected %q, got %q", i, expected, authorization)
+		}
+	}
+
+	if tokenRequests != 2 {
+		t.Errorf("Expected 2 token requests, got %d", tokenRequests)
+	}
+}
+
+func TestCallFailsIfOAuth2TokenCanNotBeObtained(t *testing.T) {
+	t.Parallel()
+
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer tokenServer.Close()
+
+	c := New(
+		func(c *Client) {
+			c.Logger = &MockLogger{}
+		},
+		WithOAuth2(OAuth2Config{TokenURL: tokenServer.URL, ClientID: "someclient", ClientSecret: "wrong"}),
+	)
+
+	r, _ := http.NewRequest(HTTP_GET, "http://someurl.test", nil)
+	if _, err := c.Call(r); !errors.Is(err, ErrAuthenticationFailed) {
+		t.Errorf("Expected authentication error, got %v", err)
+	}
+}
+
+func TestOAuth2TokenIsRequestedOnceWithoutHoldingLock(t *testing.T) {
+	t.Parallel()
+
+	var c *Client
+	var tokenRequests int32
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&tokenRequests, 1) == 1 {
+			// Other calls must be able to take the lock while the token is requested.
+			locked := make(chan struct{})
+			go func() {
+				c.M.Lock()
+				c.M.Unlock()
+				close(locked)
+			}()
+			select {
+			case <-locked:
+			case <-time.After(time.Second):
+				t.Error("Expected the lock not to be held during the token request.")
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+		fmt.Fprint(w, `{"access_token":"accesstoken","expires_in":3600}`)
+	}))
+	defer tokenServer.Close()
+
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer apiServer.Close()
+
+	c = New(
+		func(c *Client) { c.Logger = &MockLogger{} },
+		WithOAuth2(OAuth2Config{TokenURL: tokenServer.URL}),
+	)
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r, _ := http.NewRequest(HTTP_GET, apiServer.URL, nil)
+			if _, err := c.Call(r); err != nil {
+				t.Errorf("Received an error but did not expect one: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&tokenRequests); n != 1 {
+		t.Errorf("Expected 1 token request, got %d", n)
+	}
+}
+
+func TestOAuth2TokenIsDroppedOnUnauthorized(t *testing.T) {
+	t.Parallel()
+
+	tokenRequests := 0
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tokenRequests++
+		fmt.Fprintf(w, `{"access_token":"accesstoken%d","expires_in":3600}`, tokenRequests)
+	}))
+	defer tokenServer.Close()
+
+	var authorization string
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		if authorization == "Bearer accesstoken1" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer apiServer.Close()
+
+	c := New(
+		func(c *Client) { c.Logger = &MockLogger{} },
+		WithOAuth2(OAuth2Config{TokenURL: tokenServer.URL}),
+	)
+
+	for i, expected := range []int{http.StatusUnauthorized, http.StatusOK} {
+		r, _ := http.NewRequest(HTTP_GET, apiServer.URL, nil)
+		resp, err := c.Call(r)
+		if err != nil {
+			t.Fatalf("Received an error but did not expect one: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != expected {
+			t.Errorf("Call %d: Expected status %d, got %d", i, expected, resp.StatusCode)
+		}
+	}
+
+	if authorization != "Bearer accesstoken2" || tokenRequests != 2 {
+		t.Errorf("Expected a new token after 401. Got %q after %d token requests", authorization, tokenRequests)
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright 2018 Publit Sweden AB. All rights reserved.
+
+package client
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Rate limit headers read from responses by RateLimiter.Update.
+const (
+	HEADER_RATE_LIMIT_REMAINING = "X-RateLimit-Remaining"
+	HEADER_RATE_LIMIT_RESET     = "X-RateLimit-Reset"
+)
+
+// Values of X-RateLimit-Reset above this are unix timestamps, lower values are seconds until the reset.
+const rateLimitResetEpochThreshold = 1000000000
+
+// RateLimiter limits the rate of requests of the clients using it, see WithRateLimiter.
+// Requests are limited by a token bucket of a fixed rate and burst, if set, and spaced to the remaining requests
+// reported by the X-RateLimit-Remaining and X-RateLimit-Reset response headers, so the limit of the server is spread
+// out instead of hit.
+// A RateLimiter is safe for concurrent use and can be shared by several clients calling the same API. It is also used
+// by the APIClient rate limiting middleware.
+type RateLimiter struct {
+	mu sync.Mutex
+	// rate is the requests per second of the token bucket, or 0 if none.
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	// remaining and reset are the last values reported by the server, known if reset is set.
+	remaining int
+	reset     time.Time
+	// now is time.Now, replaceable in tests.
+	now func() time.Time
+}
+
+// NewRateLimiter creates a RateLimiter allowing perSecond requests per second on average, with bursts of at most burst
+// requests. A burst lower than 1 is set to 1. A perSecond of 0 or lower only limits requests according to the rate
+// limit headers of responses.
+func NewRateLimiter(perSecond float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	if !(perSecond > 0) {
+		perSecond = 0
+	}
+
+	return &RateLimiter{rate: perSecond, burst: float64(burst), tokens: float64(burst), now: time.Now}
+}
+
+// WithRateLimiter sets Client.RateLimiter.
+func WithRateLimiter(l *RateLimiter) func(c *Client) {
+	return func(c *Client) {
+		c.RateLimiter = l
+	}
+}
+
+// Wait blocks until a request is allowed, or until ctx is done in which case the context error is returned.
+// A request not made because ctx is done gives its reservation back, so it does not delay other requests.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	r := l.reserve()
+	l.mu.Unlock()
+
+	if r.delay <= 0 {
+		if err := ctx.Err(); err != nil {
+			l.cancel(r)
+			return err
+		}
+		return nil
+	}
+
+	t := time.NewTimer(r.delay)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		l.cancel(r)
+		return ctx.Err()
+	}
+}
+
+// rateLimitReservation is a request reserved by RateLimiter.reserve.
+type rateLimitReservation struct {
+	// delay is the time to wait for the request.
+	delay time.Duration
+	// token is set if a token of the bucket was taken, and remaining if a request remaining until reset was taken.
+	token     bool
+	remaining bool
+	reset     time.Time
+}
+
+// cancel gives the token and remaining request of the reservation back. The remaining request is only given back if
+// the rate limit headers have not been updated since the reservation.
+func (l *RateLimiter) cancel(r rateLimitReservation) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if r.token {
+		l.tokens++
+	}
+	if r.remaining && l.reset.Equal(r.reset) {
+		l.remaining++
+	}
+}
+
+// reserve reserves the next allowed request. Must be called with mu held.
+func (l *RateLimiter) reserve() rateLimitReservation {
+	r := rateLimitReservation{}
+	now := l.now()
+	at := now
+
+	if l.rate > 0 {
+		if l.last.IsZero() {
+			l.last = now
+		}
+		if now.After(l.last) {
+			l.tokens += now.Sub(l.last).Seconds() * l.rate
+			if l.tokens > l.burst {
+				l.tokens = l.burst
+			}
+			l.last = now
+		}
+
+		// Reserve a token, a negative amount of tokens means the request has to wait for it.
+		l.tokens--
+		r.token = true
+		if l.tokens < 0 {
+			at = now.Add(time.Duration(-l.tokens / l.rate * float64(time.Second)))
+		}
+	}
+
+	if !l.reset.IsZero() && at.Before(l.reset) {
+		if l.remaining <= 0 {
+			at = l.reset
+		} else {
+			// Spread the remaining requests evenly until the reset.
+			spaced := now.Add(l.reset.Sub(now) / time.Duration(l.remaining+1))
+			if spaced.After(at) {
+				at = spaced
+			}
+			l.remaining--
+			r.remaining = true
+			r.reset = l.reset
+		}
+	}
+
+	r.delay = at.Sub(now)
+	return r
+}
+
+// Update reads the rate limit headers of the response. Responses without the headers are ignored.
+func (l *RateLimiter) Update(resp *http.Response) {
+	remaining, err := strconv.Atoi(resp.Header.Get(HEADER_RATE_LIMIT_REMAINING))
+	if err != nil {
+		return
+	}
+
+	reset, err := strconv.ParseInt(resp.Header.Get(HEADER_RATE_LIMIT_RESET), 10, 64)
+	if err != nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.remaining = remaining
+	if reset > rateLimitResetEpochThreshold {
+		l.reset = time.Unix(reset, 0)
+	} else {
+		l.reset = l.now().Add(time.Duration(reset) * time.Second)
+	}
+}
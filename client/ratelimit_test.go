@@ -0,0 +1,138 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func newTestRateLimiter(perSecond float64, now time.Time) *RateLimiter {
+	l := NewRateLimiter(perSecond, 1)
+	l.now = func() time.Time { return now }
+	return l
+}
+
+func rateLimitResponse(remaining int, reset int64) *http.Response {
+	h := http.Header{}
+	h.Set(HEADER_RATE_LIMIT_REMAINING, strconv.Itoa(remaining))
+	h.Set(HEADER_RATE_LIMIT_RESET, strconv.FormatInt(reset, 10))
+	return &http.Response{Header: h}
+}
+
+func TestRateLimiterSpacesRequests(t *testing.T) {
+	t.Parallel()
+	now := time.Now()
+
+	t.Run("Fixed rate", func(t *testing.T) {
+		l := newTestRateLimiter(10, now)
+		for i, expected := range []time.Duration{0, 100 * time.Millisecond, 200 * time.Millisecond} {
+			if d := l.reserve().delay; d != expected {
+				t.Errorf("Request %d: expected wait %v, got %v", i, expected, d)
+			}
+		}
+	})
+
+	t.Run("No remaining requests", func(t *testing.T) {
+		l := newTestRateLimiter(0, now)
+		l.Update(rateLimitResponse(0, 5))
+		if d := l.reserve().delay; d != 5*time.Second {
+			t.Errorf("Expected wait until reset, got %v", d)
+		}
+	})
+
+	t.Run("Remaining requests are spread until reset", func(t *testing.T) {
+		l := newTestRateLimiter(0, now)
+		l.Update(rateLimitResponse(3, now.Add(4*time.Second).Unix()))
+		if d := l.reserve().delay; d <= 0 || d > time.Second {
+			t.Errorf("Expected wait of at most a quarter of the time until reset, got %v", d)
+		}
+		if l.remaining != 2 {
+			t.Errorf("Expected reservation to use a remaining request, got %d remaining", l.remaining)
+		}
+	})
+
+	t.Run("Responses without headers are ignored", func(t *testing.T) {
+		l := newTestRateLimiter(0, now)
+		l.Update(&http.Response{Header: http.Header{}})
+		if d := l.reserve().delay; d != 0 {
+			t.Errorf("Expected no wait, got %v", d)
+		}
+	})
+}
+
+func TestCallWaitsForRateLimiter(t *testing.T) {
+	t.Parallel()
+	c := New(
+		WithRateLimiter(NewRateLimiter(0, 1)),
+		func(c *Client) {
+			c.HTTPClient = DoerFunc(func(r *http.Request) (*http.Response, error) {
+				resp := rateLimitResponse(0, 60)
+				resp.StatusCode = http.StatusOK
+				return resp, nil
+			})
+			c.Logger = &MockLogger{}
+		},
+	)
+
+	r, _ := http.NewRequest(HTTP_GET, "http://someurl.test", nil)
+	if _, err := c.Call(r); err != nil {
+		t.Fatalf("Received an error but did not expect one: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	r, _ = http.NewRequestWithContext(ctx, HTTP_GET, "http://someurl.test", nil)
+
+	if _, err := c.Call(r); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected call to wait for rate limit reset, got %v", err)
+	}
+}
+
+func TestRateLimiterAllowsBursts(t *testing.T) {
+	t.Parallel()
+
+	l := NewRateLimiter(10, 2)
+	now := time.Now()
+	l.now = func() time.Time { return now }
+
+	for i, expected := range []time.Duration{0, 0, 100 * time.Millisecond} {
+		if d := l.reserve().delay; d != expected {
+			t.Errorf("Request %d: expected wait %v, got %v", i, expected, d)
+		}
+	}
+}
+
+func TestRateLimiterRefundsCancelledWaits(t *testing.T) {
+	t.Parallel()
+	now := time.Now()
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	t.Run("Context done before waiting", func(t *testing.T) {
+		l := newTestRateLimiter(10, now)
+		if err := l.Wait(cancelled); !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context error, got %v", err)
+		}
+		if l.tokens != 1 {
+			t.Errorf("Expected token to be refunded. Got %v tokens", l.tokens)
+		}
+	})
+
+	t.Run("Context done while waiting", func(t *testing.T) {
+		l := newTestRateLimiter(0, now)
+		l.Update(rateLimitResponse(3, now.Add(time.Hour).Unix()))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		if err := l.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected context error, got %v", err)
+		}
+		if l.remaining != 3 {
+			t.Errorf("Expected remaining request to be refunded. Got %d remaining", l.remaining)
+		}
+	})
+}
@@ -0,0 +1,149 @@
+// Copyright 2018 Publit Sweden AB. All rights reserved.
+
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Default backoff of retries set by WithRetries without backoff.
+const (
+	DEFAULT_RETRY_INITIAL_BACKOFF = 100 * time.Millisecond
+	DEFAULT_RETRY_MAX_BACKOFF     = 5 * time.Second
+)
+
+// Backoff returns the time to wait before the given attempt, starting at attempt 1.
+type Backoff func(attempt int) time.Duration
+
+// ConstantBackoff waits the same duration before every attempt.
+func ConstantBackoff(d time.Duration) Backoff {
+	return func(attempt int) time.Duration {
+		return d
+	}
+}
+
+// ExponentialBackoff doubles the wait for every attempt, starting at initial and capped at max.
+func ExponentialBackoff(initial, max time.Duration) Backoff {
+	return func(attempt int) time.Duration {
+		d := initial
+		for i := 1; i < attempt && d < max; i++ {
+			d *= 2
+		}
+
+		if d > max {
+			return max
+		}
+		return d
+	}
+}
+
+// RetryPolicy reports whether a request should be retried given the response and error of the last attempt.
+type RetryPolicy func(r *http.Request, resp *http.Response, err error) bool
+
+// WithRetries makes the client retry failed requests up to retries times, waiting according to backoff between attempts.
+// A nil backoff defaults to an ExponentialBackoff from DEFAULT_RETRY_INITIAL_BACKOFF to DEFAULT_RETRY_MAX_BACKOFF.
+func WithRetries(retries int, backoff Backoff) func(c *Client) {
+	return func(c *Client) {
+		if backoff == nil {
+			backoff = ExponentialBackoff(DEFAULT_RETRY_INITIAL_BACKOFF, DEFAULT_RETRY_MAX_BACKOFF)
+		}
+		c.Retries = retries
+		c.RetryBackoff = backoff
+	}
+}
+
+// WithRetryPolicy sets Client.RetryPolicy.
+func WithRetryPolicy(policy RetryPolicy) func(c *Client) {
+	return func(c *Client) {
+		c.RetryPolicy = policy
+	}
+}
+
+// DefaultRetryPolicy retries idempotent requests failing with a connection error or a 5xx response.
+func DefaultRetryPolicy(r *http.Request, resp *http.Response, err error) bool {
+	if !isIdempotent(r.Method) {
+		return false
+	}
+
+	return err != nil || resp == nil || resp.StatusCode >= http.StatusInternalServerError
+}
+
+// isIdempotent reports whether the method is idempotent as defined by RFC 7231.
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// do performs the request with the HTTPClient, retrying it according to the retry settings of the client.
+// Every attempt waits for the RateLimiter of the client, if set.
+// Requests with a body are only retried if the body can be recreated with http.Request.GetBody.
+func (c *Client) do(r *http.Request) (*http.Response, error) {
+	policy := c.RetryPolicy
+	if policy == nil {
+		policy = DefaultRetryPolicy
+	}
+
+	for attempt := 1; ; attempt++ {
+		if c.RateLimiter != nil {
+			if err := c.RateLimiter.Wait(r.Context()); err != nil {
+				return nil, err
+			}
+		}
+
+		resp, err := c.GetHTTPClient().Do(r)
+
+		if c.RateLimiter != nil && resp != nil {
+			c.RateLimiter.Update(resp)
+		}
+
+		if attempt > c.Retries || !policy(r, resp, err) || !canRetryBody(r) {
+			return resp, err
+		}
+
+		c.GetLogger().Debug(fmt.Sprintf("Retrying request [%s %s %s] after attempt %d", r.Method, r.Host, r.URL.Path, attempt))
+
+		if resp != nil && resp.Body != nil {
+			resp.Body.Close()
+		}
+
+		if !c.waitForRetry(r, attempt) {
+			return nil, r.Context().Err()
+		}
+
+		if r.GetBody != nil {
+			body, err := r.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			r.Body = body
+		}
+	}
+}
+
+// canRetryBody reports whether the body of the request can be sent again.
+func canRetryBody(r *http.Request) bool {
+	return r.Body == nil || r.Body == http.NoBody || r.GetBody != nil
+}
+
+// waitForRetry waits according to the backoff of the client before the attempt following the given one.
+// Returns false if the context of the request is done before.
+func (c *Client) waitForRetry(r *http.Request, attempt int) bool {
+	if c.RetryBackoff == nil {
+		return r.Context().Err() == nil
+	}
+
+	t := time.NewTimer(c.RetryBackoff(attempt))
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return true
+	case <-r.Context().Done():
+		return false
+	}
+}
@@ -0,0 +1,155 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// FlakyMockClient fails the first Failures calls, with an error if Err is set and otherwise with a 503 response.
+type FlakyMockClient struct {
+	Failures int
+	Err      error
+	Calls    int
+	Bodies   []string
+}
+
+func (m *FlakyMockClient) Do(r *http.Request) (*http.Response, error) {
+	m.Calls++
+
+	if r.Body != nil {
+		b, _ := ioutil.ReadAll(r.Body)
+		m.Bodies = append(m.Bodies, string(b))
+	}
+
+	if m.Calls <= m.Failures {
+		if m.Err != nil {
+			return nil, m.Err
+		}
+		return &http.Response{Status: "Service Unavailable", StatusCode: http.StatusServiceUnavailable, Body: http.NoBody}, nil
+	}
+
+	return &http.Response{Header: http.Header{}, Status: "ok", StatusCode: http.StatusOK}, nil
+}
+
+func newRetryClient(doer Doer, retries int) *Client {
+	return New(
+		WithRetries(retries, ConstantBackoff(time.Millisecond)),
+		func(c *Client) {
+			c.HTTPClient = doer
+			c.Logger = &MockLogger{}
+		},
+	)
+}
+
+func TestCallRetriesFailedRequests(t *testing.T) {
+	t.Parallel()
+
+	t.Run("5xx responses", func(t *testing.T) {
+		doer := &FlakyMockClient{Failures: 2}
+		r := httptest.NewRequest(HTTP_GET, "http://someurl.test", nil)
+		r.RequestURI = ""
+
+		resp, err := newRetryClient(doer, 2).Call(r)
+		if err != nil || resp.StatusCode != http.StatusOK {
+			t.Errorf("Expected successful response after retries, got %v", err)
+		}
+		if doer.Calls != 3 {
+			t.Errorf("Expected 3 calls, got %d", doer.Calls)
+		}
+	})
+
+	t.Run("Connection errors", func(t *testing.T) {
+		doer := &FlakyMockClient{Failures: 1, Err: errors.New("connection refused")}
+		r := httptest.NewRequest(HTTP_GET, "http://someurl.test", nil)
+		r.RequestURI = ""
+
+		if _, err := newRetryClient(doer, 1).Call(r); err != nil {
+			t.Errorf("Expected successful response after retry, got %v", err)
+		}
+	})
+
+	t.Run("Gives up after retries", func(t *testing.T) {
+		doer := &FlakyMockClient{Failures: 5}
+		r := httptest.NewRequest(HTTP_GET, "http://someurl.test", nil)
+		r.RequestURI = ""
+
+		resp, _ := newRetryClient(doer, 2).Call(r)
+		if resp.StatusCode != http.StatusServiceUnavailable || doer.Calls != 3 {
+			t.Errorf("Expected last failed response after 3 calls, got %d after %d calls", resp.StatusCode, doer.Calls)
+		}
+	})
+
+	t.Run("Resends body", func(t *testing.T) {
+		doer := &FlakyMockClient{Failures: 1}
+		r, _ := http.NewRequest(http.MethodPut, "http://someurl.test", strings.NewReader("somebody"))
+
+		newRetryClient(doer, 1).Call(r)
+		if len(doer.Bodies) != 2 || doer.Bodies[1] != "somebody" {
+			t.Errorf("Expected body to be resent, got %v", doer.Bodies)
+		}
+	})
+}
+
+func TestCallDoesNotRetryByDefault(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		client *Client
+		method string
+	}{
+		"Without retries":        {client: newRetryClient(nil, 0), method: HTTP_GET},
+		"Non idempotent methods": {client: newRetryClient(nil, 2), method: HTTP_POST},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			doer := &FlakyMockClient{Failures: 1}
+			tt.client.HTTPClient = doer
+			r := httptest.NewRequest(tt.method, "http://someurl.test", nil)
+			r.RequestURI = ""
+
+			tt.client.Call(r)
+			if doer.Calls != 1 {
+				t.Errorf("Expected 1 call, got %d", doer.Calls)
+			}
+		})
+	}
+
+	// A custom policy can retry any method.
+	doer := &FlakyMockClient{Failures: 1}
+	c := newRetryClient(doer, 1)
+	WithRetryPolicy(func(r *http.Request, resp *http.Response, err error) bool { return resp.StatusCode >= 500 })(c)
+	r := httptest.NewRequest(HTTP_POST, "http://someurl.test", nil)
+	r.RequestURI = ""
+
+	c.Call(r)
+	if doer.Calls != 2 {
+		t.Errorf("Expected custom retry policy to retry, got %d calls", doer.Calls)
+	}
+}
+
+func TestRetryStopsWhenContextIsDone(t *testing.T) {
+	t.Parallel()
+	doer := &FlakyMockClient{Failures: 5}
+	c := New(
+		WithRetries(5, ConstantBackoff(time.Hour)),
+		func(c *Client) {
+			c.HTTPClient = doer
+			c.Logger = &MockLogger{}
+		},
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	r, _ := http.NewRequestWithContext(ctx, HTTP_GET, "http://someurl.test", nil)
+
+	if _, err := c.Call(r); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context error, got %v", err)
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright 2018 Publit Sweden AB. All rights reserved.
+
+package client
+
+import (
+	"net/http"
+)
+
+// TokenRotation is the policy for adopting tokens received in the token header of responses, see Client.TokenRotation.
+type TokenRotation int
+
+// TokenRotation enum constants.
+const (
+	// TOKEN_ROTATION_WHEN_EMPTY adopts a received token if the client has no token. The default.
+	TOKEN_ROTATION_WHEN_EMPTY TokenRotation = 1 + iota
+	// TOKEN_ROTATION_NEVER never adopts received tokens. Tokens are only set by SetNewAPIToken or explicitly.
+	TOKEN_ROTATION_NEVER
+	// TOKEN_ROTATION_ALWAYS adopts every received token.
+	TOKEN_ROTATION_ALWAYS
+	// TOKEN_ROTATION_WHEN_DIFFERENT adopts a received token if it differs from the token of the client, e.g. when the
+	// server rotates tokens.
+	TOKEN_ROTATION_WHEN_DIFFERENT
+)
+
+// WithTokenRotation sets Client.TokenRotation.
+func WithTokenRotation(rotation TokenRotation) func(c *Client) {
+	return func(c *Client) {
+		c.TokenRotation = rotation
+	}
+}
+
+// adoptToken sets the token from the response according to the TokenRotation of the client.
+func (c *Client) adoptToken(r *http.Request, resp *http.Response) {
+	if c.OAuth2 != nil {
+		return
+	}
+
+	accountID := c.accountID(r)
+
+	c.M.Lock()
+	current := c.token(accountID)
+	c.M.Unlock()
+
+	received := resp.Header.Get("token")
+
+	switch c.TokenRotation {
+	case TOKEN_ROTATION_NEVER:
+		return
+	case TOKEN_ROTATION_ALWAYS:
+		if received == "" {
+			return
+		}
+	case TOKEN_ROTATION_WHEN_DIFFERENT:
+		if received == "" || received == current {
+			return
+		}
+	default:
+		if current != "" {
+			return
+		}
+	}
+
+	// No need to handle token error here since that is not the main objective of the call
+	c.setTokenFromResponse(accountID, resp)
+}
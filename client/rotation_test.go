@@ -0,0 +1,57 @@
+package client
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTokenRotation(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		rotation TokenRotation
+		current  string
+		received string
+		expected string
+	}{
+		"When empty adopts token":                {TOKEN_ROTATION_WHEN_EMPTY, "", "newtoken", "newtoken"},
+		"When empty keeps token":                 {TOKEN_ROTATION_WHEN_EMPTY, "oldtoken", "newtoken", "oldtoken"},
+		"Default keeps token":                    {0, "oldtoken", "newtoken", "oldtoken"},
+		"Never keeps empty token":                {TOKEN_ROTATION_NEVER, "", "newtoken", ""},
+		"Always adopts token":                    {TOKEN_ROTATION_ALWAYS, "oldtoken", "newtoken", "newtoken"},
+		"Always keeps token without new token":   {TOKEN_ROTATION_ALWAYS, "oldtoken", "", "oldtoken"},
+		"When different adopts rotated token":    {TOKEN_ROTATION_WHEN_DIFFERENT, "oldtoken", "newtoken", "newtoken"},
+		"When different keeps token without new": {TOKEN_ROTATION_WHEN_DIFFERENT, "oldtoken", "", "oldtoken"},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			c := New(
+				WithTokenRotation(tt.rotation),
+				func(c *Client) {
+					c.User = "someuser"
+					c.Token = tt.current
+					c.HTTPClient = DoerFunc(func(r *http.Request) (*http.Response, error) {
+						h := http.Header{}
+						if tt.received != "" {
+							h.Set("token", tt.received)
+						}
+						return &http.Response{Header: h, StatusCode: http.StatusOK}, nil
+					})
+					c.Logger = &MockLogger{}
+				},
+			)
+
+			r := httptest.NewRequest(HTTP_GET, "http://someurl.test", nil)
+			r.RequestURI = ""
+			c.Call(r)
+
+			if token := c.GetAuthToken(); token != tt.expected {
+				t.Errorf("Expected token %q, got %q", tt.expected, token)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright 2018 Publit Sweden AB. All rights reserved.
+
+package client
+
+import (
+	"net/http"
+)
+
+// RoundTripper is an http.RoundTripper authenticating requests with a Client, so Publit authentication can be used by
+// any http.Client, eg. one of a third-party SDK or a generated OpenAPI client. Create it with Client.RoundTripper.
+type RoundTripper struct {
+	// Client authenticating the requests.
+	Client *Client
+	// Base performs the authenticated requests. Defaults to http.DefaultTransport.
+	Base http.RoundTripper
+}
+
+// RoundTripper returns an http.RoundTripper setting the auth and default headers of the client on requests before
+// performing them with base, and setting the token of the client from responses like Call. A nil base defaults to
+// http.DefaultTransport. Other settings of the client, like retries and HTTPClient, are not used.
+//
+//	hc := &http.Client{Transport: c.RoundTripper(nil)}
+func (c *Client) RoundTripper(base http.RoundTripper) *RoundTripper {
+	return &RoundTripper{Client: c, Base: base}
+}
+
+// RoundTrip authenticates a copy of the request and performs it, see http.RoundTripper.
+func (t *RoundTripper) RoundTrip(r *http.Request) (*http.Response, error) {
+	// A RoundTripper must not modify the request.
+	r = r.Clone(r.Context())
+
+	if err := t.Client.setAuth(r); err != nil {
+		closeRequestBody(r)
+		return nil, err
+	}
+	t.Client.setDefaultHeaders(r)
+
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	resp, err := base.RoundTrip(r)
+	if err != nil {
+		return nil, err
+	}
+
+	t.Client.adoptToken(r, resp)
+
+	return resp, nil
+}
+
+// closeRequestBody closes the body of a request that is not sent, as required of a http.RoundTripper.
+func closeRequestBody(r *http.Request) {
+	if r.Body != nil {
+		r.Body.Close()
+	}
+}
@@ -0,0 +1,51 @@
+package client
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRoundTripperAuthenticatesRequests(t *testing.T) {
+	t.Parallel()
+
+	auths := []string{}
+	tokens := []string{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auths = append(auths, r.Header.Get("Authorization"))
+		tokens = append(tokens, r.Header.Get("token"))
+		w.Header().Set("token", "sometoken")
+	}))
+	defer ts.Close()
+
+	c := New(
+		WithDefaultHeader("X-Tenant", "sometenant"),
+		func(c *Client) {
+			c.User = "someuser"
+			c.Password = "somepassword"
+			c.Logger = &MockLogger{}
+		},
+	)
+	hc := &http.Client{Transport: c.RoundTripper(nil)}
+
+	for i := 0; i < 2; i++ {
+		r, _ := http.NewRequest(HTTP_GET, ts.URL, nil)
+		resp, err := hc.Do(r)
+		if err != nil {
+			t.Fatalf("Received an error but did not expect one: %v", err)
+		}
+		resp.Body.Close()
+
+		if r.Header.Get("Authorization") != "" {
+			t.Error("Did not expect the request of the caller to be modified.")
+		}
+	}
+
+	if auths[0] != "Basic "+b64enc("someuser;:somepassword") || tokens[0] != "" {
+		t.Errorf("Expected first request to authenticate with password, got %q", auths[0])
+	}
+
+	if auths[1] != "Basic "+b64enc("someuser;:") || tokens[1] != "sometoken" {
+		t.Errorf("Expected second request to authenticate with token from first response, got %q, %q", auths[1], tokens[1])
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright 2018 Publit Sweden AB. All rights reserved.
+
+package client
+
+import (
+	"net"
+	"net/http"
+	"time"
+)
+
+// DEFAULT_DIAL_TIMEOUT is the dial timeout used with PinnedAddresses if DialTimeout is not set, same as http.DefaultTransport.
+const DEFAULT_DIAL_TIMEOUT = 30 * time.Second
+
+// WithTimeout sets Client.Timeout.
+func WithTimeout(timeout time.Duration) func(c *Client) {
+	return func(c *Client) {
+		c.Timeout = timeout
+	}
+}
+
+// WithDialTimeout sets Client.DialTimeout.
+func WithDialTimeout(timeout time.Duration) func(c *Client) {
+	return func(c *Client) {
+		c.DialTimeout = timeout
+	}
+}
+
+// WithTLSHandshakeTimeout sets Client.TLSHandshakeTimeout.
+func WithTLSHandshakeTimeout(timeout time.Duration) func(c *Client) {
+	return func(c *Client) {
+		c.TLSHandshakeTimeout = timeout
+	}
+}
+
+// hasTransportSettings reports whether any of the timeout or dial settings of the client is set.
+func (c *Client) hasTransportSettings() bool {
+	return c.Timeout > 0 || c.DialTimeout > 0 || c.TLSHandshakeTimeout > 0 || c.DialContext != nil || len(c.PinnedAddresses) > 0
+}
+
+// newHTTPClient creates a http.Client with the timeout and dial settings of the client.
+// The transport is a clone of http.DefaultTransport, so other settings like proxies from the environment are kept.
+func (c *Client) newHTTPClient() *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	dial := c.DialContext
+	if dial == nil && (c.DialTimeout > 0 || len(c.PinnedAddresses) > 0) {
+		timeout := c.DialTimeout
+		if timeout <= 0 {
+			timeout = DEFAULT_DIAL_TIMEOUT
+		}
+		dialer := &net.Dialer{Timeout: timeout, KeepAlive: 30 * time.Second}
+		dial = dialer.DialContext
+	}
+
+	if len(c.PinnedAddresses) > 0 {
+		dial = pinnedDial(c.PinnedAddresses, dial)
+	}
+
+	if dial != nil {
+		transport.DialContext = dial
+	}
+
+	if c.TLSHandshakeTimeout > 0 {
+		transport.TLSHandshakeTimeout = c.TLSHandshakeTimeout
+	}
+
+	return &http.Client{Transport: transport, Timeout: c.Timeout}
+}
@@ -0,0 +1,58 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNewAppliesTimeouts(t *testing.T) {
+	t.Parallel()
+	c := New(
+		WithTimeout(10*time.Second),
+		WithDialTimeout(2*time.Second),
+		WithTLSHandshakeTimeout(3*time.Second),
+	)
+
+	hc, ok := c.HTTPClient.(*http.Client)
+	if !ok || hc == http.DefaultClient {
+		t.Fatalf("Expected a new http client, got %v", c.HTTPClient)
+	}
+
+	if hc.Timeout != 10*time.Second {
+		t.Errorf("Expected timeout 10s, got %v", hc.Timeout)
+	}
+
+	transport := hc.Transport.(*http.Transport)
+	if transport.TLSHandshakeTimeout != 3*time.Second || transport.DialContext == nil {
+		t.Error("Expected transport to use dial and TLS handshake timeouts.")
+	}
+
+	if New().HTTPClient != http.DefaultClient {
+		t.Error("Expected default http client without timeouts.")
+	}
+
+	if _, ok := New(WithTimeout(time.Second), func(c *Client) { c.HTTPClient = MockClient{} }).HTTPClient.(MockClient); !ok {
+		t.Error("Expected explicitly set http client to be kept.")
+	}
+}
+
+func TestCallTimesOut(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+	}))
+	defer ts.Close()
+
+	c := New(WithTimeout(10*time.Millisecond), func(c *Client) { c.Logger = &MockLogger{} })
+
+	r, _ := http.NewRequest(HTTP_GET, ts.URL, nil)
+	_, err := c.CallRaw(r)
+
+	var te *TransportError
+	if !errors.As(err, &te) {
+		t.Errorf("Expected a transport error on timeout, got %v", err)
+	}
+}
@@ -0,0 +1,134 @@
+// Copyright 2018 Publit Sweden AB. All rights reserved.
+
+package client
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DEFAULT_REFRESH_SKEW is how long before the expiry of the token a new token is requested, unless Client.RefreshSkew is set.
+const DEFAULT_REFRESH_SKEW = 30 * time.Second
+
+// WithRefreshSkew sets Client.RefreshSkew.
+func WithRefreshSkew(skew time.Duration) func(c *Client) {
+	return func(c *Client) {
+		c.RefreshSkew = skew
+	}
+}
+
+// tokenExpiry parses the exp claim of a JWT token. The signature is not verified.
+// Reports false if the token is not a JWT or has no exp claim.
+func tokenExpiry(token string) (time.Time, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, false
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	claims := struct {
+		Exp *float64 `json:"exp"`
+	}{}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp == nil {
+		return time.Time{}, false
+	}
+
+	sec := int64(*claims.Exp)
+	return time.Unix(sec, int64((*claims.Exp-float64(sec))*1e9)), true
+}
+
+// HEADER_TOKEN_EXPIRES is the response header giving the expiry of the token received with the response, either as
+// a unix timestamp or an HTTP date. Used for tokens that are not JWTs.
+const HEADER_TOKEN_EXPIRES = "Token-Expires"
+
+// GetTokenExpiry returns when the token of the client expires, as given by the Token-Expires header of the response
+// the token was received with, or else by the exp claim of a JWT token. Reports false if the expiry is unknown.
+func (c *Client) GetTokenExpiry() (time.Time, bool) {
+	accountID := c.defaultAccountID()
+
+	c.M.Lock()
+	defer c.M.Unlock()
+
+	return c.tokenExpiresAt(accountID)
+}
+
+// TokenValid reports whether the client has a token that has not expired. Tokens with unknown expiry are valid.
+func (c *Client) TokenValid() bool {
+	accountID := c.defaultAccountID()
+
+	c.M.Lock()
+	defer c.M.Unlock()
+
+	if c.token(accountID) == "" {
+		return false
+	}
+
+	expiry, ok := c.tokenExpiresAt(accountID)
+	return !ok || time.Now().Before(expiry)
+}
+
+// tokenExpiresAt returns the expiry of the token of the account. Must be called with M held.
+func (c *Client) tokenExpiresAt(accountID int) (time.Time, bool) {
+	if expiry, ok := c.tokenExpiries[accountID]; ok {
+		return expiry, true
+	}
+	return tokenExpiry(c.token(accountID))
+}
+
+// setTokenExpiry sets the expiry of the token of the account from the Token-Expires header of the response.
+// Must be called with M held.
+func (c *Client) setTokenExpiry(accountID int, resp *http.Response) {
+	v := resp.Header.Get(HEADER_TOKEN_EXPIRES)
+	if v == "" {
+		return
+	}
+
+	var expiry time.Time
+	if sec, err := strconv.ParseInt(v, 10, 64); err == nil {
+		expiry = time.Unix(sec, 0)
+	} else if t, err := http.ParseTime(v); err == nil {
+		expiry = t
+	} else {
+		c.GetLogger().Debug(fmt.Sprintf("Invalid %s header %q", HEADER_TOKEN_EXPIRES, v))
+		return
+	}
+
+	if c.tokenExpiries == nil {
+		c.tokenExpiries = map[int]time.Time{}
+	}
+	c.tokenExpiries[accountID] = expiry
+}
+
+// refreshExpiringToken unsets the token if it expires within the refresh skew, so the next call authenticates with the
+// credentials and a new token is set from its response. Tokens are only refreshed if a password is set.
+func (c *Client) refreshExpiringToken(accountID int) {
+	if c.GetCredentials().Password == "" {
+		return
+	}
+
+	skew := c.RefreshSkew
+	if skew <= 0 {
+		skew = DEFAULT_REFRESH_SKEW
+	}
+
+	c.M.Lock()
+	expiry, ok := c.tokenExpiresAt(accountID)
+	if !ok || time.Now().Add(skew).Before(expiry) {
+		c.M.Unlock()
+		return
+	}
+	c.setToken(accountID, "")
+	c.M.Unlock()
+
+	c.GetLogger().Debug(fmt.Sprintf("Token expires at %s. Requesting new token.", expiry.Format(time.RFC3339)))
+	c.storeToken(accountID, "")
+}
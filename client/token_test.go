@@ -0,0 +1,166 @@
+package client
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// makeJWT creates an unsigned JWT with the exp claim.
+func makeJWT(exp time.Time) string {
+	enc := base64.RawURLEncoding
+	header := enc.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+	payload := enc.EncodeToString([]byte(fmt.Sprintf(`{"sub":"someuser","exp":%d}`, exp.Unix())))
+	return header + "." + payload + ".signature"
+}
+
+func TestCanParseTokenExpiry(t *testing.T) {
+	t.Parallel()
+
+	exp := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	if parsed, ok := tokenExpiry(makeJWT(exp)); !ok || !parsed.Equal(exp) {
+		t.Errorf("Unexpected expiry. Expected %v, got %v", exp, parsed)
+	}
+
+	for _, v := range []string{"", "sometokenhash", "a.b.c", "a." + base64.RawURLEncoding.EncodeToString([]byte(`{}`)) + ".c"} {
+		if _, ok := tokenExpiry(v); ok {
+			t.Errorf("Did not expect expiry to be parsed from %q", v)
+		}
+	}
+}
+
+func TestCallRefreshesExpiringToken(t *testing.T) {
+	t.Parallel()
+
+	table := map[string]struct {
+		Expiry    time.Time
+		Refreshed bool
+	}{
+		"Valid token":    {time.Now().Add(time.Hour), false},
+		"Expiring token": {time.Now().Add(10 * time.Second), true},
+		"Expired token":  {time.Now().Add(-time.Hour), true},
+	}
+
+	for name, v := range table {
+		token := makeJWT(v.Expiry)
+		c := New(
+			func(c *Client) {
+				c.User = "someuser"
+				c.Password = "somepassword"
+				c.Token = token
+				c.HTTPClient = MockClient{SetTokenHeader: true}
+				c.Logger = &MockLogger{}
+			},
+			WithRefreshSkew(time.Minute),
+		)
+
+		r := httptest.NewRequest(HTTP_GET, "http://someurl.test", nil)
+		r.RequestURI = ""
+
+		if _, err := c.Call(r); err != nil {
+			t.Errorf("%s: Received an error but did not expect one: %v", name, err)
+		}
+
+		refreshed := r.Header.Get("token") == ""
+		if refreshed != v.Refreshed {
+			t.Errorf("%s: Unexpected refresh. Expected %v, got %v", name, v.Refreshed, refreshed)
+		}
+
+		if v.Refreshed && c.GetAuthToken() != "sometoken" {
+			t.Errorf("%s: Expected new token to be set from response. Got %q", name, c.GetAuthToken())
+		}
+	}
+}
+
+func TestTokenExpiryAccessors(t *testing.T) {
+	t.Parallel()
+
+	exp := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	t.Run("From JWT", func(t *testing.T) {
+		c := New(func(c *Client) { c.Token = makeJWT(exp) })
+
+		if expiry, ok := c.GetTokenExpiry(); !ok || !expiry.Equal(exp) {
+			t.Errorf("Expected expiry %v, got %v", exp, expiry)
+		}
+		if !c.TokenValid() {
+			t.Error("Expected token to be valid.")
+		}
+
+		c.Token = makeJWT(time.Now().Add(-time.Minute))
+		if c.TokenValid() {
+			t.Error("Did not expect expired token to be valid.")
+		}
+	})
+
+	t.Run("From response header", func(t *testing.T) {
+		c := New(func(c *Client) {
+			c.User = "someuser"
+			c.HTTPClient = DoerFunc(func(r *http.Request) (*http.Response, error) {
+				h := http.Header{}
+				h.Set("token", "sometoken")
+				h.Set(HEADER_TOKEN_EXPIRES, exp.UTC().Format(http.TimeFormat))
+				return &http.Response{Header: h, StatusCode: http.StatusOK}, nil
+			})
+			c.Logger = &MockLogger{}
+		})
+
+		r := httptest.NewRequest(HTTP_GET, "http://someurl.test", nil)
+		r.RequestURI = ""
+		c.Call(r)
+
+		if expiry, ok := c.GetTokenExpiry(); !ok || !expiry.Equal(exp) {
+			t.Errorf("Expected expiry %v, got %v", exp, expiry)
+		}
+
+		c.UnsetAuthToken()
+		if _, ok := c.GetTokenExpiry(); ok || c.TokenValid() {
+			t.Error("Expected expiry to be unknown without token.")
+		}
+	})
+
+	t.Run("Unknown expiry", func(t *testing.T) {
+		c := New(func(c *Client) { c.Token = "sometoken" })
+
+		if _, ok := c.GetTokenExpiry(); ok {
+			t.Error("Did not expect expiry of opaque token to be known.")
+		}
+		if !c.TokenValid() {
+			t.Error("Expected token with unknown expiry to be valid.")
+		}
+		if New().TokenValid() {
+			t.Error("Did not expect client without token to have a valid token.")
+		}
+	})
+}
+
+func TestRefreshDeletesStoredTokenWithoutHoldingLock(t *testing.T) {
+	t.Parallel()
+
+	store := &lockCheckingTokenStore{t: t}
+	c := New(
+		func(c *Client) {
+			c.User = "someuser"
+			c.Password = "somepassword"
+			c.Token = makeJWT(time.Now().Add(-time.Hour))
+			c.HTTPClient = MockClient{}
+			c.Logger = &MockLogger{}
+		},
+		WithTokenStore(store),
+	)
+	store.c = c
+
+	r := httptest.NewRequest(HTTP_GET, "http://someurl.test", nil)
+	r.RequestURI = ""
+	if _, err := c.Call(r); err != nil {
+		t.Errorf("Received an error but did not expect one: %v", err)
+	}
+
+	if r.Header.Get("token") != "" {
+		t.Error("Expected expired token to be refreshed.")
+	}
+}
@@ -0,0 +1,209 @@
+// Copyright 2018 Publit Sweden AB. All rights reserved.
+
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+)
+
+// ErrTokenNotFound is returned by a TokenStore when it holds no token for the key.
+var ErrTokenNotFound = errors.New("Token not found")
+
+// TokenStore persists tokens so they can be reused across process restarts, e.g. on disk, in a database or a secrets manager.
+// Tokens are indexed by a key identifying the user and account, see Client.TokenKey.
+type TokenStore interface {
+	// Get returns the stored token, or ErrTokenNotFound if there is none.
+	Get(key string) (string, error)
+	Set(key, token string) error
+	Delete(key string) error
+}
+
+// WithTokenStore sets Client.TokenStore.
+func WithTokenStore(store TokenStore) func(c *Client) {
+	return func(c *Client) {
+		c.TokenStore = store
+	}
+}
+
+// TokenKey returns the key of the token of the client in the TokenStore, "user;account".
+func (c *Client) TokenKey() string {
+	return c.tokenKey(c.defaultAccountID())
+}
+
+// tokenKey returns the key of the token of the account in the TokenStore.
+func (c *Client) tokenKey(accountID int) string {
+	return fmt.Sprintf("%v;%v", c.GetCredentials().User, accountID)
+}
+
+// loadToken sets the token of the account from the TokenStore if the client has no token for it.
+// The TokenStore is read without holding M, and the token is only set if no other call has set one meanwhile.
+func (c *Client) loadToken(accountID int) {
+	if c.TokenStore == nil {
+		return
+	}
+
+	c.M.Lock()
+	hasToken := c.token(accountID) != ""
+	c.M.Unlock()
+	if hasToken {
+		return
+	}
+
+	token, err := c.TokenStore.Get(c.tokenKey(accountID))
+	if err != nil {
+		if !errors.Is(err, ErrTokenNotFound) {
+			c.GetLogger().Debug(err)
+		}
+		return
+	}
+
+	c.M.Lock()
+	defer c.M.Unlock()
+	if c.token(accountID) == "" {
+		c.setToken(accountID, token)
+	}
+}
+
+// storeToken saves the token of the account in the TokenStore, or deletes the stored token if it is empty.
+// Failures are logged, since the token is still usable by the client.
+func (c *Client) storeToken(accountID int, token string) {
+	if c.TokenStore == nil {
+		return
+	}
+
+	var err error
+	if token == "" {
+		err = c.TokenStore.Delete(c.tokenKey(accountID))
+	} else {
+		err = c.TokenStore.Set(c.tokenKey(accountID), token)
+	}
+
+	if err != nil {
+		c.GetLogger().Debug(err)
+	}
+}
+
+// MemoryTokenStore is a TokenStore keeping tokens in memory, shared by the clients using it.
+type MemoryTokenStore struct {
+	mu     sync.Mutex
+	tokens map[string]string
+}
+
+// NewMemoryTokenStore creates an empty MemoryTokenStore.
+func NewMemoryTokenStore() *MemoryTokenStore {
+	return &MemoryTokenStore{tokens: map[string]string{}}
+}
+
+func (s *MemoryTokenStore) Get(key string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, ok := s.tokens[key]
+	if !ok {
+		return "", ErrTokenNotFound
+	}
+	return token, nil
+}
+
+func (s *MemoryTokenStore) Set(key, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.tokens == nil {
+		s.tokens = map[string]string{}
+	}
+	s.tokens[key] = token
+	return nil
+}
+
+func (s *MemoryTokenStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.tokens, key)
+	return nil
+}
+
+// FileTokenStore is a TokenStore keeping tokens in a json file, readable by the owner only.
+type FileTokenStore struct {
+	Path string
+
+	mu sync.Mutex
+}
+
+// NewFileTokenStore creates a FileTokenStore using the file at path. The file is created when the first token is set.
+func NewFileTokenStore(path string) *FileTokenStore {
+	return &FileTokenStore{Path: path}
+}
+
+func (s *FileTokenStore) Get(key string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tokens, err := s.read()
+	if err != nil {
+		return "", err
+	}
+
+	token, ok := tokens[key]
+	if !ok {
+		return "", ErrTokenNotFound
+	}
+	return token, nil
+}
+
+func (s *FileTokenStore) Set(key, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tokens, err := s.read()
+	if err != nil {
+		return err
+	}
+	tokens[key] = token
+	return s.write(tokens)
+}
+
+func (s *FileTokenStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tokens, err := s.read()
+	if err != nil {
+		return err
+	}
+	if _, ok := tokens[key]; !ok {
+		return nil
+	}
+	delete(tokens, key)
+	return s.write(tokens)
+}
+
+func (s *FileTokenStore) read() (map[string]string, error) {
+	tokens := map[string]string{}
+
+	b, err := ioutil.ReadFile(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return tokens, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(b, &tokens); err != nil {
+		return nil, fmt.Errorf("Could not read token file %s. %v", s.Path, err)
+	}
+	return tokens, nil
+}
+
+func (s *FileTokenStore) write(tokens map[string]string) error {
+	b, err := json.Marshal(tokens)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(s.Path, b, 0600)
+}
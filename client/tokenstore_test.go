@@ -0,0 +1,115 @@
+package client
+
+import (
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func TestTokensArePersistedInTokenStore(t *testing.T) {
+	t.Parallel()
+
+	stores := map[string]TokenStore{
+		"Memory": NewMemoryTokenStore(),
+		"File":   NewFileTokenStore(filepath.Join(t.TempDir(), "tokens.json")),
+	}
+
+	for name, store := range stores {
+		newClient := func() *Client {
+			return New(
+				func(c *Client) {
+					c.User = "someuser"
+					c.Password = "somepassword"
+					c.AccountID = 1
+					c.HTTPClient = MockClient{SetTokenHeader: true}
+					c.Logger = &MockLogger{}
+				},
+				WithTokenStore(store),
+			)
+		}
+
+		r := httptest.NewRequest(HTTP_GET, "http://someurl.test", nil)
+		r.RequestURI = ""
+		if _, err := newClient().Call(r); err != nil {
+			t.Errorf("%s: Received an error but did not expect one: %v", name, err)
+		}
+
+		if token, err := store.Get("someuser;1"); err != nil || token != "sometoken" {
+			t.Errorf("%s: Expected token to be stored. Got %q, %v", name, token, err)
+		}
+
+		// A new client reuses the stored token.
+		c := newClient()
+		r = httptest.NewRequest(HTTP_GET, "http://someurl.test", nil)
+		r.RequestURI = ""
+		c.Call(r)
+
+		if r.Header.Get("token") != "sometoken" {
+			t.Errorf("%s: Expected stored token to be used.", name)
+		}
+
+		c.UnsetAuthToken()
+		if _, err := store.Get("someuser;1"); err != ErrTokenNotFound {
+			t.Errorf("%s: Expected token to be deleted. Got %v", name, err)
+		}
+	}
+}
+
+// lockCheckingTokenStore is a MemoryTokenStore failing the test if it is used while the client holds M.
+type lockCheckingTokenStore struct {
+	MemoryTokenStore
+	c *Client
+	t *testing.T
+}
+
+func (s *lockCheckingTokenStore) checkUnlocked() {
+	if !s.c.M.TryLock() {
+		s.t.Error("Expected the token store not to be used while holding the client lock.")
+		return
+	}
+	s.c.M.Unlock()
+}
+
+func (s *lockCheckingTokenStore) Get(key string) (string, error) {
+	s.checkUnlocked()
+	return s.MemoryTokenStore.Get(key)
+}
+
+func (s *lockCheckingTokenStore) Set(key, token string) error {
+	s.checkUnlocked()
+	return s.MemoryTokenStore.Set(key, token)
+}
+
+func (s *lockCheckingTokenStore) Delete(key string) error {
+	s.checkUnlocked()
+	return s.MemoryTokenStore.Delete(key)
+}
+
+func TestTokenStoreIsReadWithoutHoldingLock(t *testing.T) {
+	t.Parallel()
+
+	store := &lockCheckingTokenStore{t: t}
+	store.MemoryTokenStore.Set("someuser;1", "storedtoken")
+
+	c := New(
+		func(c *Client) {
+			c.User = "someuser"
+			c.Password = "somepassword"
+			c.AccountID = 1
+			c.HTTPClient = MockClient{}
+			c.Logger = &MockLogger{}
+		},
+		WithTokenStore(store),
+	)
+	store.c = c
+
+	r := httptest.NewRequest(HTTP_GET, "http://someurl.test", nil)
+	r.RequestURI = ""
+	if _, err := c.Call(r); err != nil {
+		t.Errorf("Received an error but did not expect one: %v", err)
+	}
+
+	if r.Header.Get("token") != "storedtoken" {
+		t.Errorf("Expected stored token to be used. Got %q", r.Header.Get("token"))
+	}
+}
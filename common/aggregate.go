@@ -0,0 +1,110 @@
+// Copyright 2018 Publit Sweden AB. All rights reserved.
+
+package common
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Query string key of aggregates, see QueryAggregate.
+const QUERY_KEY_AGGREGATE = "aggregate"
+
+// AggregateFunc describes the aggregate functions implemented by the reporting endpoints of the Publit APIs.
+type AggregateFunc int
+
+// AggregateFunc enum constants.
+const (
+	AGGREGATE_SUM AggregateFunc = 1 + iota
+	AGGREGATE_COUNT
+	AGGREGATE_MIN
+	AGGREGATE_MAX
+	AGGREGATE_AVG
+)
+
+// AggregateFunc strings.
+var aggregateFuncs = []string{
+	"SUM",
+	"COUNT",
+	"MIN",
+	"MAX",
+	"AVG",
+}
+
+// Returns AggregateFunc "enum" as string.
+// This string is used for assembling query string parameters to the Publit APIs.
+func (f AggregateFunc) AsString() string {
+	return aggregateFuncs[f-1]
+}
+
+// TryAsString returns the AggregateFunc as string like AsString, but returns ErrInvalidEnum instead of panicking if out
+// of range.
+func (f AggregateFunc) TryAsString() (string, error) {
+	return enumString(aggregateFuncs, "AggregateFunc", int(f))
+}
+
+// String returns the AggregateFunc as string, or "AggregateFunc(n)" if out of range.
+func (f AggregateFunc) String() string {
+	return enumDisplay(aggregateFuncs, "AggregateFunc", int(f))
+}
+
+// ParseAggregateFunc returns the AggregateFunc of a string like "SUM", in any case.
+func ParseAggregateFunc(s string) (AggregateFunc, error) {
+	v, err := parseEnum(aggregateFuncs, "AggregateFunc", s)
+	return AggregateFunc(v), err
+}
+
+// Aggregate is an aggregate function of a column.
+type Aggregate struct {
+	Func   AggregateFunc
+	Column string
+}
+
+// AggregateSum returns the sum of the column.
+func AggregateSum(column string) Aggregate {
+	return Aggregate{Func: AGGREGATE_SUM, Column: column}
+}
+
+// AggregateCount returns the count of the column.
+func AggregateCount(column string) Aggregate {
+	return Aggregate{Func: AGGREGATE_COUNT, Column: column}
+}
+
+// AggregateMin returns the min of the column.
+func AggregateMin(column string) Aggregate {
+	return Aggregate{Func: AGGREGATE_MIN, Column: column}
+}
+
+// AggregateMax returns the max of the column.
+func AggregateMax(column string) Aggregate {
+	return Aggregate{Func: AGGREGATE_MAX, Column: column}
+}
+
+// AggregateAvg returns the average of the column.
+func AggregateAvg(column string) Aggregate {
+	return Aggregate{Func: AGGREGATE_AVG, Column: column}
+}
+
+// Helper to set aggregates to API query, rendered as "FUNC;column" separated by commas, eg. "SUM;price,COUNT;id".
+// The aggregates are computed per group when combined with QueryGroupBy. Aggregates with an invalid AggregateFunc are
+// left out.
+// Functions with signature func(q url.Values) are implemented in the more specific SDKs of the PublitGoSDK packages.
+func QueryAggregate(aggregates ...Aggregate) func(q url.Values) {
+	aggregateStrings := []string{}
+	for _, v := range aggregates {
+		f, err := v.Func.TryAsString()
+		if err != nil {
+			continue
+		}
+		aggregateStrings = append(aggregateStrings, fmt.Sprintf("%v;%v", f, v.Column))
+	}
+
+	aggregateString := strings.Join(aggregateStrings, ",")
+
+	return func(q url.Values) {
+		if aggregateString != "" {
+			q.Add(QUERY_KEY_AGGREGATE, aggregateString)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright 2018 Publit Sweden AB. All rights reserved.
+
+package common
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PUBLIT_TIME_FORMAT is the layout of times in the Publit APIs, in UTC.
+const PUBLIT_TIME_FORMAT = "2006-01-02 15:04:05"
+
+// NewPublitTime returns the PublitTime of t.
+func NewPublitTime(t time.Time) PublitTime {
+	return PublitTime(t.UTC().Format(PUBLIT_TIME_FORMAT))
+}
+
+// FormatAttrValue formats a value of an attribute filter in the representation of the Publit APIs.
+// Integers and floats are formatted in decimal without exponent, bools as "true" or "false", and times as PublitTime.
+// Other values are formatted with fmt.
+func FormatAttrValue(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case int:
+		return strconv.FormatInt(int64(v), 10)
+	case int8:
+		return strconv.FormatInt(int64(v), 10)
+	case int16:
+		return strconv.FormatInt(int64(v), 10)
+	case int32:
+		return strconv.FormatInt(int64(v), 10)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case uint:
+		return strconv.FormatUint(uint64(v), 10)
+	case uint8:
+		return strconv.FormatUint(uint64(v), 10)
+	case uint16:
+		return strconv.FormatUint(uint64(v), 10)
+	case uint32:
+		return strconv.FormatUint(uint64(v), 10)
+	case uint64:
+		return strconv.FormatUint(v, 10)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return string(NewPublitBool(v))
+	case time.Time:
+		return string(NewPublitTime(v))
+	}
+	return fmt.Sprint(v)
+}
+
+// Attr returns the filter of the attribute by the operator and value, formatted with FormatAttrValue and escaped with
+// EscapeAttrValue.
+func Attr(name string, op Operator, v interface{}) AttrQuery {
+	return AttrQuery{
+		Name:  name,
+		Value: EscapeAttrValue(FormatAttrValue(v)),
+		Args: AttrArgs{
+			Operator:   []Operator{op},
+			Combinator: []Combinator{COMBINATOR_AND},
+		},
+	}
+}
+
+// AttrEq filters the attribute by equality to the value, eg. AttrEq("id", 5).
+func AttrEq(name string, v interface{}) AttrQuery {
+	return Attr(name, OPERATOR_EQUAL, v)
+}
+
+// AttrNotEq filters the attribute by inequality to the value.
+func AttrNotEq(name string, v interface{}) AttrQuery {
+	return Attr(name, OPERATOR_NOT_EQUAL, v)
+}
+
+// AttrGreater filters the attribute by being greater than the value.
+func AttrGreater(name string, v interface{}) AttrQuery {
+	return Attr(name, OPERATOR_GREATER, v)
+}
+
+// AttrGreaterEq filters the attribute by being greater than or equal to the value.
+func AttrGreaterEq(name string, v interface{}) AttrQuery {
+	return Attr(name, OPERATOR_GREATER_EQUAL, v)
+}
+
+// AttrLess filters the attribute by being less than the value.
+func AttrLess(name string, v interface{}) AttrQuery {
+	return Attr(name, OPERATOR_LESS, v)
+}
+
+// AttrLessEq filters the attribute by being less than or equal to the value.
+func AttrLessEq(name string, v interface{}) AttrQuery {
+	return Attr(name, OPERATOR_LESS_EQUAL, v)
+}
+
+// AttrBefore filters the time attribute by being before t, eg. AttrBefore("created_at", time.Now()).
+func AttrBefore(name string, t time.Time) AttrQuery {
+	return Attr(name, OPERATOR_LESS, t)
+}
+
+// AttrAfter filters the time attribute by being after t.
+func AttrAfter(name string, t time.Time) AttrQuery {
+	return Attr(name, OPERATOR_GREATER, t)
+}
+
+// AttrIs filters the bool attribute by the value, eg. AttrIs("live", true).
+func AttrIs(name string, b bool) AttrQuery {
+	return Attr(name, OPERATOR_EQUAL, b)
+}
+
+// AttrIn filters the attribute by equality to any of the values, eg. AttrIn("state", "draft", "review") for
+// "state = draft OR state = review". The values are combined with OR, the first value with AND.
+// The OR-list should be the only filter of the attribute, as the precedence of mixed combinators is not defined.
+func AttrIn(name string, values ...interface{}) AttrQuery {
+	return attrList(name, OPERATOR_EQUAL, COMBINATOR_OR, values)
+}
+
+// AttrNotIn filters the attribute by inequality to all of the values. The values are combined with AND.
+func AttrNotIn(name string, values ...interface{}) AttrQuery {
+	return attrList(name, OPERATOR_NOT_EQUAL, COMBINATOR_AND, values)
+}
+
+// attrList returns the filter of the attribute by the operator on each value, the values combined by the combinator.
+func attrList(name string, op Operator, combinator Combinator, values []interface{}) AttrQuery {
+	attr := AttrQuery{Name: name}
+	strs := make([]string, len(values))
+
+	for i, v := range values {
+		strs[i] = EscapeAttrValue(FormatAttrValue(v))
+
+		comb := combinator
+		if i == 0 {
+			comb = COMBINATOR_AND
+		}
+		attr.Args.Operator = append(attr.Args.Operator, op)
+		attr.Args.Combinator = append(attr.Args.Combinator, comb)
+	}
+
+	attr.Value = strings.Join(strs, ",")
+	return attr
+}
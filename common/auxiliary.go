@@ -0,0 +1,49 @@
+// Copyright 2018 Publit Sweden AB. All rights reserved.
+
+package common
+
+import (
+	"net/url"
+	"strings"
+)
+
+// Query string key of the arguments of auxiliary attributes, see QueryAuxiliaryWithArgs.
+const QUERY_KEY_AUX_ARGS = QUERY_KEY_AUX + QUERY_ARGS_SUFFIX
+
+// AuxAttr is an auxiliary computed attribute with its arguments.
+type AuxAttr struct {
+	Name string
+	Args []string
+}
+
+// Helper to set auxiliary attributes with arguments to API query. The attributes are set like QueryAuxiliary, and the
+// arguments as "name;arg1;arg2" separated by commas in the auxiliary_args param, eg. "sales;2017-01-01;2017-12-31".
+// The arguments are escaped with EscapeAttrValue. Attributes without arguments are left out of auxiliary_args.
+// Functions with signature func(q url.Values) are implemented in the more specific SDKs of the PublitGoSDK packages.
+func QueryAuxiliaryWithArgs(attributes ...AuxAttr) func(q url.Values) {
+	names := []string{}
+	argStrings := []string{}
+
+	for _, v := range attributes {
+		names = append(names, v.Name)
+
+		if len(v.Args) > 0 {
+			parts := []string{v.Name}
+			for _, arg := range v.Args {
+				parts = append(parts, EscapeAttrValue(arg))
+			}
+			argStrings = append(argStrings, strings.Join(parts, ";"))
+		}
+	}
+
+	setAux := QueryAuxiliary(names...)
+	argString := strings.Join(argStrings, ",")
+
+	return func(q url.Values) {
+		setAux(q)
+
+		if argString != "" {
+			q.Add(QUERY_KEY_AUX_ARGS, argString)
+		}
+	}
+}
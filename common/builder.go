@@ -0,0 +1,209 @@
+// Copyright 2018 Publit Sweden AB. All rights reserved.
+
+package common
+
+import (
+	"net/url"
+)
+
+// QueryBuilder assembles the query params of a request to the Publit APIs, eg.
+//
+//	params := common.NewQuery().
+//		Where("state", common.OPERATOR_EQUAL, "published").
+//		With("authors").
+//		OrderBy("created_at", common.ORDER_DIR_DESC).
+//		Limit(50, 0).
+//		Build()
+type QueryBuilder struct {
+	attrs    []AttrQuery
+	withs    []string
+	scopes   []Scope
+	aux      []AuxAttr
+	order    []string
+	orderDir OrderDir
+	groupBy  []string
+	aggs     []Aggregate
+	params   []func(q url.Values)
+}
+
+// NewQuery returns an empty QueryBuilder.
+func NewQuery() *QueryBuilder {
+	return &QueryBuilder{}
+}
+
+// Where filters the attribute by the operator and value, escaped with EscapeAttrValue.
+// Filters of the same attribute are combined with AND, see QueryAttr.
+func (b *QueryBuilder) Where(name string, op Operator, value string) *QueryBuilder {
+	return b.WhereAttr(AttrQuery{
+		Name:  name,
+		Value: EscapeAttrValue(value),
+		Args: AttrArgs{
+			Operator:   []Operator{op},
+			Combinator: []Combinator{COMBINATOR_AND},
+		},
+	})
+}
+
+// WhereAttr adds the attribute filters, eg. built with AttrEq. Filters of an attribute already filtered on are
+// appended to its values and args, see QueryAttr.
+func (b *QueryBuilder) WhereAttr(attrs ...AttrQuery) *QueryBuilder {
+	for _, attr := range attrs {
+		b.addAttr(attr)
+	}
+	return b
+}
+
+// WhereIn filters the attribute by equality to any of the values, see AttrIn.
+func (b *QueryBuilder) WhereIn(name string, values ...interface{}) *QueryBuilder {
+	return b.WhereAttr(AttrIn(name, values...))
+}
+
+// addAttr adds the attribute filter, appending it to an existing filter of the attribute.
+func (b *QueryBuilder) addAttr(attr AttrQuery) {
+	for i := range b.attrs {
+		a := &b.attrs[i]
+		if a.Name == attr.Name {
+			a.Value += "," + attr.Value
+			a.Args.Operator = append(a.Args.Operator, attr.Args.Operator...)
+			a.Args.Combinator = append(a.Args.Combinator, attr.Args.Combinator...)
+			return
+		}
+	}
+
+	// Copy the args, as they are appended to.
+	attr.Args.Operator = append([]Operator(nil), attr.Args.Operator...)
+	attr.Args.Combinator = append([]Combinator(nil), attr.Args.Combinator...)
+	b.attrs = append(b.attrs, attr)
+}
+
+// Search searches the columns for the term, see QuerySearch.
+func (b *QueryBuilder) Search(term string, columns ...string) *QueryBuilder {
+	b.params = append(b.params, QuerySearch(term, columns...))
+	return b
+}
+
+// Has filters on the existence of related records matching the filters, see QueryHas.
+func (b *QueryBuilder) Has(relation string, filters ...AttrQuery) *QueryBuilder {
+	b.params = append(b.params, QueryHas(relation, filters...))
+	return b
+}
+
+// With includes the relations in the response, see QueryWith.
+func (b *QueryBuilder) With(relations ...string) *QueryBuilder {
+	b.withs = append(b.withs, relations...)
+	return b
+}
+
+// WithRelation includes the relation in the response with its own query params, see WithRelation.
+func (b *QueryBuilder) WithRelation(name string, params ...func(q url.Values)) *QueryBuilder {
+	b.withs = append(b.withs, name)
+	b.params = append(b.params, queryRelationParams(name, params...))
+	return b
+}
+
+// Scope applies the scopes, see QueryScope.
+func (b *QueryBuilder) Scope(scopes ...Scope) *QueryBuilder {
+	b.scopes = append(b.scopes, scopes...)
+	return b
+}
+
+// Auxiliary includes the auxiliary attributes in the response, see QueryAuxiliary.
+func (b *QueryBuilder) Auxiliary(attributes ...string) *QueryBuilder {
+	for _, v := range attributes {
+		b.aux = append(b.aux, AuxAttr{Name: v})
+	}
+	return b
+}
+
+// AuxiliaryWithArgs includes the auxiliary attributes with arguments in the response, see QueryAuxiliaryWithArgs.
+func (b *QueryBuilder) AuxiliaryWithArgs(attributes ...AuxAttr) *QueryBuilder {
+	b.aux = append(b.aux, attributes...)
+	return b
+}
+
+// OrderBy orders the response by the attribute, see QueryOrderBy.
+// Attributes of repeated calls are ordered by in turn. The API has a single direction, so the last one is used.
+func (b *QueryBuilder) OrderBy(attribute string, dir OrderDir) *QueryBuilder {
+	b.order = append(b.order, attribute)
+	b.orderDir = dir
+	return b
+}
+
+// GroupBy groups the response by the attributes, see QueryGroupBy.
+func (b *QueryBuilder) GroupBy(attributes ...string) *QueryBuilder {
+	b.groupBy = append(b.groupBy, attributes...)
+	return b
+}
+
+// Aggregate requests the aggregates, see QueryAggregate.
+func (b *QueryBuilder) Aggregate(aggregates ...Aggregate) *QueryBuilder {
+	b.aggs = append(b.aggs, aggregates...)
+	return b
+}
+
+// Locale sets the locale of localized attributes, see QueryLocale.
+func (b *QueryBuilder) Locale(locale string) *QueryBuilder {
+	b.params = append(b.params, QueryLocale(locale))
+	return b
+}
+
+// Market sets the market of store and price endpoints, see QueryMarket.
+func (b *QueryBuilder) Market(market string) *QueryBuilder {
+	b.params = append(b.params, QueryMarket(market))
+	return b
+}
+
+// Limit limits the response to limit records starting at offset, see QueryLimit.
+func (b *QueryBuilder) Limit(limit, offset int) *QueryBuilder {
+	b.params = append(b.params, QueryLimit(limit, offset))
+	return b
+}
+
+// Param adds any other query param func, eg. QueryAttrRange or the result of QueryExpr.
+func (b *QueryBuilder) Param(params ...func(q url.Values)) *QueryBuilder {
+	b.params = append(b.params, params...)
+	return b
+}
+
+// Build returns the query param funcs, to be passed to the request functions of the APIClient.
+func (b *QueryBuilder) Build() []func(q url.Values) {
+	params := []func(q url.Values){}
+
+	if len(b.attrs) > 0 {
+		params = append(params, QueryAttr(b.attrs...))
+	}
+	if len(b.withs) > 0 {
+		params = append(params, QueryWith(b.withs...))
+	}
+	if len(b.scopes) > 0 {
+		params = append(params, QueryScope(b.scopes))
+	}
+	if len(b.aux) > 0 {
+		params = append(params, QueryAuxiliaryWithArgs(b.aux...))
+	}
+	if len(b.order) > 0 {
+		params = append(params, QueryOrderBy(b.order, b.orderDir))
+	}
+	if len(b.groupBy) > 0 {
+		params = append(params, QueryGroupBy(b.groupBy))
+	}
+	if len(b.aggs) > 0 {
+		params = append(params, QueryAggregate(b.aggs...))
+	}
+
+	return append(params, b.params...)
+}
+
+// Values returns the built query params as url.Values.
+func (b *QueryBuilder) Values() url.Values {
+	q := url.Values{}
+	for _, f := range b.Build() {
+		f(q)
+	}
+	return q
+}
+
+// Validate validates the built query params, see ValidateQuery.
+func (b *QueryBuilder) Validate() error {
+	return ValidateQuery(b.Values())
+}
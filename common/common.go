@@ -4,7 +4,6 @@
 package common
 
 import (
-	"errors"
 	"fmt"
 	"net/url"
 	"reflect"
@@ -23,12 +22,24 @@ const (
 	QUERY_KEY_ORDER_DIR = "order_dir"
 	QUERY_ARGS_SUFFIX   = "_args"
 	QUERY_KEY_GROUP_BY  = "group_by"
+
+	QUERY_KEY_SEARCH         = "search"
+	QUERY_KEY_SEARCH_COLUMNS = "search_columns"
+
+	QUERY_KEY_HAS            = "has"
+	QUERY_RELATION_SEPARATOR = "."
+
+	QUERY_KEY_LOCALE = "locale"
+	QUERY_KEY_MARKET = "market"
 )
 
 // Operator describes the different operators implemented in Publits general API interface.
 type Operator int
 
 // Operator enum constants.
+// OPERATOR_LIKE matches values against a pattern where "%" matches any characters, while OPERATOR_BEGINS_WITH,
+// OPERATOR_ENDS_WITH and OPERATOR_CONTAINS match substrings of values without wildcards.
+// OPERATOR_BETWEEN matches values in an inclusive range given as two comma separated values, see QueryAttrRange.
 const (
 	OPERATOR_EQUAL Operator = 1 + iota
 	OPERATOR_NOT_EQUAL
@@ -36,6 +47,11 @@ const (
 	OPERATOR_GREATER
 	OPERATOR_LESS_EQUAL
 	OPERATOR_LESS
+	OPERATOR_LIKE
+	OPERATOR_BEGINS_WITH
+	OPERATOR_ENDS_WITH
+	OPERATOR_CONTAINS
+	OPERATOR_BETWEEN
 )
 
 // Combinator describes the different combinators implemented in Publits general API interface..
@@ -61,6 +77,11 @@ var operators = []string{
 	"GREATER",
 	"LESS_EQUAL",
 	"LESS",
+	"LIKE",
+	"BEGINS_WITH",
+	"ENDS_WITH",
+	"CONTAINS",
+	"BETWEEN",
 }
 
 // Combinator string
@@ -94,16 +115,19 @@ type APIErrorResponse struct {
 }
 
 // General Publit API error.
+// Validation errors also give the attribute that failed validation, the violated rule and the rejected value.
 type APIError struct {
-	Info string `json:"Info"`
-	Type string `json:"Type"`
+	Info  string      `json:"Info"`
+	Type  string      `json:"Type"`
+	Field string      `json:"Field,omitempty"`
+	Rule  string      `json:"Rule,omitempty"`
+	Value interface{} `json:"Value,omitempty"`
 }
 
 // Returns APIErrorResponse as error.
+// *APIErrorResponse implements error itself, so the APIErrorResponse is returned.
 func (e *APIErrorResponse) GetAsError() error {
-	return errors.New(
-		fmt.Sprintf(`Code: "%v", Type: "%v", Combined info: "%v"`, e.Code, e.Type, e.CombinedInfo),
-	)
+	return e
 }
 
 // Checks if APIErrorResponse is set.
@@ -124,6 +148,7 @@ func QueryLimit(limit, offset int) func(q url.Values) {
 }
 
 // Helper to set With parameter to API query.
+// Each call adds a with param, see WithRelation for appending to an existing one.
 // Functions with signature func(q url.Values) are implemented in the more specific SDKs of the PublitGoSDK packages.
 func QueryWith(withs ...string) func(q url.Values) {
 	withString := strings.Join(withs, ",")
@@ -140,13 +165,14 @@ type Scope struct {
 }
 
 // Helper to sets scope parameter to API query.
+// The filters of the scopes are escaped with EscapeAttrValue.
 // Functions with signature func(q url.Values) are implemented in the more specific SDKs of the PublitGoSDK packages.
 func QueryScope(scopes []Scope) func(q url.Values) {
 	var scopeStrings []string
 	for _, v := range scopes {
 		str := ""
 		if v.Filter != "" {
-			str = fmt.Sprintf("%v;%v", v.Scope, v.Filter)
+			str = fmt.Sprintf("%v;%v", v.Scope, EscapeAttrValue(v.Filter))
 		} else {
 			str = v.Scope
 		}
@@ -184,6 +210,60 @@ func QueryOrderBy(attributes []string, dir OrderDir) func(q url.Values) {
 	}
 }
 
+// Helper to set a free-text search to API query, matching records with any of the columns containing the term.
+// Without columns the API searches its default columns of the resource.
+// Functions with signature func(q url.Values) are implemented in the more specific SDKs of the PublitGoSDK packages.
+func QuerySearch(term string, columns ...string) func(q url.Values) {
+	columnString := strings.Join(columns, ",")
+
+	return func(q url.Values) {
+		q.Add(QUERY_KEY_SEARCH, term)
+
+		if columnString != "" {
+			q.Add(QUERY_KEY_SEARCH_COLUMNS, columnString)
+		}
+	}
+}
+
+// Helper to set the locale of localized attributes to API query, eg. "sv_SE", replacing any earlier locale.
+// The locale param is used by store and price endpoints, and is sent as is. Localized metadata endpoints instead read
+// the Accept-Language header set by the APIClient.WithLocale request option. Use the one documented by the endpoint.
+// Functions with signature func(q url.Values) are implemented in the more specific SDKs of the PublitGoSDK packages.
+func QueryLocale(locale string) func(q url.Values) {
+	return func(q url.Values) {
+		q.Set(QUERY_KEY_LOCALE, locale)
+	}
+}
+
+// Helper to set the market of store and price endpoints to API query, eg. "SE", replacing any earlier market.
+// Functions with signature func(q url.Values) are implemented in the more specific SDKs of the PublitGoSDK packages.
+func QueryMarket(market string) func(q url.Values) {
+	return func(q url.Values) {
+		q.Set(QUERY_KEY_MARKET, market)
+	}
+}
+
+// Helper to set any query param to API query, for early-access or undocumented params of the Publit APIs.
+// The value is sent as is.
+// Functions with signature func(q url.Values) are implemented in the more specific SDKs of the PublitGoSDK packages.
+func QueryRaw(key, value string) func(q url.Values) {
+	return func(q url.Values) {
+		q.Add(key, value)
+	}
+}
+
+// Helper to set any query params to API query, see QueryRaw.
+// Functions with signature func(q url.Values) are implemented in the more specific SDKs of the PublitGoSDK packages.
+func QueryRawValues(values url.Values) func(q url.Values) {
+	return func(q url.Values) {
+		for k, v := range values {
+			for _, value := range v {
+				q.Add(k, value)
+			}
+		}
+	}
+}
+
 // QueryGroupBy sets group by query to API query.
 func QueryGroupBy(attributes []string) func(q url.Values) {
 	groupByString := strings.Join(attributes, ",")
@@ -207,6 +287,8 @@ type AttrArgs struct {
 }
 
 // Helper to set attribute filters to API query.
+// AttrQuery.Value is sent as is, as it may hold several comma separated values. The constructors such as AttrEq escape
+// values containing delimiters with EscapeAttrValue; escape values set directly on AttrQuery.Value with it too.
 // Functions with signature func(q url.Values) are implemented in the more specific SDKs of the PublitGoSDK packages.
 func QueryAttr(attributes ...AttrQuery) func(q url.Values) {
 	return func(q url.Values) {
@@ -230,6 +312,47 @@ func QueryAttr(attributes ...AttrQuery) func(q url.Values) {
 	}
 }
 
+// Helper to filter on the existence of related records to API query, matching records with at least one record of the
+// relation that matches the filters. The filters are set on the attributes of the relation, prefixed by the relation
+// name and QUERY_RELATION_SEPARATOR, eg. "authors.name".
+// Functions with signature func(q url.Values) are implemented in the more specific SDKs of the PublitGoSDK packages.
+func QueryHas(relation string, filters ...AttrQuery) func(q url.Values) {
+	attrs := make([]AttrQuery, len(filters))
+	for i, v := range filters {
+		v.Name = relation + QUERY_RELATION_SEPARATOR + v.Name
+		attrs[i] = v
+	}
+
+	return func(q url.Values) {
+		q.Add(QUERY_KEY_HAS, relation)
+		QueryAttr(attrs...)(q)
+	}
+}
+
+// Helper to set an inclusive range filter of an attribute to API query, rendered as the BETWEEN operator with the values
+// "from,to". An empty from or to gives an open range, rendered as LESS_EQUAL or GREATER_EQUAL. If both are empty the
+// attribute is not filtered.
+// Functions with signature func(q url.Values) are implemented in the more specific SDKs of the PublitGoSDK packages.
+func QueryAttrRange(name, from, to string) func(q url.Values) {
+	attr := AttrQuery{Name: name}
+
+	switch {
+	case from == "" && to == "":
+		return func(q url.Values) {}
+	case from == "":
+		attr.Value = EscapeAttrValue(to)
+		attr.Args.Operator = []Operator{OPERATOR_LESS_EQUAL}
+	case to == "":
+		attr.Value = EscapeAttrValue(from)
+		attr.Args.Operator = []Operator{OPERATOR_GREATER_EQUAL}
+	default:
+		attr.Value = fmt.Sprintf("%v,%v", EscapeAttrValue(from), EscapeAttrValue(to))
+		attr.Args.Operator = []Operator{OPERATOR_BETWEEN}
+	}
+
+	return QueryAttr(attr)
+}
+
 func (a AttrArgs) IsEmpty() bool {
 	return reflect.DeepEqual(a, AttrArgs{})
 }
@@ -239,7 +362,7 @@ func (a AttrArgs) IsEmpty() bool {
 func (timeString PublitTime) ConvertPublitTimeToTime() (time.Time, error) {
 	t := time.Time{}
 	if timeString != "" {
-		t, err := time.Parse("2006-01-02 15:04:05", string(timeString))
+		t, err := time.Parse(PUBLIT_TIME_FORMAT, string(timeString))
 
 		return t, err
 	}
@@ -1,9 +1,13 @@
 package common
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
+	"reflect"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -93,6 +97,117 @@ func TestOrderDirEnumCanOnlyBeSetToExistingValues(t *testing.T) {
 	o.AsString()
 }
 
+func TestEnumsCanBeConvertedWithoutPanicking(t *testing.T) {
+	t.Parallel()
+
+	if s, err := OPERATOR_BETWEEN.TryAsString(); err != nil || s != "BETWEEN" {
+		t.Errorf("Unexpected operator string %q, %v", s, err)
+	}
+	if s, err := COMBINATOR_OR.TryAsString(); err != nil || s != "OR" {
+		t.Errorf("Unexpected combinator string %q, %v", s, err)
+	}
+	if s, err := ORDER_DIR_DESC.TryAsString(); err != nil || s != "DESC" {
+		t.Errorf("Unexpected order direction string %q, %v", s, err)
+	}
+
+	invalid := map[string]func() (string, error){
+		"Operator":   Operator(42).TryAsString,
+		"Zero":       Operator(0).TryAsString,
+		"Combinator": Combinator(42).TryAsString,
+		"OrderDir":   OrderDir(-1).TryAsString,
+	}
+	for name, f := range invalid {
+		if _, err := f(); !errors.Is(err, ErrInvalidEnum) {
+			t.Errorf("%v: Expected ErrInvalidEnum, got %v", name, err)
+		}
+	}
+}
+
+func TestCanParseEnums(t *testing.T) {
+	t.Parallel()
+
+	if o, err := ParseOperator("greater_equal"); err != nil || o != OPERATOR_GREATER_EQUAL {
+		t.Errorf("Unexpected operator %v, %v", o, err)
+	}
+	if c, err := ParseCombinator(" OR "); err != nil || c != COMBINATOR_OR {
+		t.Errorf("Unexpected combinator %v, %v", c, err)
+	}
+	if d, err := ParseOrderDir("Desc"); err != nil || d != ORDER_DIR_DESC {
+		t.Errorf("Unexpected order direction %v, %v", d, err)
+	}
+
+	if _, err := ParseOperator("EQUALS"); !errors.Is(err, ErrInvalidEnum) {
+		t.Errorf("Expected ErrInvalidEnum, got %v", err)
+	}
+	if _, err := ParseCombinator("XOR"); !errors.Is(err, ErrInvalidEnum) {
+		t.Errorf("Expected ErrInvalidEnum, got %v", err)
+	}
+	if _, err := ParseOrderDir(""); !errors.Is(err, ErrInvalidEnum) {
+		t.Errorf("Expected ErrInvalidEnum, got %v", err)
+	}
+}
+
+func TestEnumsImplementStringer(t *testing.T) {
+	t.Parallel()
+
+	tests := map[fmt.Stringer]string{
+		OPERATOR_NOT_EQUAL: "NOT_EQUAL",
+		Operator(42):       "Operator(42)",
+		COMBINATOR_AND:     "AND",
+		Combinator(0):      "Combinator(0)",
+		ORDER_DIR_ASC:      "ASC",
+		OrderDir(3):        "OrderDir(3)",
+	}
+
+	for v, expected := range tests {
+		if s := fmt.Sprint(v); s != expected {
+			t.Errorf("Expected %q, got %q", expected, s)
+		}
+	}
+}
+
+func TestEnumsRoundTripJSON(t *testing.T) {
+	t.Parallel()
+
+	type savedFilter struct {
+		Operators  []Operator `json:"operators"`
+		Combinator Combinator `json:"combinator"`
+		OrderDir   OrderDir   `json:"order_dir"`
+	}
+
+	in := savedFilter{
+		Operators:  []Operator{OPERATOR_EQUAL, OPERATOR_CONTAINS},
+		Combinator: COMBINATOR_OR,
+	}
+
+	b, err := json.Marshal(in)
+	if err != nil {
+		t.Fatalf("Received an error but did not expect one: %v", err)
+	}
+
+	expected := `{"operators":["EQUAL","CONTAINS"],"combinator":"OR","order_dir":null}`
+	if string(b) != expected {
+		t.Errorf("Expected %s, got %s", expected, b)
+	}
+
+	out := savedFilter{}
+	if err := json.Unmarshal(b, &out); err != nil {
+		t.Fatalf("Received an error but did not expect one: %v", err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Errorf("Expected %+v, got %+v", in, out)
+	}
+
+	if _, err := json.Marshal(savedFilter{Combinator: 42}); !errors.Is(err, ErrInvalidEnum) {
+		t.Errorf("Expected ErrInvalidEnum, got %v", err)
+	}
+	for _, data := range []string{`{"combinator":"XOR"}`, `{"order_dir":1}`} {
+		if err := json.Unmarshal([]byte(data), &savedFilter{}); !errors.Is(err, ErrInvalidEnum) {
+			t.Errorf("Expected ErrInvalidEnum for %s, got %v", data, err)
+		}
+	}
+}
+
 func TestCanSetOrderByQueryString(t *testing.T) {
 	t.Parallel()
 	t.Run(
@@ -144,6 +259,11 @@ func TestOperatorEnumCanBeViewedAsString(t *testing.T) {
 		"LESS_EQUAL":    OPERATOR_LESS_EQUAL,
 		"LESS":          OPERATOR_LESS,
 		"NOT_EQUAL":     OPERATOR_NOT_EQUAL,
+		"LIKE":          OPERATOR_LIKE,
+		"BEGINS_WITH":   OPERATOR_BEGINS_WITH,
+		"ENDS_WITH":     OPERATOR_ENDS_WITH,
+		"CONTAINS":      OPERATOR_CONTAINS,
+		"BETWEEN":       OPERATOR_BETWEEN,
 	}
 	for expected, o := range operators {
 		t.Run(
@@ -291,6 +411,351 @@ func TestCanSetAttributeQuery(t *testing.T) {
 	)
 }
 
+func TestCanSetSubstringAttributeQuery(t *testing.T) {
+	t.Parallel()
+	q := url.Values{}
+
+	QueryAttr(
+		AttrQuery{Name: "title", Value: "harry", Args: AttrArgs{Operator: []Operator{OPERATOR_CONTAINS}}},
+		AttrQuery{Name: "name", Value: "J%Rowling", Args: AttrArgs{Operator: []Operator{OPERATOR_LIKE}}},
+	)(q)
+
+	assertQueryStringEqual("title", "harry", q, t)
+	assertQueryStringEqual("title"+QUERY_ARGS_SUFFIX, "CONTAINS", q, t)
+	assertQueryStringEqual("name", "J%Rowling", q, t)
+	assertQueryStringEqual("name"+QUERY_ARGS_SUFFIX, "LIKE", q, t)
+}
+
+func TestCanSetAttributeRangeQuery(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		from, to    string
+		value, args string
+	}{
+		"Closed range":     {"2017-01-01", "2017-12-31", "2017-01-01,2017-12-31", "BETWEEN"},
+		"Open lower bound": {"", "2017-12-31", "2017-12-31", "LESS_EQUAL"},
+		"Open upper bound": {"2017-01-01", "", "2017-01-01", "GREATER_EQUAL"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			q := url.Values{}
+			QueryAttrRange("created_at", tt.from, tt.to)(q)
+
+			assertQueryStringEqual("created_at", tt.value, q, t)
+			assertQueryStringEqual("created_at"+QUERY_ARGS_SUFFIX, tt.args, q, t)
+		})
+	}
+
+	t.Run("Unbounded range", func(t *testing.T) {
+		q := url.Values{}
+		QueryAttrRange("created_at", "", "")(q)
+
+		if len(q) != 0 {
+			t.Errorf("Expected no filter for an unbounded range. Got %v", q)
+		}
+	})
+}
+
+func TestCanSetSearchQuery(t *testing.T) {
+	t.Parallel()
+
+	t.Run("With columns", func(t *testing.T) {
+		q := url.Values{}
+		QuerySearch("harry potter", "title", "isbn")(q)
+
+		assertQueryStringEqual(QUERY_KEY_SEARCH, "harry potter", q, t)
+		assertQueryStringEqual(QUERY_KEY_SEARCH_COLUMNS, "title,isbn", q, t)
+	})
+
+	t.Run("Without columns", func(t *testing.T) {
+		q := url.Values{}
+		QuerySearch("9789100000000")(q)
+
+		assertQueryStringEqual(QUERY_KEY_SEARCH, "9789100000000", q, t)
+		if _, ok := q[QUERY_KEY_SEARCH_COLUMNS]; ok {
+			t.Errorf("Expected no %v, got %v", QUERY_KEY_SEARCH_COLUMNS, q)
+		}
+	})
+}
+
+func TestCanSetHasQuery(t *testing.T) {
+	t.Parallel()
+
+	q := url.Values{}
+	QueryHas("authors", AttrQuery{
+		Name:  "name",
+		Value: "Rowling",
+		Args:  AttrArgs{Operator: []Operator{OPERATOR_CONTAINS}},
+	})(q)
+
+	assertQueryStringEqual(QUERY_KEY_HAS, "authors", q, t)
+	assertQueryStringEqual("authors.name", "Rowling", q, t)
+	assertQueryStringEqual("authors.name"+QUERY_ARGS_SUFFIX, "CONTAINS", q, t)
+
+	q = url.Values{}
+	QueryHas("prices")(q)
+
+	if q.Encode() != "has=prices" {
+		t.Errorf("Unexpected query. Got %v", q.Encode())
+	}
+}
+
+func TestCanCreateTypedAttributeFilters(t *testing.T) {
+	t.Parallel()
+
+	created := time.Date(2017, 7, 10, 19, 5, 0, 0, time.FixedZone("CEST", 2*60*60))
+
+	tests := map[string]struct {
+		attr     AttrQuery
+		value    string
+		operator Operator
+	}{
+		"Int":          {AttrEq("id", 5), "5", OPERATOR_EQUAL},
+		"Int64":        {AttrNotEq("id", int64(-12)), "-12", OPERATOR_NOT_EQUAL},
+		"Uint":         {AttrGreaterEq("pages", uint16(300)), "300", OPERATOR_GREATER_EQUAL},
+		"Float":        {AttrLess("price", 1e7+0.5), "10000000.5", OPERATOR_LESS},
+		"Float32":      {AttrLessEq("rating", float32(4.2)), "4.2", OPERATOR_LESS_EQUAL},
+		"Bool":         {AttrIs("live", true), "true", OPERATOR_EQUAL},
+		"Time before":  {AttrBefore("created_at", created), "2017-07-10 17:05:00", OPERATOR_LESS},
+		"Time after":   {AttrAfter("created_at", created), "2017-07-10 17:05:00", OPERATOR_GREATER},
+		"Decimal":      {AttrGreater("price", MustParseDecimal("99.50")), "99.50", OPERATOR_GREATER},
+		"String":       {AttrEq("title", "Dune"), "Dune", OPERATOR_EQUAL},
+		"Generic attr": {Attr("title", OPERATOR_CONTAINS, "Dune"), "Dune", OPERATOR_CONTAINS},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if tt.attr.Value != tt.value {
+				t.Errorf("Expected value %q, got %q", tt.value, tt.attr.Value)
+			}
+			if !reflect.DeepEqual(tt.attr.Args, AttrArgs{Operator: []Operator{tt.operator}, Combinator: []Combinator{COMBINATOR_AND}}) {
+				t.Errorf("Unexpected args %+v", tt.attr.Args)
+			}
+		})
+	}
+
+	q := NewQuery().WhereAttr(AttrGreaterEq("price", 10), AttrLess("price", 20)).Values()
+	assertQueryStringEqual("price", "10,20", q, t)
+	assertQueryStringEqual("price"+QUERY_ARGS_SUFFIX, "GREATER_EQUAL;AND,LESS;AND", q, t)
+}
+
+func TestCanSetOrListAttributeQuery(t *testing.T) {
+	t.Parallel()
+
+	q := NewQuery().WhereIn("state", "draft", "review").Values()
+	assertQueryStringEqual("state", "draft,review", q, t)
+	assertQueryStringEqual("state"+QUERY_ARGS_SUFFIX, "EQUAL;AND,EQUAL;OR", q, t)
+
+	q = url.Values{}
+	QueryAttr(AttrNotIn("id", 1, 2, 3))(q)
+	assertQueryStringEqual("id", "1,2,3", q, t)
+	assertQueryStringEqual("id"+QUERY_ARGS_SUFFIX, "NOT_EQUAL;AND,NOT_EQUAL;AND,NOT_EQUAL;AND", q, t)
+
+	q = url.Values{}
+	QueryAttr(AttrIn("id", 7))(q)
+	assertQueryStringEqual("id", "7", q, t)
+	assertQueryStringEqual("id"+QUERY_ARGS_SUFFIX, "EQUAL;AND", q, t)
+}
+
+func TestFilterValuesAreEscaped(t *testing.T) {
+	t.Parallel()
+
+	title := `Crime, Punishment; etc. \ more`
+	escaped := `Crime\, Punishment\; etc. \\ more`
+
+	if EscapeAttrValue(title) != escaped {
+		t.Errorf("Expected %q, got %q", escaped, EscapeAttrValue(title))
+	}
+	if UnescapeAttrValue(escaped) != title {
+		t.Errorf("Expected %q, got %q", title, UnescapeAttrValue(escaped))
+	}
+
+	q := url.Values{}
+	QueryAttr(AttrEq("title", title))(q)
+	assertQueryStringEqual("title", escaped, q, t)
+
+	q = NewQuery().Where("title", OPERATOR_EQUAL, title).Values()
+	assertQueryStringEqual("title", escaped, q, t)
+
+	q = NewQuery().WhereIn("title", title, "Dune").Values()
+	assertQueryStringEqual("title", escaped+",Dune", q, t)
+	if values := SplitAttrValues(q.Get("title")); !reflect.DeepEqual(values, []string{title, "Dune"}) {
+		t.Errorf("Unexpected split values %q", values)
+	}
+
+	f, err := QueryExpr(Cond("title", OPERATOR_EQUAL, "a,b"))
+	if err != nil {
+		t.Fatalf("Received an error but did not expect one: %v", err)
+	}
+	q = url.Values{}
+	f(q)
+	assertQueryStringEqual("title", `a\,b`, q, t)
+
+	q = url.Values{}
+	QueryAttrRange("title", "a,b", "c;d")(q)
+	assertQueryStringEqual("title", `a\,b,c\;d`, q, t)
+
+	q = url.Values{}
+	QueryScope([]Scope{{Scope: "search", Filter: "Crime, Punishment"}, {Scope: "active"}})(q)
+	assertQueryStringEqual(QUERY_KEY_SCOPE, `search;Crime\, Punishment,active`, q, t)
+
+	if values := SplitAttrValues(""); !reflect.DeepEqual(values, []string{""}) {
+		t.Errorf("Unexpected split values %q", values)
+	}
+}
+
+func TestCanSetRawQuery(t *testing.T) {
+	t.Parallel()
+
+	q := url.Values{}
+	QueryRaw("beta_feature", "on")(q)
+	QueryRawValues(url.Values{"tag": {"a", "b"}, "x": {"1"}})(q)
+
+	expected := "beta_feature=on&tag=a&tag=b&x=1"
+	if q.Encode() != expected {
+		t.Errorf("Expected %q, got %q", expected, q.Encode())
+	}
+}
+
+func TestScopeBuilderValidatesScopes(t *testing.T) {
+	t.Parallel()
+
+	RegisterScopes("scopetest_products",
+		ScopeDef{Name: "active", Filter: SCOPE_FILTER_NONE},
+		ScopeDef{Name: "search"},
+		ScopeDef{
+			Name:   "publisher",
+			Filter: SCOPE_FILTER_REQUIRED,
+			ValidateFilter: func(filter string) error {
+				if _, err := strconv.Atoi(filter); err != nil {
+					return errors.New("Publisher must be an id")
+				}
+				return nil
+			},
+		},
+	)
+
+	f, err := NewScopes("scopetest_products").Add("active").AddFilter("search", "dune").AddFilter("publisher", "42").Query()
+	if err != nil {
+		t.Fatalf("Received an error but did not expect one: %v", err)
+	}
+
+	q := url.Values{}
+	f(q)
+	assertQueryStringEqual(QUERY_KEY_SCOPE, "active,search;dune,publisher;42", q, t)
+
+	tests := map[string]struct {
+		builder  *ScopeBuilder
+		expected error
+	}{
+		"Unknown scope":          {NewScopes("scopetest_products").Add("activ"), ErrUnknownScope},
+		"Unknown resource":       {NewScopes("scopetest_unknown").Add("active"), ErrUnknownScope},
+		"Filter of no filter":    {NewScopes("scopetest_products").AddFilter("active", "1"), ErrInvalidScopeFilter},
+		"Missing filter":         {NewScopes("scopetest_products").Add("publisher"), ErrInvalidScopeFilter},
+		"Filter fails validator": {NewScopes("scopetest_products").AddFilter("publisher", "Acme"), ErrInvalidScopeFilter},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := tt.builder.Build(); !errors.Is(err, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestCanSetLocaleAndMarketQuery(t *testing.T) {
+	t.Parallel()
+
+	q := NewQuery().Locale("en_GB").Market("SE").Locale("sv_SE").Values()
+
+	if err := ValidateQuery(q); err != nil {
+		t.Errorf("Received an error but did not expect one: %v", err)
+	}
+	assertQueryStringEqual(QUERY_KEY_LOCALE, "sv_SE", q, t)
+	assertQueryStringEqual(QUERY_KEY_MARKET, "SE", q, t)
+}
+
+func TestCanSetRelationQuery(t *testing.T) {
+	t.Parallel()
+
+	q := url.Values{}
+	QueryWith("prices")(q)
+	WithRelation("authors",
+		QueryAttr(AttrEq("role", "writer")),
+		QueryOrderBy([]string{"name"}, ORDER_DIR_ASC),
+		QueryLimit(5, 0),
+		WithRelation("books", QueryLimit(1, 0)),
+	)(q)
+
+	expected := map[string]string{
+		QUERY_KEY_WITH:                  "prices,authors",
+		"with.authors.role":             "writer",
+		"with.authors.role_args":        "EQUAL;AND",
+		"with.authors.order_by":         "name",
+		"with.authors.order_dir":        "ASC",
+		"with.authors.limit":            "0,5",
+		"with.authors.with":             "books",
+		"with.authors.with.books.limit": "0,1",
+	}
+	for k, v := range expected {
+		assertQueryStringEqual(k, v, q, t)
+	}
+	if len(q) != len(expected) {
+		t.Errorf("Unexpected query. Got %v", q)
+	}
+
+	q = NewQuery().With("prices").WithRelation("authors", QueryLimit(5, 0)).Values()
+	assertQueryStringEqual(QUERY_KEY_WITH, "prices,authors", q, t)
+	assertQueryStringEqual("with.authors.limit", "0,5", q, t)
+	if err := ValidateQuery(q); err != nil {
+		t.Errorf("Received an error but did not expect one: %v", err)
+	}
+
+	q = url.Values{}
+	QueryWith("prices")(q)
+	QueryWith("cover")(q)
+	if len(q[QUERY_KEY_WITH]) != 2 {
+		t.Errorf("Expected QueryWith to add a with param per call. Got %v", q[QUERY_KEY_WITH])
+	}
+
+	WithRelation("authors")(q)
+	if len(q[QUERY_KEY_WITH]) != 1 {
+		t.Errorf("Expected a single with param. Got %v", q[QUERY_KEY_WITH])
+	}
+	assertQueryStringEqual(QUERY_KEY_WITH, "prices,cover,authors", q, t)
+}
+
+func TestCanSetAuxiliaryWithArgsQuery(t *testing.T) {
+	t.Parallel()
+
+	q := NewQuery().
+		Auxiliary("cover").
+		AuxiliaryWithArgs(
+			AuxAttr{Name: "sales", Args: []string{"2017-01-01", "2017-12-31"}},
+			AuxAttr{Name: "price", Args: []string{"SE", "incl, VAT"}},
+		).
+		Values()
+
+	assertQueryStringEqual(QUERY_KEY_AUX, "cover,sales,price", q, t)
+	assertQueryStringEqual(QUERY_KEY_AUX_ARGS, `sales;2017-01-01;2017-12-31,price;SE;incl\, VAT`, q, t)
+	if err := ValidateQuery(q); err != nil {
+		t.Errorf("Received an error but did not expect one: %v", err)
+	}
+
+	q = url.Values{}
+	QueryAuxiliaryWithArgs(AuxAttr{Name: "cover"})(q)
+	if q.Encode() != "auxiliary=cover" {
+		t.Errorf("Unexpected query. Got %v", q.Encode())
+	}
+
+	if err := ValidateQuery(url.Values{QUERY_KEY_AUX_ARGS: {"sales;2017"}}); !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("Expected ErrInvalidQuery, got %v", err)
+	}
+}
+
 func TestCanSetGroupByQuery(t *testing.T) {
 	t.Parallel()
 
@@ -306,6 +771,74 @@ func TestCanSetGroupByQuery(t *testing.T) {
 	assertQueryStringEqual(QUERY_KEY_GROUP_BY, expected, q, t)
 }
 
+func TestCanSetAggregateQuery(t *testing.T) {
+	t.Parallel()
+
+	q := NewQuery().
+		GroupBy("publisher_id").
+		Aggregate(AggregateSum("price"), AggregateCount("id")).
+		Aggregate(AggregateMin("created_at"), AggregateMax("created_at"), AggregateAvg("price")).
+		Values()
+
+	assertQueryStringEqual(QUERY_KEY_GROUP_BY, "publisher_id", q, t)
+	assertQueryStringEqual(QUERY_KEY_AGGREGATE, "SUM;price,COUNT;id,MIN;created_at,MAX;created_at,AVG;price", q, t)
+
+	if f, err := ParseAggregateFunc("avg"); err != nil || f != AGGREGATE_AVG || f.String() != "AVG" {
+		t.Errorf("Unexpected aggregate function %v, %v", f, err)
+	}
+	if _, err := ParseAggregateFunc("MEDIAN"); !errors.Is(err, ErrInvalidEnum) {
+		t.Errorf("Expected ErrInvalidEnum, got %v", err)
+	}
+
+	q = url.Values{}
+	QueryAggregate(Aggregate{Column: "price"}, AggregateSum("price"), Aggregate{Func: 42, Column: "id"})(q)
+	assertQueryStringEqual(QUERY_KEY_AGGREGATE, "SUM;price", q, t)
+
+	q = url.Values{}
+	QueryAggregate(Aggregate{Column: "price"})(q)
+	if len(q) != 0 {
+		t.Errorf("Expected invalid aggregates to be left out. Got %v", q)
+	}
+	if _, err := AggregateFunc(0).TryAsString(); !errors.Is(err, ErrInvalidEnum) {
+		t.Errorf("Expected ErrInvalidEnum, got %v", err)
+	}
+}
+
+func TestCanSerializeAndParseQuery(t *testing.T) {
+	t.Parallel()
+
+	b := NewQuery().
+		Where("state", OPERATOR_EQUAL, "published").
+		WhereIn("title", "Crime, Punishment", "Dune").
+		OrderBy("created_at", ORDER_DIR_DESC).
+		Limit(50, 0)
+
+	s := b.String()
+	if s != SerializeQuery(b.Build()...) {
+		t.Errorf("Expected the builder to serialize like SerializeQuery, got %q", s)
+	}
+	if reordered := SerializeQuery(QueryLimit(50, 0), QueryOrderBy([]string{"created_at"}, ORDER_DIR_DESC), QueryAttr(b.attrs...)); reordered != s {
+		t.Errorf("Expected the serialization to be canonical. Got %q and %q", s, reordered)
+	}
+
+	for _, in := range []string{s, "?" + s} {
+		f, err := ParseQuery(in)
+		if err != nil {
+			t.Fatalf("Received an error but did not expect one: %v", err)
+		}
+
+		q := url.Values{}
+		f(q)
+		if !reflect.DeepEqual(q, b.Values()) {
+			t.Errorf("Expected %v, got %v", b.Values(), q)
+		}
+	}
+
+	if _, err := ParseQuery("a=%zz"); err == nil {
+		t.Error("Did not receive an error but was expecting one.")
+	}
+}
+
 func TestCanConvertPublitTimeToTime(t *testing.T) {
 	t.Parallel()
 	publitTimeStr := PublitTime("2017-07-10 17:05:00")
@@ -347,6 +880,219 @@ func TestCanParsePublitBoolToBool(t *testing.T) {
 	}
 }
 
+func TestCanParsePublitBoolStrictly(t *testing.T) {
+	t.Parallel()
+
+	publitBools := map[PublitBool]bool{"True": true, "TRUE": true, "true": true, "False": false, "FALSE": false, "false": false}
+	for pb, b := range publitBools {
+		cb, err := pb.ParseBool()
+		if err != nil {
+			t.Errorf("Received an error but did not expect one: %v", err)
+		}
+		if cb != b {
+			t.Errorf("Bool %q was not converted as expected.", pb)
+		}
+	}
+
+	for _, pb := range []PublitBool{"", "yes", "1", "truthy"} {
+		if _, err := pb.ParseBool(); !errors.Is(err, ErrInvalidPublitBool) {
+			t.Errorf("Expected ErrInvalidPublitBool for %q, got %v", pb, err)
+		}
+	}
+}
+
+func TestPublitBoolRoundTripsJSON(t *testing.T) {
+	t.Parallel()
+
+	type payload struct {
+		Active PublitBool `json:"active"`
+	}
+
+	tests := map[string]struct {
+		in, out  string
+		expected PublitBool
+	}{
+		"String":  {`{"active":"True"}`, `{"active":"True"}`, "True"},
+		"Boolean": {`{"active":false}`, `{"active":"false"}`, "false"},
+		"Empty":   {`{"active":""}`, `{"active":""}`, ""},
+		"Null":    {`{"active":null}`, `{"active":""}`, ""},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			p := payload{}
+			if err := json.Unmarshal([]byte(tt.in), &p); err != nil {
+				t.Fatalf("Received an error but did not expect one: %v", err)
+			}
+			if p.Active != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, p.Active)
+			}
+
+			b, err := json.Marshal(p)
+			if err != nil {
+				t.Fatalf("Received an error but did not expect one: %v", err)
+			}
+			if string(b) != tt.out {
+				t.Errorf("Expected %s, got %s", tt.out, b)
+			}
+		})
+	}
+
+	if err := json.Unmarshal([]byte(`{"active":"maybe"}`), &payload{}); !errors.Is(err, ErrInvalidPublitBool) {
+		t.Errorf("Expected ErrInvalidPublitBool, got %v", err)
+	}
+	if _, err := json.Marshal(payload{Active: "maybe"}); !errors.Is(err, ErrInvalidPublitBool) {
+		t.Errorf("Expected ErrInvalidPublitBool, got %v", err)
+	}
+}
+
+func TestNullableDistinguishesAbsentNullAndZero(t *testing.T) {
+	t.Parallel()
+
+	type response struct {
+		Title     NullString     `json:"title"`
+		Pages     NullInt        `json:"pages"`
+		Active    NullBool       `json:"active"`
+		CreatedAt NullPublitTime `json:"created_at"`
+	}
+
+	r := response{}
+	if err := json.Unmarshal([]byte(`{"title":null,"pages":0,"active":"true"}`), &r); err != nil {
+		t.Fatalf("Received an error but did not expect one: %v", err)
+	}
+
+	if !r.Title.Set || r.Title.Valid {
+		t.Errorf("Expected title to be null, got %+v", r.Title)
+	}
+	if !r.Pages.Set || !r.Pages.Valid || r.Pages.Value != 0 {
+		t.Errorf("Expected pages to be zero, got %+v", r.Pages)
+	}
+	if r.Active != NewNullable(PublitBool("true")) {
+		t.Errorf("Expected active to be true, got %+v", r.Active)
+	}
+	if r.CreatedAt.Set || r.CreatedAt.Valid {
+		t.Errorf("Expected created_at to be absent, got %+v", r.CreatedAt)
+	}
+	if r.Title.Ptr() != nil || *r.Pages.Ptr() != 0 {
+		t.Errorf("Unexpected pointers of %+v", r)
+	}
+}
+
+func TestNullableMarshalsPayloads(t *testing.T) {
+	t.Parallel()
+
+	type payload struct {
+		Title *NullString `json:"title,omitempty"`
+		Pages *NullInt    `json:"pages,omitempty"`
+		ISBN  *NullString `json:"isbn,omitempty"`
+	}
+
+	cleared := Null[string]()
+	pages := NewNullable(0)
+
+	b, err := json.Marshal(payload{Title: &cleared, Pages: &pages})
+	if err != nil {
+		t.Fatalf("Received an error but did not expect one: %v", err)
+	}
+
+	expected := `{"title":null,"pages":0}`
+	if string(b) != expected {
+		t.Errorf("Expected %s, got %s", expected, b)
+	}
+}
+
+func TestCanParseAndFormatPublitDecimal(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"99.50":  "99.50",
+		"-0.05":  "-0.05",
+		"+12":    "12",
+		".5":     "0.5",
+		"7.":     "7",
+		"0.0001": "0.0001",
+	}
+
+	for in, expected := range tests {
+		d, err := ParseDecimal(in)
+		if err != nil {
+			t.Errorf("Received an error but did not expect one for %q: %v", in, err)
+			continue
+		}
+		if d.String() != expected {
+			t.Errorf("Expected %q to format as %q, got %q", in, expected, d.String())
+		}
+	}
+
+	for _, in := range []string{"", "-", ".", "1,50", "1e3", "abc", "1.2.3"} {
+		if _, err := ParseDecimal(in); !errors.Is(err, ErrInvalidDecimal) {
+			t.Errorf("Expected ErrInvalidDecimal for %q, got %v", in, err)
+		}
+	}
+}
+
+func TestPublitDecimalArithmeticIsExact(t *testing.T) {
+	t.Parallel()
+
+	a := MustParseDecimal("0.1")
+	b := MustParseDecimal("0.2")
+
+	if sum := a.Add(b); sum.Cmp(MustParseDecimal("0.3")) != 0 {
+		t.Errorf("Expected 0.1 + 0.2 to equal 0.3, got %v", sum)
+	}
+	if diff := a.Sub(b); diff.String() != "-0.1" {
+		t.Errorf("Expected -0.1, got %v", diff)
+	}
+	if vat := MustParseDecimal("99.90").Mul(MustParseDecimal("1.25")); vat.String() != "124.8750" || vat.StringFixed(2) != "124.88" {
+		t.Errorf("Unexpected product %v", vat)
+	}
+	if neg := NewDecimal(-125, 2).Round(1); neg.String() != "-1.3" {
+		t.Errorf("Expected halves to round away from zero, got %v", neg)
+	}
+	if fixed := NewDecimal(99, 0).StringFixed(2); fixed != "99.00" {
+		t.Errorf("Expected 99.00, got %v", fixed)
+	}
+	if !(PublitDecimal{}).IsZero() || (PublitDecimal{}).String() != "0" {
+		t.Error("Expected the zero value to be 0")
+	}
+	if NewDecimal(5, -2).String() != "500" {
+		t.Errorf("Expected 500, got %v", NewDecimal(5, -2))
+	}
+}
+
+func TestPublitDecimalRoundTripsJSON(t *testing.T) {
+	t.Parallel()
+
+	type price struct {
+		Amount PublitDecimal `json:"amount"`
+	}
+
+	for _, in := range []string{`{"amount":"149.00"}`, `{"amount":149.00}`} {
+		p := price{}
+		if err := json.Unmarshal([]byte(in), &p); err != nil {
+			t.Fatalf("Received an error but did not expect one: %v", err)
+		}
+
+		b, _ := json.Marshal(p)
+		if string(b) != `{"amount":"149.00"}` {
+			t.Errorf("Unexpected JSON of %s. Got %s", in, b)
+		}
+	}
+
+	if err := json.Unmarshal([]byte(`{"amount":"free"}`), &price{}); !errors.Is(err, ErrInvalidDecimal) {
+		t.Errorf("Expected ErrInvalidDecimal, got %v", err)
+	}
+
+	p := price{}
+	json.Unmarshal([]byte(`{"amount":"149.00"}`), &p)
+	if err := json.Unmarshal([]byte(`{"amount":null}`), &p); err != nil {
+		t.Fatalf("Received an error but did not expect one: %v", err)
+	}
+	if b, _ := json.Marshal(p); string(b) != `{"amount":"149.00"}` {
+		t.Errorf("Expected null to leave the decimal unchanged. Got %s", b)
+	}
+}
+
 func TestCanGetErrorFromAPIErrorResponse(t *testing.T) {
 	t.Parallel()
 
@@ -375,6 +1121,138 @@ func TestCanGetErrorFromAPIErrorResponse(t *testing.T) {
 	}
 }
 
+func TestAPIErrorResponseIsError(t *testing.T) {
+	t.Parallel()
+
+	var err error = &APIErrorResponse{
+		Code: http.StatusUnprocessableEntity,
+		Type: "ValidationError",
+		Errors: []*APIError{
+			nil,
+			{Info: "Title is required", Type: "Required"},
+			{Info: "ISBN is invalid", Type: "Format"},
+			{Info: "Price is required", Type: "Required"},
+		},
+		CombinedInfo: "Validation failed",
+	}
+
+	expected := `Code: "422", Type: "ValidationError", Combined info: "Validation failed"`
+	if err.Error() != expected {
+		t.Errorf("Expected %q, got %q", expected, err.Error())
+	}
+
+	var e *APIErrorResponse
+	if !errors.As(err, &e) {
+		t.Fatal("Expected error to be an *APIErrorResponse but was not.")
+	}
+	if !e.IsValidation() {
+		t.Error("Expected a validation error.")
+	}
+	if first := e.FirstError(); first == nil || first.Info != "Title is required" {
+		t.Errorf("Unexpected first error %+v", first)
+	}
+
+	byType := e.ErrorsByType()
+	if len(byType) != 2 || len(byType["Required"]) != 2 || len(byType["Format"]) != 1 {
+		t.Errorf("Unexpected errors by type %v", byType)
+	}
+
+	if (&APIErrorResponse{Code: http.StatusNotFound}).IsValidation() {
+		t.Error("Did not expect a validation error.")
+	}
+	if (&APIErrorResponse{}).FirstError() != nil {
+		t.Error("Did not expect a first error.")
+	}
+}
+
+func TestCanGetFieldErrorsFromAPIErrorResponse(t *testing.T) {
+	t.Parallel()
+
+	body := `{
+		"Code": 422,
+		"Type": "ValidationError",
+		"CombinedInfo": "Validation failed",
+		"errors": [
+			{"Info": "Title is required", "Type": "ValidationError", "Field": "title", "Rule": "required"},
+			{"Info": "Price must be at least 0", "Type": "ValidationError", "Field": "price", "Rule": "min:0", "Value": -1},
+			{"Info": "Title is too short", "Type": "ValidationError", "Field": "title", "Rule": "min_length:2", "Value": "a"},
+			{"Info": "Something else", "Type": "ValidationError"}
+		]
+	}`
+
+	e := &APIErrorResponse{}
+	if err := json.Unmarshal([]byte(body), e); err != nil {
+		t.Fatalf("Received an error but did not expect one: %v", err)
+	}
+
+	fields := e.FieldErrors()
+	if len(fields) != 2 || len(fields["title"]) != 2 || len(fields["price"]) != 1 {
+		t.Fatalf("Unexpected field errors %v", fields)
+	}
+
+	price := fields["price"][0]
+	if price.Rule != "min:0" || price.Value != float64(-1) || price.Info != "Price must be at least 0" {
+		t.Errorf("Unexpected field error %+v", price)
+	}
+	if fields["title"][1].Value != "a" {
+		t.Errorf("Unexpected field error %+v", fields["title"][1])
+	}
+}
+
+func TestCanCreateCategoryErrors(t *testing.T) {
+	t.Parallel()
+
+	info := &APIErrorResponse{Code: http.StatusConflict, Type: "Conflict", CombinedInfo: "Already exists"}
+
+	tests := map[string]struct {
+		code     int
+		response *APIErrorResponse
+		category error
+	}{
+		"Status code":              {http.StatusNotFound, nil, ErrNotFound},
+		"Code of response":         {0, info, ErrConflict},
+		"Status code over code":    {http.StatusUnprocessableEntity, info, ErrValidation},
+		"Type of response":         {http.StatusInternalServerError, &APIErrorResponse{Type: "Rate_Limit_Exceeded"}, ErrRateLimited},
+		"Validation type":          {0, &APIErrorResponse{Type: "ValidationError"}, ErrValidation},
+		"Not found type":           {0, &APIErrorResponse{Type: "Not found"}, ErrNotFound},
+		"Too many requests status": {http.StatusTooManyRequests, nil, ErrRateLimited},
+	}
+
+	categories := []error{ErrUnauthorized, ErrNotFound, ErrValidation, ErrRateLimited, ErrConflict}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := NewCategoryError(tt.code, tt.response)
+			for _, category := range categories {
+				if errors.Is(err, category) != (category == tt.category) {
+					t.Errorf("Unexpected category match of %v, got %v", category, err)
+				}
+			}
+
+			var e *APIErrorResponse
+			if errors.As(err, &e) != (tt.response != nil) || e != tt.response {
+				t.Errorf("Expected the error to unwrap to the API error response, got %v", err)
+			}
+		})
+	}
+
+	var conflictErr *ConflictError
+	if err := NewCategoryError(0, info); !errors.As(err, &conflictErr) || conflictErr.Error() != info.Error() {
+		t.Errorf("Expected a ConflictError with the message of the response, got %v", err)
+	}
+	if err := NewCategoryError(http.StatusNotFound, nil); err.Error() != ErrNotFound.Error() {
+		t.Errorf("Expected the message of the category, got %q", err.Error())
+	}
+
+	other := &APIErrorResponse{Code: http.StatusInternalServerError, Type: "Internal"}
+	if err := NewCategoryError(0, other); err != other {
+		t.Errorf("Expected the API error response, got %v", err)
+	}
+	if err := NewCategoryError(http.StatusInternalServerError, nil); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+}
+
 func TestAPIErrorResponseHasInformation(t *testing.T) {
 	t.Parallel()
 
@@ -427,6 +1305,295 @@ func TestAPIErrorResponseHasInformation(t *testing.T) {
 	)
 }
 
+func TestCanSetFilterExpressionQuery(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		expr     Expr
+		expected map[string]string
+	}{
+		"OR on one attribute AND another": {
+			And(
+				Or(Cond("state", OPERATOR_EQUAL, "published"), Cond("state", OPERATOR_EQUAL, "draft")),
+				Cond("price", OPERATOR_GREATER, "10"),
+			),
+			map[string]string{
+				"state":                     "published,draft",
+				"state" + QUERY_ARGS_SUFFIX: "EQUAL;AND,EQUAL;OR",
+				"price":                     "10",
+				"price" + QUERY_ARGS_SUFFIX: "GREATER;AND",
+			},
+		},
+		"Nested ANDs are flattened": {
+			And(Cond("price", OPERATOR_GREATER_EQUAL, "10"), And(Cond("price", OPERATOR_LESS, "20"))),
+			map[string]string{
+				"price":                     "10,20",
+				"price" + QUERY_ARGS_SUFFIX: "GREATER_EQUAL;AND,LESS;AND",
+			},
+		},
+		"Negations are pushed down": {
+			Not(Or(Cond("state", OPERATOR_EQUAL, "deleted"), Cond("price", OPERATOR_LESS, "10"))),
+			map[string]string{
+				"state":                     "deleted",
+				"state" + QUERY_ARGS_SUFFIX: "NOT_EQUAL;AND",
+				"price":                     "10",
+				"price" + QUERY_ARGS_SUFFIX: "GREATER_EQUAL;AND",
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			f, err := QueryExpr(tt.expr)
+			if err != nil {
+				t.Fatalf("Received an error but did not expect one: %v", err)
+			}
+
+			q := url.Values{}
+			f(q)
+
+			for k, v := range tt.expected {
+				assertQueryStringEqual(k, v, q, t)
+			}
+			if len(q) != len(tt.expected) {
+				t.Errorf("Unexpected query. Got %v", q)
+			}
+		})
+	}
+}
+
+func TestFilterExpressionFailsIfNotSupportedByQuerySyntax(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]Expr{
+		"OR of different attributes": Or(Cond("state", OPERATOR_EQUAL, "draft"), Cond("price", OPERATOR_LESS, "10")),
+		"OR combined with other filters on the attribute": And(
+			Or(Cond("state", OPERATOR_EQUAL, "published"), Cond("state", OPERATOR_EQUAL, "draft")),
+			Cond("state", OPERATOR_NOT_EQUAL, "deleted"),
+		),
+		"AND below OR": Or(
+			And(Cond("price", OPERATOR_GREATER, "10"), Cond("price", OPERATOR_LESS, "20")),
+			Cond("price", OPERATOR_EQUAL, "0"),
+		),
+		"Negated substring match": Not(Cond("title", OPERATOR_CONTAINS, "harry")),
+		"Empty group":             And(Or()),
+	}
+
+	for name, expr := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := QueryExpr(expr); !errors.Is(err, ErrUnsupportedExpression) {
+				t.Errorf("Expected ErrUnsupportedExpression, got %v", err)
+			}
+		})
+	}
+}
+
+func TestFilterExpressionCompilesToSeveralQueries(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		expr     Expr
+		expected []string
+	}{
+		"OR across attributes AND another": {
+			And(
+				Or(Cond("state", OPERATOR_EQUAL, "draft"), Cond("price", OPERATOR_EQUAL, "0")),
+				Cond("title", OPERATOR_BEGINS_WITH, "harry"),
+			),
+			[]string{
+				"state=draft&state_args=EQUAL%3BAND&title=harry&title_args=BEGINS_WITH%3BAND",
+				"price=0&price_args=EQUAL%3BAND&title=harry&title_args=BEGINS_WITH%3BAND",
+			},
+		},
+		"OR on one attribute kept in one query": {
+			Or(
+				And(Or(Cond("state", OPERATOR_EQUAL, "published"), Cond("state", OPERATOR_EQUAL, "draft")), Cond("price", OPERATOR_LESS, "10")),
+				Cond("price", OPERATOR_EQUAL, "0"),
+			),
+			[]string{
+				"price=10&price_args=LESS%3BAND&state=published%2Cdraft&state_args=EQUAL%3BAND%2CEQUAL%3BOR",
+				"price=0&price_args=EQUAL%3BAND",
+			},
+		},
+		"OR combined with other filters on the attribute": {
+			And(
+				Or(Cond("state", OPERATOR_EQUAL, "published"), Cond("state", OPERATOR_EQUAL, "draft")),
+				Cond("state", OPERATOR_NOT_EQUAL, "deleted"),
+			),
+			[]string{
+				"state=published%2Cdeleted&state_args=EQUAL%3BAND%2CNOT_EQUAL%3BAND",
+				"state=draft%2Cdeleted&state_args=EQUAL%3BAND%2CNOT_EQUAL%3BAND",
+			},
+		},
+		"Single query": {
+			Cond("price", OPERATOR_EQUAL, "0"),
+			[]string{"price=0&price_args=EQUAL%3BAND"},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			funcs, err := QueryExprs(tt.expr)
+			if err != nil {
+				t.Fatalf("Received an error but did not expect one: %v", err)
+			}
+
+			got := []string{}
+			for _, f := range funcs {
+				q := url.Values{}
+				f(q)
+				got = append(got, q.Encode())
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Unexpected queries.\nExpected %v\nGot      %v", tt.expected, got)
+			}
+		})
+	}
+
+	terms := []Expr{}
+	for i := 0; i < 6; i++ {
+		terms = append(terms, Or(Cond("a", OPERATOR_EQUAL, "1"), Cond("b", OPERATOR_EQUAL, "2")))
+	}
+	if _, err := CompileExprQueries(And(terms...)); !errors.Is(err, ErrUnsupportedExpression) {
+		t.Errorf("Expected ErrUnsupportedExpression for too many queries, got %v", err)
+	}
+	queries, err := CompileExprQueries(Cond("title", OPERATOR_EQUAL, "a,b"))
+	if err != nil || len(queries) != 1 || queries[0][0].Value != `a\,b` {
+		t.Errorf("Expected value with comma to be escaped, got %+v, %v", queries, err)
+	}
+}
+
+func TestQueryBuilderBuildsQueryParams(t *testing.T) {
+	t.Parallel()
+
+	params := NewQuery().
+		Where("state", OPERATOR_EQUAL, "published").
+		Where("price", OPERATOR_GREATER, "10").
+		Where("price", OPERATOR_LESS, "20").
+		With("authors").
+		With("prices").
+		Scope(Scope{Scope: "active"}).
+		Auxiliary("aux1").
+		OrderBy("created_at", ORDER_DIR_ASC).
+		OrderBy("title", ORDER_DIR_DESC).
+		GroupBy("state").
+		Limit(50, 0).
+		Param(QueryAttrRange("created_at", "2017-01-01", "2017-12-31")).
+		Build()
+
+	q := url.Values{}
+	for _, f := range params {
+		f(q)
+	}
+
+	expected := map[string]string{
+		"state":                          "published",
+		"state" + QUERY_ARGS_SUFFIX:      "EQUAL;AND",
+		"price":                          "10,20",
+		"price" + QUERY_ARGS_SUFFIX:      "GREATER;AND,LESS;AND",
+		"created_at":                     "2017-01-01,2017-12-31",
+		"created_at" + QUERY_ARGS_SUFFIX: "BETWEEN",
+		QUERY_KEY_WITH:                   "authors,prices",
+		QUERY_KEY_SCOPE:                  "active",
+		QUERY_KEY_AUX:                    "aux1",
+		QUERY_KEY_ORDER:                  "created_at,title",
+		QUERY_KEY_ORDER_DIR:              "DESC",
+		QUERY_KEY_GROUP_BY:               "state",
+		QUERY_KEY_LIMIT:                  "0,50",
+	}
+
+	for k, v := range expected {
+		assertQueryStringEqual(k, v, q, t)
+	}
+	if len(q) != len(expected) {
+		t.Errorf("Unexpected query. Got %v", q)
+	}
+}
+
+func TestEmptyQueryBuilderBuildsNoQueryParams(t *testing.T) {
+	t.Parallel()
+
+	if q := NewQuery().Values(); len(q) != 0 {
+		t.Errorf("Expected empty query, got %v", q)
+	}
+}
+
+func TestValidateQueryAcceptsValidQuery(t *testing.T) {
+	t.Parallel()
+
+	b := NewQuery().
+		Where("state", OPERATOR_EQUAL, "published").
+		OrderBy("created_at", ORDER_DIR_DESC).
+		Limit(50, 0).
+		Has("authors").
+		Has("prices")
+
+	if err := b.Validate(); err != nil {
+		t.Errorf("Received an error but did not expect one: %v", err)
+	}
+}
+
+func TestValidateQueryDetectsProblems(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		query url.Values
+		key   string
+	}{
+		"Duplicate key":             {url.Values{"state": {"a", "b"}}, "state"},
+		"Malformed limit":           {url.Values{QUERY_KEY_LIMIT: {"50"}}, QUERY_KEY_LIMIT},
+		"Negative offset":           {url.Values{QUERY_KEY_LIMIT: {"-1,50"}}, QUERY_KEY_LIMIT},
+		"Zero limit":                {url.Values{QUERY_KEY_LIMIT: {"0,0"}}, QUERY_KEY_LIMIT},
+		"Order dir without order":   {url.Values{QUERY_KEY_ORDER_DIR: {"ASC"}}, QUERY_KEY_ORDER_DIR},
+		"Unknown order dir":         {url.Values{QUERY_KEY_ORDER: {"title"}, QUERY_KEY_ORDER_DIR: {"UP"}}, QUERY_KEY_ORDER_DIR},
+		"Unknown operator":          {url.Values{"state": {"a"}, "state_args": {"EQUALS;AND"}}, "state_args"},
+		"Unknown combinator":        {url.Values{"state": {"a"}, "state_args": {"EQUAL;XOR"}}, "state_args"},
+		"Args without an attribute": {url.Values{"state_args": {"EQUAL"}}, "state_args"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := ValidateQuery(tt.query)
+			if !errors.Is(err, ErrInvalidQuery) {
+				t.Fatalf("Expected ErrInvalidQuery, got %v", err)
+			}
+
+			var errs QueryErrors
+			if !errors.As(err, &errs) || len(errs) != 1 || errs[0].Key != tt.key {
+				t.Errorf("Expected a single problem with %q, got %v", tt.key, err)
+			}
+		})
+	}
+}
+
+func TestCanDecodeResponseEnvelopes(t *testing.T) {
+	t.Parallel()
+
+	type book struct {
+		ID    int    `json:"id"`
+		Title string `json:"title"`
+	}
+
+	list := ListResponse[book]{}
+	if err := json.Unmarshal([]byte(`{"data":[{"id":1,"title":"a"},{"id":2,"title":"b"}],"count":5}`), &list); err != nil {
+		t.Fatalf("Received an error but did not expect one: %v", err)
+	}
+	if len(list.Data) != 2 || list.Count != 5 || list.Data[1].Title != "b" {
+		t.Errorf("Unexpected list response %+v", list)
+	}
+	if !list.HasMore() || list.NextOffset() != 2 {
+		t.Error("Expected list to have more records from offset 2.")
+	}
+
+	show := ShowResponse[book]{}
+	if err := json.Unmarshal([]byte(`{"data":{"id":1,"title":"a"}}`), &show); err != nil {
+		t.Fatalf("Received an error but did not expect one: %v", err)
+	}
+	if show.Data.ID != 1 || show.Data.Title != "a" {
+		t.Errorf("Unexpected show response %+v", show)
+	}
+}
+
 func assertQueryStringEqual(valueName, expected string, q url.Values, t *testing.T) {
 	if q.Get(valueName) != expected {
 		t.Errorf(`%v did not match expected. Got "%v", expected "%v"`, valueName, q.Get(valueName), expected)
@@ -455,6 +1622,18 @@ func ExampleQueryAttr() {
 	// Output: param filter: value, args: EQUAL;AND
 }
 
+func ExampleQueryBuilder() {
+	q := NewQuery().
+		Where("state", OPERATOR_EQUAL, "published").
+		With("authors").
+		OrderBy("created_at", ORDER_DIR_DESC).
+		Limit(50, 0).
+		Values()
+
+	fmt.Println(q.Encode())
+	// Output: limit=0%2C50&order_by=created_at&order_dir=DESC&state=published&state_args=EQUAL%3BAND&with=authors
+}
+
 func ExampleQueryAuxiliary() {
 	auxiliaryAttributes := []string{"aux1", "aux2"}
 	f := QueryAuxiliary(auxiliaryAttributes...)
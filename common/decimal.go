@@ -0,0 +1,207 @@
+// Copyright 2018 Publit Sweden AB. All rights reserved.
+
+package common
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// ErrInvalidDecimal is returned when a string is not a decimal number.
+var ErrInvalidDecimal = errors.New("Invalid decimal")
+
+// PublitDecimal is an exact decimal number, such as the prices of the Publit APIs which are sent as decimal strings.
+// Arithmetic is exact, unlike float64. The zero value is 0. PublitDecimals are immutable.
+type PublitDecimal struct {
+	// The value is unscaled * 10^-scale.
+	unscaled *big.Int
+	scale    int
+}
+
+// NewDecimal returns the PublitDecimal unscaled * 10^-scale, eg. NewDecimal(9950, 2) is 99.50.
+func NewDecimal(unscaled int64, scale int) PublitDecimal {
+	if scale < 0 {
+		return PublitDecimal{unscaled: new(big.Int).Mul(big.NewInt(unscaled), pow10(-scale))}
+	}
+	return PublitDecimal{unscaled: big.NewInt(unscaled), scale: scale}
+}
+
+// ParseDecimal parses a decimal string like "-99.50". Returns ErrInvalidDecimal for other strings.
+func ParseDecimal(s string) (PublitDecimal, error) {
+	digits := strings.TrimPrefix(strings.TrimPrefix(s, "-"), "+")
+	intPart, fracPart := digits, ""
+	if i := strings.IndexByte(digits, '.'); i >= 0 {
+		intPart, fracPart = digits[:i], digits[i+1:]
+	}
+
+	if intPart == "" && fracPart == "" || !isDigits(intPart) || !isDigits(fracPart) {
+		return PublitDecimal{}, fmt.Errorf("%w %q", ErrInvalidDecimal, s)
+	}
+
+	unscaled, _ := new(big.Int).SetString("0"+intPart+fracPart, 10)
+	if strings.HasPrefix(s, "-") {
+		unscaled.Neg(unscaled)
+	}
+
+	return PublitDecimal{unscaled: unscaled, scale: len(fracPart)}, nil
+}
+
+// MustParseDecimal is like ParseDecimal but panics on invalid strings. Use for constants.
+func MustParseDecimal(s string) PublitDecimal {
+	d, err := ParseDecimal(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// isDigits reports whether s only contains the digits 0-9.
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// pow10 returns 10^n.
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// int returns the unscaled value, treating the zero value as 0.
+func (d PublitDecimal) int() *big.Int {
+	if d.unscaled == nil {
+		return new(big.Int)
+	}
+	return d.unscaled
+}
+
+// rescale returns the unscaled value at a scale not less than the scale of d.
+func (d PublitDecimal) rescale(scale int) *big.Int {
+	return new(big.Int).Mul(d.int(), pow10(scale-d.scale))
+}
+
+// align returns the unscaled values of d and e at their common scale.
+func (d PublitDecimal) align(e PublitDecimal) (*big.Int, *big.Int, int) {
+	scale := d.scale
+	if e.scale > scale {
+		scale = e.scale
+	}
+	return d.rescale(scale), e.rescale(scale), scale
+}
+
+// Add returns d + e.
+func (d PublitDecimal) Add(e PublitDecimal) PublitDecimal {
+	a, b, scale := d.align(e)
+	return PublitDecimal{unscaled: a.Add(a, b), scale: scale}
+}
+
+// Sub returns d - e.
+func (d PublitDecimal) Sub(e PublitDecimal) PublitDecimal {
+	a, b, scale := d.align(e)
+	return PublitDecimal{unscaled: a.Sub(a, b), scale: scale}
+}
+
+// Mul returns d * e.
+func (d PublitDecimal) Mul(e PublitDecimal) PublitDecimal {
+	return PublitDecimal{unscaled: new(big.Int).Mul(d.int(), e.int()), scale: d.scale + e.scale}
+}
+
+// Neg returns -d.
+func (d PublitDecimal) Neg() PublitDecimal {
+	return PublitDecimal{unscaled: new(big.Int).Neg(d.int()), scale: d.scale}
+}
+
+// Cmp compares d and e and returns -1 if d < e, 0 if d == e and +1 if d > e.
+func (d PublitDecimal) Cmp(e PublitDecimal) int {
+	a, b, _ := d.align(e)
+	return a.Cmp(b)
+}
+
+// Sign returns -1 if d < 0, 0 if d == 0 and +1 if d > 0.
+func (d PublitDecimal) Sign() int {
+	return d.int().Sign()
+}
+
+// IsZero reports whether d is 0.
+func (d PublitDecimal) IsZero() bool {
+	return d.Sign() == 0
+}
+
+// Round rounds d to the number of decimal places, with halves rounded away from zero.
+func (d PublitDecimal) Round(places int) PublitDecimal {
+	if places < 0 {
+		places = 0
+	}
+	if d.scale <= places {
+		return PublitDecimal{unscaled: d.rescale(places), scale: places}
+	}
+
+	divisor := pow10(d.scale - places)
+	q, r := new(big.Int).QuoRem(d.int(), divisor, new(big.Int))
+
+	// Round away from zero if the remainder is at least half of the divisor.
+	if r.Abs(r).Mul(r, big.NewInt(2)).Cmp(divisor) >= 0 {
+		q.Add(q, big.NewInt(int64(d.Sign())))
+	}
+
+	return PublitDecimal{unscaled: q, scale: places}
+}
+
+// Float64 returns the nearest float64 of d. Use for display only, as precision may be lost.
+func (d PublitDecimal) Float64() float64 {
+	f, _ := new(big.Rat).SetFrac(d.int(), pow10(d.scale)).Float64()
+	return f
+}
+
+// String formats d with all its decimal places, eg. "99.50".
+func (d PublitDecimal) String() string {
+	s := new(big.Int).Abs(d.int()).String()
+	if d.scale > 0 {
+		if len(s) <= d.scale {
+			s = strings.Repeat("0", d.scale-len(s)+1) + s
+		}
+		s = s[:len(s)-d.scale] + "." + s[len(s)-d.scale:]
+	}
+
+	if d.Sign() < 0 {
+		return "-" + s
+	}
+	return s
+}
+
+// StringFixed formats d rounded to exactly the number of decimal places, eg. "99.00" for two places.
+func (d PublitDecimal) StringFixed(places int) string {
+	return d.Round(places).String()
+}
+
+// MarshalJSON encodes d as a JSON string, like the prices of the Publit APIs.
+func (d PublitDecimal) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON decodes d from a JSON string or number. Like encoding/json does for other types, null leaves d unchanged.
+func (d *PublitDecimal) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+	if strings.HasPrefix(s, `"`) {
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+	}
+
+	v, err := ParseDecimal(s)
+	if err != nil {
+		return err
+	}
+
+	*d = v
+	return nil
+}
@@ -0,0 +1,153 @@
+// Copyright 2018 Publit Sweden AB. All rights reserved.
+
+package common
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidEnum is returned when an Operator, Combinator or OrderDir is out of range or can not be parsed.
+var ErrInvalidEnum = errors.New("Invalid enum value")
+
+// enumString returns the string of the 1-based enum value, or ErrInvalidEnum if out of range.
+func enumString(strs []string, name string, v int) (string, error) {
+	if v < 1 || v > len(strs) {
+		return "", fmt.Errorf("%w. %v %d out of range", ErrInvalidEnum, name, v)
+	}
+	return strs[v-1], nil
+}
+
+// parseEnum returns the 1-based enum value of the string, compared case-insensitively, or ErrInvalidEnum if unknown.
+func parseEnum(strs []string, name string, s string) (int, error) {
+	for i, v := range strs {
+		if strings.EqualFold(v, strings.TrimSpace(s)) {
+			return i + 1, nil
+		}
+	}
+	return 0, fmt.Errorf("%w. Unknown %v %q", ErrInvalidEnum, name, s)
+}
+
+// TryAsString returns the Operator as string like AsString, but returns ErrInvalidEnum instead of panicking if out of
+// range.
+func (o Operator) TryAsString() (string, error) {
+	return enumString(operators, "Operator", int(o))
+}
+
+// TryAsString returns the Combinator as string like AsString, but returns ErrInvalidEnum instead of panicking if out
+// of range.
+func (c Combinator) TryAsString() (string, error) {
+	return enumString(combinators, "Combinator", int(c))
+}
+
+// TryAsString returns the OrderDir as string like AsString, but returns ErrInvalidEnum instead of panicking if out of
+// range.
+func (o OrderDir) TryAsString() (string, error) {
+	return enumString(orderDirections, "OrderDir", int(o))
+}
+
+// ParseOperator returns the Operator of a string like "GREATER_EQUAL", in any case.
+func ParseOperator(s string) (Operator, error) {
+	v, err := parseEnum(operators, "Operator", s)
+	return Operator(v), err
+}
+
+// ParseCombinator returns the Combinator of a string like "AND", in any case.
+func ParseCombinator(s string) (Combinator, error) {
+	v, err := parseEnum(combinators, "Combinator", s)
+	return Combinator(v), err
+}
+
+// ParseOrderDir returns the OrderDir of a string like "DESC", in any case.
+func ParseOrderDir(s string) (OrderDir, error) {
+	v, err := parseEnum(orderDirections, "OrderDir", s)
+	return OrderDir(v), err
+}
+
+// String returns the Operator as string, or "Operator(n)" if out of range.
+func (o Operator) String() string {
+	return enumDisplay(operators, "Operator", int(o))
+}
+
+// String returns the Combinator as string, or "Combinator(n)" if out of range.
+func (c Combinator) String() string {
+	return enumDisplay(combinators, "Combinator", int(c))
+}
+
+// String returns the OrderDir as string, or "OrderDir(n)" if out of range.
+func (o OrderDir) String() string {
+	return enumDisplay(orderDirections, "OrderDir", int(o))
+}
+
+// MarshalJSON encodes the Operator as its string, or null if zero.
+func (o Operator) MarshalJSON() ([]byte, error) {
+	return marshalEnum(operators, "Operator", int(o))
+}
+
+// UnmarshalJSON decodes the Operator from its string, see ParseOperator. Null leaves the Operator unchanged.
+func (o *Operator) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum(operators, "Operator", data, (*int)(o))
+}
+
+// MarshalJSON encodes the Combinator as its string, or null if zero.
+func (c Combinator) MarshalJSON() ([]byte, error) {
+	return marshalEnum(combinators, "Combinator", int(c))
+}
+
+// UnmarshalJSON decodes the Combinator from its string, see ParseCombinator. Null leaves the Combinator unchanged.
+func (c *Combinator) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum(combinators, "Combinator", data, (*int)(c))
+}
+
+// MarshalJSON encodes the OrderDir as its string, or null if zero.
+func (o OrderDir) MarshalJSON() ([]byte, error) {
+	return marshalEnum(orderDirections, "OrderDir", int(o))
+}
+
+// UnmarshalJSON decodes the OrderDir from its string, see ParseOrderDir. Null leaves the OrderDir unchanged.
+func (o *OrderDir) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum(orderDirections, "OrderDir", data, (*int)(o))
+}
+
+// enumDisplay returns the string of the enum value, or "name(v)" if out of range.
+func enumDisplay(strs []string, name string, v int) string {
+	s, err := enumString(strs, name, v)
+	if err != nil {
+		return fmt.Sprintf("%v(%d)", name, v)
+	}
+	return s
+}
+
+// marshalEnum encodes the enum value as its string, or null if zero. Returns ErrInvalidEnum if out of range.
+func marshalEnum(strs []string, name string, v int) ([]byte, error) {
+	if v == 0 {
+		return []byte("null"), nil
+	}
+
+	s, err := enumString(strs, name, v)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(s)
+}
+
+// unmarshalEnum decodes the enum value from its string into v. Null leaves v unchanged.
+func unmarshalEnum(strs []string, name string, data []byte, v *int) error {
+	var s *string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("%w. %v must be a string, got %s", ErrInvalidEnum, name, data)
+	}
+	if s == nil {
+		return nil
+	}
+
+	parsed, err := parseEnum(strs, name, *s)
+	if err != nil {
+		return err
+	}
+
+	*v = parsed
+	return nil
+}
@@ -0,0 +1,209 @@
+// Copyright 2018 Publit Sweden AB. All rights reserved.
+
+package common
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Error categories of responses from the Publit APIs.
+// The category errors, such as NotFoundError, match these with errors.Is.
+var (
+	ErrUnauthorized = errors.New("Unauthorized")
+	ErrNotFound     = errors.New("Not found")
+	ErrValidation   = errors.New("Validation failed")
+	ErrRateLimited  = errors.New("Rate limited")
+	ErrConflict     = errors.New("Conflict")
+)
+
+// Error returns the error message of the APIErrorResponse, so *APIErrorResponse can be used as error.
+func (e *APIErrorResponse) Error() string {
+	return fmt.Sprintf(`Code: "%v", Type: "%v", Combined info: "%v"`, e.Code, e.Type, e.CombinedInfo)
+}
+
+// IsValidation reports whether the APIErrorResponse is a validation failure, ie. has code 400 or 422.
+func (e *APIErrorResponse) IsValidation() bool {
+	return e.Code == http.StatusBadRequest || e.Code == http.StatusUnprocessableEntity
+}
+
+// FirstError returns the first of the Errors, or nil if there are none.
+func (e *APIErrorResponse) FirstError() *APIError {
+	for _, v := range e.Errors {
+		if v != nil {
+			return v
+		}
+	}
+	return nil
+}
+
+// ErrorsByType returns the Errors grouped by their Type.
+func (e *APIErrorResponse) ErrorsByType() map[string][]*APIError {
+	byType := map[string][]*APIError{}
+	for _, v := range e.Errors {
+		if v != nil {
+			byType[v.Type] = append(byType[v.Type], v)
+		}
+	}
+	return byType
+}
+
+// FieldErrors returns the Errors that concern an attribute, grouped by their Field.
+func (e *APIErrorResponse) FieldErrors() map[string][]*APIError {
+	byField := map[string][]*APIError{}
+	for _, v := range e.Errors {
+		if v != nil && v.Field != "" {
+			byField[v.Field] = append(byField[v.Field], v)
+		}
+	}
+	return byField
+}
+
+// NotFoundError is the category error of 404 Not Found responses. It matches ErrNotFound with errors.Is.
+type NotFoundError struct {
+	// Response is the error information given by the Publit API. Nil if no information was given.
+	Response *APIErrorResponse
+}
+
+// ValidationError is the category error of 400 Bad Request and 422 Unprocessable Entity responses.
+// It matches ErrValidation with errors.Is.
+type ValidationError struct {
+	// Response is the error information given by the Publit API. Nil if no information was given.
+	Response *APIErrorResponse
+}
+
+// ConflictError is the category error of 409 Conflict responses. It matches ErrConflict with errors.Is.
+type ConflictError struct {
+	// Response is the error information given by the Publit API. Nil if no information was given.
+	Response *APIErrorResponse
+}
+
+// RateLimitError is the category error of 429 Too Many Requests responses. It matches ErrRateLimited with errors.Is.
+type RateLimitError struct {
+	// Response is the error information given by the Publit API. Nil if no information was given.
+	Response *APIErrorResponse
+	// RetryAfter is the time the server asked the client to wait before retrying. Zero if not known.
+	RetryAfter time.Duration
+}
+
+// NewCategoryError returns the category error of a response with the status code and error information, either of
+// which may be missing. The category is given by the status code, or the code of the APIErrorResponse if zero, and
+// otherwise by the Type of the APIErrorResponse.
+// Returns the APIErrorResponse if it matches no category, and nil if it is nil as well.
+func NewCategoryError(code int, e *APIErrorResponse) error {
+	if code == 0 && e != nil {
+		code = e.Code
+	}
+
+	switch code {
+	case http.StatusNotFound:
+		return &NotFoundError{Response: e}
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return &ValidationError{Response: e}
+	case http.StatusConflict:
+		return &ConflictError{Response: e}
+	case http.StatusTooManyRequests:
+		return &RateLimitError{Response: e}
+	}
+
+	if e == nil {
+		return nil
+	}
+
+	t := strings.ToLower(strings.NewReplacer(" ", "", "_", "", "-", "").Replace(e.Type))
+	switch {
+	case strings.Contains(t, "notfound"):
+		return &NotFoundError{Response: e}
+	case strings.Contains(t, "validation"):
+		return &ValidationError{Response: e}
+	case strings.Contains(t, "conflict"):
+		return &ConflictError{Response: e}
+	case strings.Contains(t, "ratelimit"), strings.Contains(t, "toomanyrequests"):
+		return &RateLimitError{Response: e}
+	}
+
+	return e
+}
+
+// Error returns the error message of the NotFoundError.
+func (e *NotFoundError) Error() string {
+	return categoryMessage(ErrNotFound, e.Response)
+}
+
+// Is reports if target is ErrNotFound.
+func (e *NotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// Unwrap returns the APIErrorResponse, if any.
+func (e *NotFoundError) Unwrap() error {
+	return unwrapResponse(e.Response)
+}
+
+// Error returns the error message of the ValidationError.
+func (e *ValidationError) Error() string {
+	return categoryMessage(ErrValidation, e.Response)
+}
+
+// Is reports if target is ErrValidation.
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// Unwrap returns the APIErrorResponse, if any.
+func (e *ValidationError) Unwrap() error {
+	return unwrapResponse(e.Response)
+}
+
+// Error returns the error message of the ConflictError.
+func (e *ConflictError) Error() string {
+	return categoryMessage(ErrConflict, e.Response)
+}
+
+// Is reports if target is ErrConflict.
+func (e *ConflictError) Is(target error) bool {
+	return target == ErrConflict
+}
+
+// Unwrap returns the APIErrorResponse, if any.
+func (e *ConflictError) Unwrap() error {
+	return unwrapResponse(e.Response)
+}
+
+// Error returns the error message of the RateLimitError.
+func (e *RateLimitError) Error() string {
+	msg := categoryMessage(ErrRateLimited, e.Response)
+	if e.RetryAfter > 0 {
+		msg = fmt.Sprintf("%v. Retry after %v", msg, e.RetryAfter)
+	}
+	return msg
+}
+
+// Is reports if target is ErrRateLimited.
+func (e *RateLimitError) Is(target error) bool {
+	return target == ErrRateLimited
+}
+
+// Unwrap returns the APIErrorResponse, if any.
+func (e *RateLimitError) Unwrap() error {
+	return unwrapResponse(e.Response)
+}
+
+// categoryMessage returns the message of the APIErrorResponse, or of the category if there is none.
+func categoryMessage(category error, e *APIErrorResponse) string {
+	if e == nil {
+		return category.Error()
+	}
+	return e.Error()
+}
+
+// unwrapResponse returns the APIErrorResponse as error, or nil if it is nil.
+func unwrapResponse(e *APIErrorResponse) error {
+	if e == nil {
+		return nil
+	}
+	return e
+}
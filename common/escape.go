@@ -0,0 +1,63 @@
+// Copyright 2018 Publit Sweden AB. All rights reserved.
+
+package common
+
+import (
+	"strings"
+)
+
+// Escaping of values in the Publit query syntax.
+// Attribute filter values and scope filters are delimited by "," and ";". A single value containing a delimiter, or
+// the escape character "\", is escaped by a "\" before the character, so "Crime, Punishment; etc." is sent as
+// "Crime\, Punishment\; etc.".
+//
+// The attribute filter constructors such as AttrEq, QueryBuilder.Where, QueryAttrRange, QueryScope and filter
+// expressions escape their values. AttrQuery.Value is sent as is by QueryAttr, as it may hold several values, so values
+// set directly on it must be escaped with EscapeAttrValue. Use SplitAttrValues to decode a value.
+const QUERY_ESCAPE = `\`
+
+var attrValueEscaper = strings.NewReplacer(QUERY_ESCAPE, QUERY_ESCAPE+QUERY_ESCAPE, ",", QUERY_ESCAPE+",", ";", QUERY_ESCAPE+";")
+
+// EscapeAttrValue escapes the delimiters of a single value, see QUERY_ESCAPE.
+func EscapeAttrValue(v string) string {
+	return attrValueEscaper.Replace(v)
+}
+
+// UnescapeAttrValue reverses EscapeAttrValue.
+func UnescapeAttrValue(v string) string {
+	b := strings.Builder{}
+	escaped := false
+	for _, r := range v {
+		if !escaped && string(r) == QUERY_ESCAPE {
+			escaped = true
+			continue
+		}
+		escaped = false
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// SplitAttrValues splits an attribute filter value on the unescaped commas and unescapes the values.
+func SplitAttrValues(v string) []string {
+	values := []string{}
+	b := strings.Builder{}
+	escaped := false
+
+	for _, r := range v {
+		switch {
+		case escaped:
+			escaped = false
+			b.WriteRune(r)
+		case string(r) == QUERY_ESCAPE:
+			escaped = true
+		case r == ',':
+			values = append(values, b.String())
+			b.Reset()
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	return append(values, b.String())
+}
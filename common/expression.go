@@ -0,0 +1,373 @@
+// Copyright 2018 Publit Sweden AB. All rights reserved.
+
+package common
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+// ErrUnsupportedExpression is returned when a filter expression can not be expressed in the Publit query syntax.
+var ErrUnsupportedExpression = errors.New("Filter expression is not supported by the Publit query syntax")
+
+// Expr is a boolean filter expression built with Cond, And, Or and Not, see QueryExpr.
+type Expr interface {
+	// normalize returns the expression with negations pushed down to the conditions.
+	normalize(negate bool) (Expr, error)
+}
+
+// condExpr is a filter on a single attribute.
+type condExpr struct {
+	name  string
+	op    Operator
+	value string
+}
+
+// groupExpr combines expressions with AND or OR.
+type groupExpr struct {
+	combinator Combinator
+	exprs      []Expr
+}
+
+// notExpr negates an expression.
+type notExpr struct {
+	expr Expr
+}
+
+// Cond is an expression filtering the attribute by the operator and value.
+func Cond(name string, op Operator, value string) Expr {
+	return condExpr{name: name, op: op, value: value}
+}
+
+// And is an expression matching if all of the expressions match.
+func And(exprs ...Expr) Expr {
+	return groupExpr{combinator: COMBINATOR_AND, exprs: exprs}
+}
+
+// Or is an expression matching if any of the expressions match.
+func Or(exprs ...Expr) Expr {
+	return groupExpr{combinator: COMBINATOR_OR, exprs: exprs}
+}
+
+// Not is an expression matching if the expression does not match.
+func Not(expr Expr) Expr {
+	return notExpr{expr: expr}
+}
+
+// Negations of the operators that have one.
+var negatedOperators = map[Operator]Operator{
+	OPERATOR_EQUAL:         OPERATOR_NOT_EQUAL,
+	OPERATOR_NOT_EQUAL:     OPERATOR_EQUAL,
+	OPERATOR_GREATER_EQUAL: OPERATOR_LESS,
+	OPERATOR_LESS:          OPERATOR_GREATER_EQUAL,
+	OPERATOR_GREATER:       OPERATOR_LESS_EQUAL,
+	OPERATOR_LESS_EQUAL:    OPERATOR_GREATER,
+}
+
+func (e condExpr) normalize(negate bool) (Expr, error) {
+	if !negate {
+		return e, nil
+	}
+
+	op, ok := negatedOperators[e.op]
+	if !ok {
+		return nil, fmt.Errorf("%w. Operator %v of %q can not be negated", ErrUnsupportedExpression, e.op, e.name)
+	}
+
+	return condExpr{name: e.name, op: op, value: e.value}, nil
+}
+
+func (e groupExpr) normalize(negate bool) (Expr, error) {
+	combinator := e.combinator
+	if negate {
+		// De Morgan's laws.
+		combinator = COMBINATOR_AND
+		if e.combinator == COMBINATOR_AND {
+			combinator = COMBINATOR_OR
+		}
+	}
+
+	g := groupExpr{combinator: combinator}
+	for _, v := range e.exprs {
+		n, err := v.normalize(negate)
+		if err != nil {
+			return nil, err
+		}
+
+		// Flatten nested groups with the same combinator.
+		if sub, ok := n.(groupExpr); ok && sub.combinator == combinator {
+			g.exprs = append(g.exprs, sub.exprs...)
+			continue
+		}
+		g.exprs = append(g.exprs, n)
+	}
+
+	return g, nil
+}
+
+func (e notExpr) normalize(negate bool) (Expr, error) {
+	return e.expr.normalize(!negate)
+}
+
+// Max amount of queries an expression is expanded to by CompileExprQueries.
+const MAX_EXPR_QUERIES = 32
+
+// CompileExpr compiles the expression to attribute filters of a single query, see QueryAttr.
+//
+// The Publit query syntax filters each attribute by a list of values, and combines the attributes with AND. So the
+// expression must be an AND of terms, where the terms of each attribute are either conditions or a single OR of
+// conditions on that attribute, e.g. And(Or(Cond("state", ...), Cond("state", ...)), Cond("price", ...)).
+// Negations are pushed down to the conditions. Other expressions, such as an OR across attributes, return
+// ErrUnsupportedExpression, see CompileExprQueries for those.
+//
+// Values of an attribute are combined with the combinator of the later value. The first value has the AND combinator.
+func CompileExpr(e Expr) ([]AttrQuery, error) {
+	n, err := e.normalize(false)
+	if err != nil {
+		return nil, err
+	}
+
+	terms := []Expr{n}
+	if g, ok := n.(groupExpr); ok && g.combinator == COMBINATOR_AND {
+		terms = g.exprs
+	}
+
+	return compileTerms(terms)
+}
+
+// CompileExprQueries compiles the expression to the attribute filters of one or more queries, whose results combined
+// match the expression. Expressions supported by CompileExpr compile to a single query. Other expressions are expanded
+// to an OR of such expressions, each sent as a query of its own, e.g. "(a OR b) AND c" is compiled to the queries
+// "a AND c" and "b AND c". ORs of conditions on a single attribute are kept in one query where possible.
+//
+// A resource may match several of the queries, so the results must be deduplicated, e.g. by id, when combined.
+// Expressions expanding to more than MAX_EXPR_QUERIES queries return ErrUnsupportedExpression.
+func CompileExprQueries(e Expr) ([][]AttrQuery, error) {
+	n, err := e.normalize(false)
+	if err != nil {
+		return nil, err
+	}
+
+	if attrs, err := CompileExpr(n); err == nil {
+		return [][]AttrQuery{attrs}, nil
+	}
+
+	conjunctions, err := disjunctiveTerms(n)
+	if err != nil {
+		return nil, err
+	}
+
+	queries := [][]AttrQuery{}
+	for _, terms := range conjunctions {
+		attrs, err := compileTerms(terms)
+		if err != nil {
+			// Split the ORs on single attributes that could not be combined with the other terms.
+			expanded, err := splitTerms(terms)
+			if err != nil {
+				return nil, err
+			}
+			for _, v := range expanded {
+				attrs, err := compileTerms(v)
+				if err != nil {
+					return nil, err
+				}
+				queries = append(queries, attrs)
+			}
+			continue
+		}
+		queries = append(queries, attrs)
+	}
+
+	if len(queries) > MAX_EXPR_QUERIES {
+		return nil, fmt.Errorf("%w. Expression expands to %d queries, more than %d", ErrUnsupportedExpression, len(queries), MAX_EXPR_QUERIES)
+	}
+
+	return queries, nil
+}
+
+// disjunctiveTerms expands the normalized expression to an OR of ANDs of terms, where the terms are conditions or ORs
+// of conditions on a single attribute.
+func disjunctiveTerms(e Expr) ([][]Expr, error) {
+	switch t := e.(type) {
+	case condExpr:
+		return [][]Expr{{t}}, nil
+	case groupExpr:
+		if len(t.exprs) == 0 {
+			return nil, fmt.Errorf("%w. Empty group", ErrUnsupportedExpression)
+		}
+
+		if t.combinator == COMBINATOR_OR {
+			if _, _, err := termConds(t); err == nil {
+				return [][]Expr{{t}}, nil
+			}
+
+			alternatives := [][]Expr{}
+			for _, v := range t.exprs {
+				sub, err := disjunctiveTerms(v)
+				if err != nil {
+					return nil, err
+				}
+				alternatives = append(alternatives, sub...)
+				if len(alternatives) > MAX_EXPR_QUERIES {
+					return nil, fmt.Errorf("%w. Expression expands to more than %d queries", ErrUnsupportedExpression, MAX_EXPR_QUERIES)
+				}
+			}
+			return alternatives, nil
+		}
+
+		// The AND of ORs is the OR of the ANDs of every combination of their alternatives.
+		product := [][]Expr{{}}
+		for _, v := range t.exprs {
+			sub, err := disjunctiveTerms(v)
+			if err != nil {
+				return nil, err
+			}
+
+			next := [][]Expr{}
+			for _, p := range product {
+				for _, alt := range sub {
+					terms := append(append([]Expr{}, p...), alt...)
+					next = append(next, terms)
+				}
+			}
+			if len(next) > MAX_EXPR_QUERIES {
+				return nil, fmt.Errorf("%w. Expression expands to more than %d queries", ErrUnsupportedExpression, MAX_EXPR_QUERIES)
+			}
+			product = next
+		}
+		return product, nil
+	}
+
+	return nil, ErrUnsupportedExpression
+}
+
+// splitTerms expands the ORs among the terms to one AND of conditions per combination of their conditions.
+func splitTerms(terms []Expr) ([][]Expr, error) {
+	expanded := [][]Expr{{}}
+	for _, term := range terms {
+		conds, combinator, err := termConds(term)
+		if err != nil {
+			return nil, err
+		}
+
+		alternatives := [][]Expr{}
+		if combinator == COMBINATOR_OR {
+			for _, c := range conds {
+				alternatives = append(alternatives, []Expr{c})
+			}
+		} else {
+			alternatives = append(alternatives, []Expr{term})
+		}
+
+		next := [][]Expr{}
+		for _, e := range expanded {
+			for _, alt := range alternatives {
+				next = append(next, append(append([]Expr{}, e...), alt...))
+			}
+		}
+		if len(next) > MAX_EXPR_QUERIES {
+			return nil, fmt.Errorf("%w. Expression expands to more than %d queries", ErrUnsupportedExpression, MAX_EXPR_QUERIES)
+		}
+		expanded = next
+	}
+	return expanded, nil
+}
+
+// compileTerms compiles the terms of a top level AND to attribute filters, see CompileExpr.
+func compileTerms(terms []Expr) ([]AttrQuery, error) {
+	attrs := []AttrQuery{}
+	index := map[string]int{}
+	ors := map[string]bool{}
+
+	for _, term := range terms {
+		conds, combinator, err := termConds(term)
+		if err != nil {
+			return nil, err
+		}
+
+		name := conds[0].name
+		i, exists := index[name]
+		if exists && (ors[name] || combinator == COMBINATOR_OR) {
+			return nil, fmt.Errorf("%w. OR of %q can not be combined with other filters on it", ErrUnsupportedExpression, name)
+		}
+		if !exists {
+			i = len(attrs)
+			index[name] = i
+			attrs = append(attrs, AttrQuery{Name: name})
+		}
+		ors[name] = combinator == COMBINATOR_OR
+
+		for _, c := range conds {
+			a := &attrs[i]
+			comb := combinator
+			if len(a.Args.Operator) == 0 {
+				comb = COMBINATOR_AND
+			}
+
+			if a.Value != "" || len(a.Args.Operator) > 0 {
+				a.Value += ","
+			}
+			a.Value += EscapeAttrValue(c.value)
+			a.Args.Operator = append(a.Args.Operator, c.op)
+			a.Args.Combinator = append(a.Args.Combinator, comb)
+		}
+	}
+
+	return attrs, nil
+}
+
+// termConds returns the conditions of a term of the top level AND, which must all be on the same attribute.
+func termConds(term Expr) ([]condExpr, Combinator, error) {
+	switch t := term.(type) {
+	case condExpr:
+		return []condExpr{t}, COMBINATOR_AND, nil
+	case groupExpr:
+		if len(t.exprs) == 0 {
+			return nil, 0, fmt.Errorf("%w. Empty group", ErrUnsupportedExpression)
+		}
+
+		conds := []condExpr{}
+		for _, v := range t.exprs {
+			c, ok := v.(condExpr)
+			if !ok {
+				return nil, 0, fmt.Errorf("%w. Nested groups are only supported directly below a top level AND", ErrUnsupportedExpression)
+			}
+			if c.name != t.exprs[0].(condExpr).name {
+				return nil, 0, fmt.Errorf("%w. OR of different attributes %q and %q", ErrUnsupportedExpression, t.exprs[0].(condExpr).name, c.name)
+			}
+			conds = append(conds, c)
+		}
+
+		return conds, t.combinator, nil
+	}
+
+	return nil, 0, ErrUnsupportedExpression
+}
+
+// Helper to set a filter expression to API query, see CompileExpr.
+// Functions with signature func(q url.Values) are implemented in the more specific SDKs of the PublitGoSDK packages.
+func QueryExpr(e Expr) (func(q url.Values), error) {
+	attrs, err := CompileExpr(e)
+	if err != nil {
+		return nil, err
+	}
+
+	return QueryAttr(attrs...), nil
+}
+
+// Helper to set the filter expression to the queries of the expression, see CompileExprQueries.
+// Each returned function sets the filters of one query, and the results of the queries must be combined by the caller.
+// Functions with signature func(q url.Values) are implemented in the more specific SDKs of the PublitGoSDK packages.
+func QueryExprs(e Expr) ([]func(q url.Values), error) {
+	queries, err := CompileExprQueries(e)
+	if err != nil {
+		return nil, err
+	}
+
+	funcs := make([]func(q url.Values), len(queries))
+	for i, attrs := range queries {
+		funcs[i] = QueryAttr(attrs...)
+	}
+	return funcs, nil
+}
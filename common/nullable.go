@@ -0,0 +1,70 @@
+// Copyright 2018 Publit Sweden AB. All rights reserved.
+
+package common
+
+import (
+	"encoding/json"
+)
+
+// Nullable is a value of Publit JSON distinguishing an absent field, a null field and a zero value.
+// Decoding sets Set if the field is present, and Valid if it is not null.
+// Encoding writes null unless Valid. encoding/json can not omit struct fields, so payload fields that may be absent are
+// declared as pointers with omitempty, eg. `json:"title,omitempty"` on a *NullString, where nil leaves the field out
+// and Null clears it.
+type Nullable[T any] struct {
+	Value T
+	// Valid reports whether the value is not null.
+	Valid bool
+	// Set reports whether the field was present when decoded.
+	Set bool
+}
+
+// Nullable types of the Publit JSON values.
+type (
+	NullString     = Nullable[string]
+	NullInt        = Nullable[int]
+	NullBool       = Nullable[PublitBool]
+	NullPublitTime = Nullable[PublitTime]
+)
+
+// NewNullable returns a set, non-null Nullable of v.
+func NewNullable[T any](v T) Nullable[T] {
+	return Nullable[T]{Value: v, Valid: true, Set: true}
+}
+
+// Null returns a set, null Nullable.
+func Null[T any]() Nullable[T] {
+	return Nullable[T]{Set: true}
+}
+
+// Ptr returns a pointer to a copy of the value, or nil if the value is null.
+func (n Nullable[T]) Ptr() *T {
+	if !n.Valid {
+		return nil
+	}
+	v := n.Value
+	return &v
+}
+
+// MarshalJSON encodes the value, or null unless Valid.
+func (n Nullable[T]) MarshalJSON() ([]byte, error) {
+	if !n.Valid {
+		return []byte("null"), nil
+	}
+	return json.Marshal(n.Value)
+}
+
+// UnmarshalJSON decodes the value, or null.
+func (n *Nullable[T]) UnmarshalJSON(data []byte) error {
+	var v *T
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	*n = Nullable[T]{Set: true}
+	if v != nil {
+		n.Value = *v
+		n.Valid = true
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+// Copyright 2018 Publit Sweden AB. All rights reserved.
+
+package common
+
+import (
+	"net/url"
+	"strings"
+)
+
+// Helper to include a relation in the response with its own query params, such as filters, ordering and limits of the
+// related records. The relation is appended to the with parameter, merging any with params added by QueryWith into a
+// single value. The params are rendered prefixed by "with.", the relation name and QUERY_RELATION_SEPARATOR, eg.
+//
+//	common.WithRelation("authors", common.QueryAttr(common.AttrEq("role", "writer")), common.QueryLimit(5, 0))
+//
+// renders "with=authors&with.authors.role=writer&with.authors.role_args=EQUAL;AND&with.authors.limit=0,5".
+// Relations can be nested by passing WithRelation as a param.
+// Functions with signature func(q url.Values) are implemented in the more specific SDKs of the PublitGoSDK packages.
+func WithRelation(name string, params ...func(q url.Values)) func(q url.Values) {
+	relationParams := queryRelationParams(name, params...)
+
+	return func(q url.Values) {
+		addWith(q, name)
+		relationParams(q)
+	}
+}
+
+// queryRelationParams returns the query param func setting the params of the relation, prefixed by its name.
+func queryRelationParams(name string, params ...func(q url.Values)) func(q url.Values) {
+	prefix := QUERY_KEY_WITH + QUERY_RELATION_SEPARATOR + name + QUERY_RELATION_SEPARATOR
+
+	return func(q url.Values) {
+		relation := url.Values{}
+		for _, f := range params {
+			f(relation)
+		}
+
+		for k, v := range relation {
+			for _, value := range v {
+				q.Add(prefix+k, value)
+			}
+		}
+	}
+}
+
+// addWith appends the comma separated relations to the with parameter, merging it to a single value.
+func addWith(q url.Values, withs string) {
+	values := []string{}
+	for _, v := range q[QUERY_KEY_WITH] {
+		if v != "" {
+			values = append(values, v)
+		}
+	}
+	if withs != "" {
+		values = append(values, withs)
+	}
+
+	q.Set(QUERY_KEY_WITH, strings.Join(values, ","))
+}
@@ -0,0 +1,33 @@
+// Copyright 2018 Publit Sweden AB. All rights reserved.
+
+package common
+
+// ListResponse is the envelope of index responses from the Publit APIs, holding a page of records and pagination
+// information. Resource packages decode index responses into a ListResponse of their model instead of declaring their
+// own envelope.
+type ListResponse[T any] struct {
+	// Data holds the records of the page.
+	Data []T `json:"data"`
+	// Count is the total amount of records matching the query.
+	Count int `json:"count"`
+	// Offset is the offset of the page, as requested with the limit query parameter.
+	Offset int `json:"-"`
+	// Limit is the max amount of records of the page, as requested with the limit query parameter. 0 if no limit was requested.
+	Limit int `json:"-"`
+}
+
+// HasMore reports if there are more records after this page.
+func (l ListResponse[T]) HasMore() bool {
+	return l.Offset+len(l.Data) < l.Count
+}
+
+// NextOffset returns the offset of the page following this one.
+func (l ListResponse[T]) NextOffset() int {
+	return l.Offset + len(l.Data)
+}
+
+// ShowResponse is the envelope of responses from the Publit APIs holding a single record.
+type ShowResponse[T any] struct {
+	// Data holds the record.
+	Data T `json:"data"`
+}
@@ -0,0 +1,135 @@
+// Copyright 2018 Publit Sweden AB. All rights reserved.
+
+package common
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+)
+
+// Errors of invalid scopes, see ScopeBuilder.
+var (
+	ErrUnknownScope       = errors.New("Unknown scope")
+	ErrInvalidScopeFilter = errors.New("Invalid scope filter")
+)
+
+// ScopeFilter describes whether a scope takes a filter.
+type ScopeFilter int
+
+// ScopeFilter enum constants. The zero value is treated as SCOPE_FILTER_OPTIONAL.
+const (
+	SCOPE_FILTER_NONE ScopeFilter = 1 + iota
+	SCOPE_FILTER_OPTIONAL
+	SCOPE_FILTER_REQUIRED
+)
+
+// ScopeDef defines a known scope of a resource, see RegisterScopes.
+type ScopeDef struct {
+	// Name of the scope.
+	Name string
+	// Filter describes whether the scope takes a filter.
+	Filter ScopeFilter
+	// ValidateFilter optionally validates a given filter.
+	ValidateFilter func(filter string) error
+}
+
+var (
+	scopesMu sync.RWMutex
+	scopes   = map[string]map[string]ScopeDef{}
+)
+
+// RegisterScopes registers the known scopes of a resource, such as "products". The resource packages of the
+// PublitGoSDK register the scopes of their resources, and declare constants of the scope names.
+// A scope registered again replaces the earlier definition.
+func RegisterScopes(resource string, defs ...ScopeDef) {
+	scopesMu.Lock()
+	defer scopesMu.Unlock()
+
+	if scopes[resource] == nil {
+		scopes[resource] = map[string]ScopeDef{}
+	}
+	for _, v := range defs {
+		scopes[resource][v.Name] = v
+	}
+}
+
+// LookupScope returns the definition of a scope of a resource.
+func LookupScope(resource, name string) (ScopeDef, bool) {
+	scopesMu.RLock()
+	defer scopesMu.RUnlock()
+
+	def, ok := scopes[resource][name]
+	return def, ok
+}
+
+// ValidateScopes checks the scopes against the registered scopes of the resource.
+// Returns ErrUnknownScope for scopes not registered, and ErrInvalidScopeFilter for filters the scope does not accept.
+func ValidateScopes(resource string, scopes ...Scope) error {
+	for _, v := range scopes {
+		def, ok := LookupScope(resource, v.Scope)
+		if !ok {
+			return fmt.Errorf("%w %q of %q", ErrUnknownScope, v.Scope, resource)
+		}
+
+		switch {
+		case def.Filter == SCOPE_FILTER_NONE && v.Filter != "":
+			return fmt.Errorf("%w. Scope %q of %q takes no filter", ErrInvalidScopeFilter, v.Scope, resource)
+		case def.Filter == SCOPE_FILTER_REQUIRED && v.Filter == "":
+			return fmt.Errorf("%w. Scope %q of %q requires a filter", ErrInvalidScopeFilter, v.Scope, resource)
+		}
+
+		if def.ValidateFilter != nil && v.Filter != "" {
+			if err := def.ValidateFilter(v.Filter); err != nil {
+				return fmt.Errorf("%w. Scope %q of %q: %v", ErrInvalidScopeFilter, v.Scope, resource, err)
+			}
+		}
+	}
+	return nil
+}
+
+// ScopeBuilder assembles the scopes of a request, validated against the registered scopes of the resource, eg.
+//
+//	scopes, err := common.NewScopes("products").
+//		Add("active").
+//		AddFilter("publisher", "42").
+//		Build()
+type ScopeBuilder struct {
+	resource string
+	scopes   []Scope
+}
+
+// NewScopes returns an empty ScopeBuilder of the resource.
+func NewScopes(resource string) *ScopeBuilder {
+	return &ScopeBuilder{resource: resource}
+}
+
+// Add adds the scope without filter.
+func (b *ScopeBuilder) Add(name string) *ScopeBuilder {
+	b.scopes = append(b.scopes, Scope{Scope: name})
+	return b
+}
+
+// AddFilter adds the scope with the filter.
+func (b *ScopeBuilder) AddFilter(name, filter string) *ScopeBuilder {
+	b.scopes = append(b.scopes, Scope{Scope: name, Filter: filter})
+	return b
+}
+
+// Build validates the scopes, see ValidateScopes, and returns them to be passed to QueryScope or QueryBuilder.Scope.
+func (b *ScopeBuilder) Build() ([]Scope, error) {
+	if err := ValidateScopes(b.resource, b.scopes...); err != nil {
+		return nil, err
+	}
+	return append([]Scope(nil), b.scopes...), nil
+}
+
+// Query validates the scopes and returns the query param func setting them, see QueryScope.
+func (b *ScopeBuilder) Query() (func(q url.Values), error) {
+	scopes, err := b.Build()
+	if err != nil {
+		return nil, err
+	}
+	return QueryScope(scopes), nil
+}
@@ -0,0 +1,34 @@
+// Copyright 2018 Publit Sweden AB. All rights reserved.
+
+package common
+
+import (
+	"net/url"
+	"strings"
+)
+
+// SerializeQuery returns the query set by the query param funcs as a canonical string, eg. for cache keys, saved
+// searches and logs. The string is the URL encoded query with the keys sorted. The values of a key keep their order,
+// as it may be significant. Use ParseQuery to read it back.
+func SerializeQuery(params ...func(q url.Values)) string {
+	q := url.Values{}
+	for _, f := range params {
+		f(q)
+	}
+	return q.Encode()
+}
+
+// ParseQuery parses a query serialized by SerializeQuery, with or without a leading "?", and returns the query param
+// func setting it.
+func ParseQuery(s string) (func(q url.Values), error) {
+	values, err := url.ParseQuery(strings.TrimPrefix(s, "?"))
+	if err != nil {
+		return nil, err
+	}
+	return QueryRawValues(values), nil
+}
+
+// String returns the built query params serialized, see SerializeQuery.
+func (b *QueryBuilder) String() string {
+	return SerializeQuery(b.Build()...)
+}
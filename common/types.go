@@ -0,0 +1,71 @@
+// Copyright 2018 Publit Sweden AB. All rights reserved.
+
+package common
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidPublitBool is returned when a PublitBool is neither "true" nor "false", in any case.
+var ErrInvalidPublitBool = errors.New("Invalid PublitBool")
+
+// NewPublitBool returns the PublitBool of b.
+func NewPublitBool(b bool) PublitBool {
+	if b {
+		return "true"
+	}
+	return "false"
+}
+
+// ParseBool converts the PublitBool to bool. Unlike ConvertPublitBoolToBool it returns ErrInvalidPublitBool for values
+// other than "true" and "false", in any case.
+func (str PublitBool) ParseBool() (bool, error) {
+	switch strings.ToLower(string(str)) {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+	return false, fmt.Errorf("%w %q", ErrInvalidPublitBool, string(str))
+}
+
+// MarshalJSON encodes the PublitBool as a JSON string. Returns ErrInvalidPublitBool for values other than "true" and
+// "false", so invalid values are never sent in payloads. An empty PublitBool is encoded as an empty string.
+func (str PublitBool) MarshalJSON() ([]byte, error) {
+	if str != "" {
+		if _, err := str.ParseBool(); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(string(str))
+}
+
+// UnmarshalJSON decodes the PublitBool from a JSON string or boolean.
+// Returns ErrInvalidPublitBool for strings other than "true" and "false". Null leaves the PublitBool unchanged.
+func (str *PublitBool) UnmarshalJSON(data []byte) error {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	switch b := v.(type) {
+	case nil:
+		return nil
+	case bool:
+		*str = NewPublitBool(b)
+		return nil
+	case string:
+		if b != "" {
+			if _, err := PublitBool(b).ParseBool(); err != nil {
+				return err
+			}
+		}
+		*str = PublitBool(b)
+		return nil
+	}
+
+	return fmt.Errorf("%w %s", ErrInvalidPublitBool, data)
+}
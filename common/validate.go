@@ -0,0 +1,144 @@
+// Copyright 2018 Publit Sweden AB. All rights reserved.
+
+package common
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidQuery is matched by the errors of ValidateQuery with errors.Is.
+var ErrInvalidQuery = errors.New("Invalid query")
+
+// Query keys that may be given more than once.
+var repeatableQueryKeys = map[string]bool{
+	QUERY_KEY_HAS: true,
+}
+
+// QueryError describes a problem with a query param.
+type QueryError struct {
+	Key     string
+	Problem string
+}
+
+// Error returns the error message of the QueryError.
+func (e *QueryError) Error() string {
+	return fmt.Sprintf("Invalid query param %q. %v", e.Key, e.Problem)
+}
+
+// Is reports if target is ErrInvalidQuery.
+func (e *QueryError) Is(target error) bool {
+	return target == ErrInvalidQuery
+}
+
+// QueryErrors holds all problems found by ValidateQuery, ordered by key.
+type QueryErrors []*QueryError
+
+// Error returns the error messages of all problems.
+func (e QueryErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, v := range e {
+		msgs[i] = v.Error()
+	}
+	return strings.Join(msgs, " ")
+}
+
+// Is reports if target is ErrInvalidQuery.
+func (e QueryErrors) Is(target error) bool {
+	return target == ErrInvalidQuery
+}
+
+// ValidateQuery checks the query params before the request is sent. It detects duplicate keys, malformed limits,
+// order_dir without order_by, and unknown operators and combinators or missing values of attribute filters.
+// Returns QueryErrors if any problem is found.
+func ValidateQuery(q url.Values) error {
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	errs := QueryErrors{}
+	add := func(key, format string, a ...interface{}) {
+		errs = append(errs, &QueryError{Key: key, Problem: fmt.Sprintf(format, a...)})
+	}
+
+	for _, k := range keys {
+		values := q[k]
+		if len(values) > 1 && !repeatableQueryKeys[k] {
+			add(k, "Given %d times.", len(values))
+		}
+
+		for _, v := range values {
+			switch {
+			case k == QUERY_KEY_LIMIT:
+				if problem := validateLimit(v); problem != "" {
+					add(k, problem)
+				}
+			case k == QUERY_KEY_ORDER_DIR:
+				if _, ok := q[QUERY_KEY_ORDER]; !ok {
+					add(k, "Given without %v.", QUERY_KEY_ORDER)
+				}
+				if indexOf(orderDirections, v) < 0 {
+					add(k, "Unknown direction %q.", v)
+				}
+			case k == QUERY_KEY_AUX_ARGS:
+				if _, ok := q[QUERY_KEY_AUX]; !ok {
+					add(k, "Given without %v.", QUERY_KEY_AUX)
+				}
+			case strings.HasSuffix(k, QUERY_ARGS_SUFFIX):
+				if _, ok := q[strings.TrimSuffix(k, QUERY_ARGS_SUFFIX)]; !ok {
+					add(k, "Given without a value of the attribute.")
+				}
+				for _, arg := range strings.Split(v, ",") {
+					parts := strings.SplitN(arg, ";", 2)
+					if indexOf(operators, parts[0]) < 0 {
+						add(k, "Unknown operator %q.", parts[0])
+					}
+					if len(parts) > 1 && indexOf(combinators, parts[1]) < 0 {
+						add(k, "Unknown combinator %q.", parts[1])
+					}
+				}
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// validateLimit returns the problem of a limit param, which is "offset,limit" as set by QueryLimit.
+func validateLimit(v string) string {
+	parts := strings.Split(v, ",")
+	if len(parts) != 2 {
+		return fmt.Sprintf("Expected \"offset,limit\", got %q.", v)
+	}
+
+	offset, err := strconv.Atoi(parts[0])
+	if err != nil || offset < 0 {
+		return fmt.Sprintf("Offset must be a non-negative integer, got %q.", parts[0])
+	}
+
+	limit, err := strconv.Atoi(parts[1])
+	if err != nil || limit < 1 {
+		return fmt.Sprintf("Limit must be a positive integer, got %q.", parts[1])
+	}
+
+	return ""
+}
+
+// indexOf returns the index of s in strs, or -1.
+func indexOf(strs []string, s string) int {
+	for i, v := range strs {
+		if v == s {
+			return i
+		}
+	}
+	return -1
+}